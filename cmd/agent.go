@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/calummacc/g0/internal/distributed"
+	"github.com/spf13/cobra"
+)
+
+var agentListen string
+
+var agentCmd = &cobra.Command{
+	Use:   "agent",
+	Short: "Run load tests on behalf of a controller",
+	Long: `Wait for load tests sent by "g0 run --agents" and stream their results back to the controller.
+
+Each agent generates its share of the concurrency and rate; the controller combines the results.
+URLs are resolved and TLS certificate files (--cacert, --cert, --key) are read on the agent. If ` + distributed.TokenEnv + ` is set,
+the controller must use the same token. Without a token, the agent only listens on a loopback address.
+
+Example:
+  G0_AGENT_TOKEN=secret g0 agent --listen :7000
+  g0 run --url http://api.internal/health -c 200 -d 1m --agents host1:7000,host2:7000`,
+	RunE: runAgent,
+}
+
+func init() {
+	rootCmd.AddCommand(agentCmd)
+
+	agentCmd.Flags().StringVar(&agentListen, "listen", distributed.DefaultListen, "Address to listen on for the controller (other than loopback only with "+distributed.TokenEnv+" set)")
+}
+
+func runAgent(cmd *cobra.Command, args []string) error {
+	logger := log.New(os.Stderr, "", log.LstdFlags)
+	agent := distributed.NewAgent(os.Getenv(distributed.TokenEnv), logger.Printf)
+	addr, err := agent.Listen(agentListen)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	defer agent.Close()
+
+	logger.Printf("g0 agent listening on %s", addr)
+	if agent.Token == "" {
+		logger.Printf("Warning: %s is not set, any local user can start load tests", distributed.TokenEnv)
+	}
+
+	// Serve until interrupted
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	<-signals
+	logger.Printf("shutting down")
+	return nil
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/calummacc/g0/internal/capacity"
+	"github.com/calummacc/g0/internal/printer"
+	"github.com/calummacc/g0/internal/runner"
+	"github.com/calummacc/g0/internal/threshold"
+	"github.com/spf13/cobra"
+)
+
+var (
+	capacityURLs        []string
+	capacityConcurrency int
+	capacityMethod      string
+	capacityBody        string
+	capacityHeaders     []string
+	capacitySLO         string
+	capacityStepTime    string
+	capacitySearch      string
+	capacityStartRPS    float64
+	capacityMaxRPS      float64
+	capacityStepRPS     float64
+	capacityPrecision   float64
+	capacityCooldown    string
+)
+
+var findCapacityCmd = &cobra.Command{
+	Use:   "find-capacity",
+	Short: "Find the highest request rate that meets an SLO",
+	Long: `Run load steps of increasing rate until the SLO breaks and report the highest rate that still met it.
+
+Example:
+  g0 find-capacity --url https://api.example.com --slo 'p99<300ms,errors<1%'
+  g0 find-capacity --url https://api.example.com --slo 'p95<100ms' --search step --start-rps 100 --step-rps 100`,
+	RunE: runFindCapacity,
+}
+
+func init() {
+	rootCmd.AddCommand(findCapacityCmd)
+
+	findCapacityCmd.Flags().StringArrayVarP(&capacityURLs, "url", "u", []string{}, "Target URL(s) to test (can be specified multiple times for round-robin)")
+	findCapacityCmd.Flags().IntVarP(&capacityConcurrency, "concurrency", "c", 50, "Number of concurrent workers per step")
+	findCapacityCmd.Flags().StringVarP(&capacityMethod, "method", "m", "GET", "HTTP method")
+	findCapacityCmd.Flags().StringVarP(&capacityBody, "body", "b", "", "Request body")
+	findCapacityCmd.Flags().StringArrayVarP(&capacityHeaders, "headers", "H", []string{}, "HTTP headers (can be specified multiple times)")
+	findCapacityCmd.Flags().StringVar(&capacitySLO, "slo", "", "Service level objective every step must meet, e.g. 'p99<300ms,errors<1%'")
+	findCapacityCmd.Flags().StringVar(&capacityStepTime, "step-duration", "10s", "Duration of each load step")
+	findCapacityCmd.Flags().StringVar(&capacitySearch, "search", capacity.SearchBinary, "Search strategy: binary (double, then bisect) or step (fixed increments)")
+	findCapacityCmd.Flags().Float64Var(&capacityStartRPS, "start-rps", 10, "Request rate of the first step")
+	findCapacityCmd.Flags().Float64Var(&capacityMaxRPS, "max-rps", 10000, "Highest request rate to try")
+	findCapacityCmd.Flags().Float64Var(&capacityStepRPS, "step-rps", 0, "Rate increase per step for --search step (default: --start-rps)")
+	findCapacityCmd.Flags().Float64Var(&capacityPrecision, "precision", 0.05, "For --search binary: stop once the search range is within this fraction of the rate")
+	findCapacityCmd.Flags().StringVar(&capacityCooldown, "cooldown", "2s", "Pause between steps so the target can recover")
+
+	findCapacityCmd.MarkFlagRequired("url")
+	findCapacityCmd.MarkFlagRequired("slo")
+}
+
+func runFindCapacity(cmd *cobra.Command, args []string) error {
+	stepDuration, err := time.ParseDuration(capacityStepTime)
+	if err != nil {
+		return fmt.Errorf("invalid step-duration format: %w", err)
+	}
+	cooldown, err := time.ParseDuration(capacityCooldown)
+	if err != nil {
+		return fmt.Errorf("invalid cooldown format: %w", err)
+	}
+
+	if len(capacityURLs) == 0 {
+		return fmt.Errorf("at least one URL is required (use --url or -u)")
+	}
+	if capacityConcurrency <= 0 {
+		return fmt.Errorf("concurrency must be greater than 0")
+	}
+	headerMap, err := parseHeaders(capacityHeaders)
+	if err != nil {
+		return err
+	}
+
+	slo, err := threshold.ParseList(capacitySLO)
+	if err != nil {
+		return err
+	}
+
+	switch capacitySearch {
+	case capacity.SearchBinary:
+		if capacityPrecision <= 0 || capacityPrecision >= 1 {
+			return fmt.Errorf("precision must be between 0 and 1")
+		}
+	case capacity.SearchStep:
+		if capacityStepRPS < 0 {
+			return fmt.Errorf("step-rps must not be negative")
+		}
+		if capacityStepRPS == 0 {
+			capacityStepRPS = capacityStartRPS
+		}
+	default:
+		return fmt.Errorf("unknown search strategy %q (supported: binary, step)", capacitySearch)
+	}
+	if capacityStartRPS <= 0 {
+		return fmt.Errorf("start-rps must be greater than 0")
+	}
+	if capacityMaxRPS < capacityStartRPS {
+		return fmt.Errorf("max-rps must be at least start-rps")
+	}
+
+	printer.PrintLogo()
+	printer.PrintCapacityStart(capacityURLs, capacityConcurrency, stepDuration, slo, capacitySearch)
+
+	config := capacity.Config{
+		Base: runner.Config{
+			URLs:        capacityURLs,
+			Concurrency: capacityConcurrency,
+			Duration:    stepDuration,
+			Method:      capacityMethod,
+			Body:        capacityBody,
+			Headers:     headerMap,
+			Burst:       1,
+		},
+		SLO:       slo,
+		Search:    capacitySearch,
+		StartRPS:  capacityStartRPS,
+		MaxRPS:    capacityMaxRPS,
+		StepRPS:   capacityStepRPS,
+		Precision: capacityPrecision,
+		Cooldown:  cooldown,
+	}
+
+	result, err := capacity.Search(config, func(number int, rps float64) {
+		printer.PrintCapacityStepRunning(number, rps, stepDuration)
+	}, printer.PrintCapacityStep)
+	if err != nil {
+		return fmt.Errorf("capacity search failed: %w", err)
+	}
+
+	printer.PrintCapacityResult(result, capacityMaxRPS)
+	return nil
+}
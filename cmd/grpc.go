@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/calummacc/g0/internal/grpcload"
+	"github.com/calummacc/g0/internal/printer"
+	"github.com/calummacc/g0/internal/runner"
+	"github.com/calummacc/g0/internal/threshold"
+	"github.com/spf13/cobra"
+)
+
+var (
+	grpcTarget      string
+	grpcMethod      string
+	grpcData        string
+	grpcMetadata    []string
+	grpcProtoSet    string
+	grpcProtoFiles  []string
+	grpcImportPaths []string
+	grpcConcurrency int
+	grpcDuration    string
+	grpcMaxRPS      float64
+	grpcConnections int
+	grpcCallTimeout string
+	grpcPlaintext   bool
+	grpcInsecure    bool
+
+	grpcJSONOutput  bool
+	grpcOutputFile  string
+	grpcThresholds  []string
+	grpcOutSpecs    []string
+	grpcOutInterval string
+	grpcRunID       string
+)
+
+var grpcCmd = &cobra.Command{
+	Use:   "grpc",
+	Short: "Run a gRPC load test",
+	Long: `Call a unary or server-streaming gRPC method in a loop and report latency and gRPC status codes.
+
+The method descriptor is fetched with server reflection, or read from --protoset or --proto files.
+
+Example:
+  g0 grpc --target localhost:50051 --plaintext --method helloworld.Greeter/SayHello --data '{"name":"g0"}'
+  g0 grpc --target api.example.com:443 --method pkg.Feed/Subscribe --proto feed.proto -I ./protos -c 20 -d 30s`,
+	RunE: runGRPCTest,
+}
+
+func init() {
+	rootCmd.AddCommand(grpcCmd)
+
+	grpcCmd.Flags().StringVarP(&grpcTarget, "target", "t", "", "Server address (host:port)")
+	grpcCmd.Flags().StringVar(&grpcMethod, "method", "", "Method to call, e.g. package.Service/Method")
+	grpcCmd.Flags().StringVar(&grpcData, "data", "{}", "Request message as JSON")
+	grpcCmd.Flags().StringArrayVarP(&grpcMetadata, "headers", "H", []string{}, "Metadata sent with every call, e.g. 'authorization: Bearer ...' (can be specified multiple times)")
+	grpcCmd.Flags().StringVar(&grpcProtoSet, "protoset", "", "FileDescriptorSet file to resolve the method from instead of server reflection")
+	grpcCmd.Flags().StringArrayVar(&grpcProtoFiles, "proto", []string{}, "Proto file to resolve the method from instead of server reflection (can be specified multiple times)")
+	grpcCmd.Flags().StringArrayVarP(&grpcImportPaths, "import-path", "I", []string{}, "Import path for --proto files (can be specified multiple times)")
+	grpcCmd.Flags().IntVarP(&grpcConcurrency, "concurrency", "c", 10, "Number of concurrent workers")
+	grpcCmd.Flags().StringVarP(&grpcDuration, "duration", "d", "10s", "Test duration (e.g., 10s, 1m)")
+	grpcCmd.Flags().Float64Var(&grpcMaxRPS, "max-rps", 0, "Maximum calls per second (0 = unlimited)")
+	grpcCmd.Flags().IntVar(&grpcConnections, "connections", 1, "HTTP/2 connections shared by the workers")
+	grpcCmd.Flags().StringVar(&grpcCallTimeout, "timeout", "30s", "Deadline of each call, including the whole response stream")
+	grpcCmd.Flags().BoolVar(&grpcPlaintext, "plaintext", false, "Connect without TLS")
+	grpcCmd.Flags().BoolVarP(&grpcInsecure, "insecure", "k", false, "Skip TLS certificate verification")
+	grpcCmd.Flags().BoolVarP(&grpcJSONOutput, "json", "j", false, "Output results in JSON format")
+	grpcCmd.Flags().StringVarP(&grpcOutputFile, "output", "o", "", "Output file path for JSON results (default: results/g0-result-YYYYMMDD-HHMMSS.json)")
+	grpcCmd.Flags().StringArrayVar(&grpcThresholds, "threshold", []string{}, "Pass/fail threshold, e.g. 'p99<300ms,errors<1%'; calls with a status other than OK are errors (can be specified multiple times)")
+	grpcCmd.Flags().StringArrayVar(&grpcOutSpecs, "out", []string{}, "Stream metrics to an output: influx=<write URL>, statsd=<host:port> or otlp=<collector URL> (can be specified multiple times)")
+	grpcCmd.Flags().StringVar(&grpcOutInterval, "out-interval", "5s", "Flush interval for --out outputs")
+	grpcCmd.Flags().StringVar(&grpcRunID, "run-id", "", "Run ID used to tag streamed metrics (default: random)")
+
+	grpcCmd.MarkFlagRequired("target")
+	grpcCmd.MarkFlagRequired("method")
+}
+
+func runGRPCTest(cmd *cobra.Command, args []string) error {
+	duration, err := time.ParseDuration(grpcDuration)
+	if err != nil {
+		return fmt.Errorf("invalid duration format: %w", err)
+	}
+	callTimeout, err := time.ParseDuration(grpcCallTimeout)
+	if err != nil || callTimeout <= 0 {
+		return fmt.Errorf("invalid timeout %q (expected a positive duration, e.g. 30s)", grpcCallTimeout)
+	}
+	if grpcConcurrency <= 0 {
+		return fmt.Errorf("concurrency must be greater than 0")
+	}
+	if grpcConnections <= 0 {
+		return fmt.Errorf("connections must be greater than 0")
+	}
+	if grpcMaxRPS < 0 {
+		return fmt.Errorf("max-rps must not be negative")
+	}
+	if grpcProtoSet != "" && len(grpcProtoFiles) > 0 {
+		return fmt.Errorf("--protoset and --proto cannot be used together")
+	}
+	if grpcPlaintext && grpcInsecure {
+		return fmt.Errorf("--insecure has no effect with --plaintext")
+	}
+
+	metadata, err := parseHeaders(grpcMetadata)
+	if err != nil {
+		return err
+	}
+	thresholds, err := parseThresholds(grpcThresholds)
+	if err != nil {
+		return err
+	}
+
+	config := grpcload.Config{
+		Target:   grpcTarget,
+		Method:   grpcMethod,
+		Data:     grpcData,
+		Metadata: metadata,
+		Descriptors: grpcload.DescriptorSource{
+			ProtoSet:    grpcProtoSet,
+			ProtoFiles:  grpcProtoFiles,
+			ImportPaths: grpcImportPaths,
+		},
+		Concurrency: grpcConcurrency,
+		Duration:    duration,
+		MaxRPS:      grpcMaxRPS,
+		Connections: grpcConnections,
+		CallTimeout: callTimeout,
+		Plaintext:   grpcPlaintext,
+		Insecure:    grpcInsecure,
+	}
+
+	printer.PrintLogo()
+	printer.PrintGRPCStart(config)
+
+	// Set up streaming outputs if requested
+	outputs, err := startOutputs(grpcOutSpecs, grpcOutInterval, grpcRunID)
+	if err != nil {
+		return err
+	}
+	if outputs != nil {
+		defer outputs.Stop()
+	}
+
+	// Show progress while the test runs
+	statsChan := make(chan *runner.Stats, 1)
+	done := make(chan struct{})
+	progressDone := make(chan struct{})
+	go func() {
+		defer close(progressDone)
+		var stats *runner.Stats
+		start := time.Now()
+		ticker := time.NewTicker(200 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case stats = <-statsChan:
+				start = time.Now()
+				if outputs != nil {
+					outputs.SetStats(stats)
+				}
+			case <-ticker.C:
+				if stats != nil {
+					progress := stats.GetProgressStats()
+					printer.PrintProgress(time.Since(start), duration, &progress, 0)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	result, err := grpcload.Run(config, statsChan)
+	close(done)
+	<-progressDone
+	printer.ClearProgress()
+	if err != nil {
+		return fmt.Errorf("gRPC test failed: %w", err)
+	}
+
+	// Flush the last interval to the outputs before reporting
+	if outputs != nil {
+		for _, err := range outputs.Stop() {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	fmt.Println()
+	printer.PrintResults(result.Summary)
+	if result.Stream != nil {
+		printer.PrintGRPCStream(result.Stream)
+	}
+
+	thresholdResults := threshold.EvaluateAll(thresholds, threshold.SummaryValues(result.Summary))
+	printer.PrintThresholds(thresholdResults)
+
+	if grpcJSONOutput {
+		filePath, err := printer.PrintGRPCResultsJSON(result, config, thresholdResults, grpcOutputFile)
+		if err != nil {
+			return fmt.Errorf("failed to save JSON output: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "\nResults saved to: %s\n", filePath)
+	}
+
+	// Fail the command (non-zero exit code) if any threshold was crossed
+	if !threshold.AllPassed(thresholdResults) {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("one or more thresholds failed")
+	}
+	return nil
+}
@@ -1,13 +1,23 @@
 package cmd
 
 import (
+	"crypto/tls"
 	"fmt"
 	"os"
 	"strings"
 	"time"
 
+	"github.com/calummacc/g0/internal/check"
+	"github.com/calummacc/g0/internal/control"
+	"github.com/calummacc/g0/internal/dashboard"
+	"github.com/calummacc/g0/internal/distributed"
+	"github.com/calummacc/g0/internal/graphql"
+	"github.com/calummacc/g0/internal/httpclient"
+	"github.com/calummacc/g0/internal/metrics"
+	"github.com/calummacc/g0/internal/output"
 	"github.com/calummacc/g0/internal/printer"
 	"github.com/calummacc/g0/internal/runner"
+	"github.com/calummacc/g0/internal/threshold"
 	"github.com/spf13/cobra"
 )
 
@@ -15,12 +25,57 @@ var (
 	urls        []string
 	concurrency int
 	duration    string
+	warmup      string
 	method      string
 	body        string
 	headers     []string
+	stream      bool
 	jsonOutput  bool
 	outputFile  string
-	maxRPS      int
+	maxRPS      float64
+	burst       int
+	arrival     string
+	jitter      float64
+	adaptive    bool
+	agents      []string
+	ui          bool
+	controlAddr string
+
+	graphqlQueries []string
+	graphqlVars    string
+
+	prometheusListen string
+	outSpecs         []string
+	outInterval      string
+	runID            string
+	traceSample      float64
+	traceSlow        string
+	thresholdExprs   []string
+	checkExprs       []string
+	junitFile        string
+	summaryMDFile    string
+	githubAnnotate   bool
+	topErrors        int
+
+	requestTimeout      string
+	connectTimeout      string
+	tlsHandshakeTimeout string
+	maxIdleConnsPerHost int
+	maxConnsPerHost     int
+	disableKeepAlive    bool
+	redirects           string
+	httpVersion         string
+	h2Conns             int
+	h2MaxStreams        int
+
+	caCert        string
+	clientCert    string
+	clientKey     string
+	insecure      bool
+	serverName    string
+	tlsMinVersion string
+	tlsMaxVersion string
+	cipherSuites  string
 )
 
 var runCmd = &cobra.Command{
@@ -40,12 +95,55 @@ func init() {
 	runCmd.Flags().StringArrayVarP(&urls, "url", "u", []string{}, "Target URL(s) - can be specified multiple times (required)")
 	runCmd.Flags().IntVarP(&concurrency, "concurrency", "c", 10, "Number of concurrent workers")
 	runCmd.Flags().StringVarP(&duration, "duration", "d", "10s", "Test duration (e.g., 10s, 1m, 30s)")
+	runCmd.Flags().StringVar(&warmup, "warmup", "0s", "Warm-up period before --duration whose results are excluded from the statistics (e.g., 10s)")
 	runCmd.Flags().StringVarP(&method, "method", "m", "GET", "HTTP method")
 	runCmd.Flags().StringVarP(&body, "body", "b", "", "Request body")
 	runCmd.Flags().StringArrayVarP(&headers, "headers", "H", []string{}, "HTTP headers (can be specified multiple times)")
+	runCmd.Flags().BoolVar(&stream, "stream", false, "Read response bodies incrementally and report time to first event, inter-event gaps and stream duration (SSE events, or body chunks); --timeout covers the whole stream")
+	runCmd.Flags().StringArrayVar(&graphqlQueries, "graphql-query", []string{}, "GraphQL document whose operations are POSTed in turn, reported per operation; responses with errors fail (can be specified multiple times)")
+	runCmd.Flags().StringVar(&graphqlVars, "variables", "", "JSON file with variables sent with every --graphql-query operation")
 	runCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output results in JSON format")
 	runCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file path for JSON results (default: results/g0-result-YYYYMMDD-HHMMSS.json)")
-	runCmd.Flags().IntVarP(&maxRPS, "max-rps", "r", 0, "Maximum requests per second (0 = no limit)")
+	runCmd.Flags().Float64VarP(&maxRPS, "max-rps", "r", 0, "Maximum requests per second, may be fractional e.g. 0.5 (0 = no limit)")
+	runCmd.Flags().IntVar(&burst, "burst", 1, "Number of requests that may be sent back-to-back under --max-rps")
+	runCmd.Flags().StringVar(&arrival, "arrival", "constant", "Distribution of gaps between requests under --max-rps: constant, uniform or poisson")
+	runCmd.Flags().Float64Var(&jitter, "jitter", 1, "For --arrival uniform: gaps vary by up to ±jitter × the mean gap (0-1)")
+	runCmd.Flags().BoolVar(&adaptive, "adaptive", false, "Back off on 429/503 or rising errors (honoring Retry-After) and probe up again, using --max-rps as the ceiling")
+	runCmd.Flags().StringSliceVar(&agents, "agents", []string{}, "Generate the load on g0 agents (host:port, comma-separated), splitting concurrency and rate across them")
+	runCmd.Flags().BoolVar(&ui, "ui", false, "Show a full-screen live dashboard; press p to pause or resume, q to stop early and s to save a report")
+	runCmd.Flags().StringVar(&controlAddr, "control", "", "Serve an HTTP API on this address to read live stats, change the workers or --max-rps, pause, resume or stop the run (e.g., 127.0.0.1:6565)")
+	runCmd.Flags().StringArrayVar(&outSpecs, "out", []string{}, "Stream metrics to an output: influx=<write URL>, statsd=<host:port> or otlp=<collector URL> (can be specified multiple times)")
+	runCmd.Flags().StringVar(&outInterval, "out-interval", "5s", "Flush interval for --out outputs")
+	runCmd.Flags().StringVar(&runID, "run-id", "", "Run ID used to tag streamed metrics (default: random)")
+	runCmd.Flags().Float64Var(&traceSample, "trace-sample", 0, "Fraction of requests (0-1) that carry a W3C traceparent header (0 = disabled)")
+	runCmd.Flags().StringVar(&traceSlow, "trace-slow", "0s", "Report trace IDs of sampled requests at least this slow (the slowest are always kept)")
+	runCmd.Flags().StringArrayVar(&thresholdExprs, "threshold", []string{}, "Pass/fail threshold, e.g. 'p99<300ms,errors<1%' (can be specified multiple times)")
+	runCmd.Flags().StringArrayVar(&checkExprs, "check", []string{}, "Assertion counted for every response without failing it, e.g. 'status==200', 'header Content-Type contains json' or 'body contains ok' (can be specified multiple times)")
+	runCmd.Flags().StringVar(&junitFile, "junit", "", "Write a JUnit XML report (one testcase per threshold, check and endpoint) to this file")
+	runCmd.Flags().StringVar(&summaryMDFile, "summary-md", "", "Write a Markdown summary (e.g. for $GITHUB_STEP_SUMMARY) to this file")
+	runCmd.Flags().BoolVar(&githubAnnotate, "github-annotations", false, "Print GitHub Actions annotations for failed thresholds and checks")
+	runCmd.Flags().IntVar(&topErrors, "top-errors", 5, "Number of most frequent error messages reported per error class")
+	runCmd.Flags().StringVar(&prometheusListen, "prometheus-listen", "", "Serve live Prometheus metrics on this address during the run (e.g., :9091)")
+
+	runCmd.Flags().StringVar(&requestTimeout, "timeout", "30s", "Request timeout, including redirects")
+	runCmd.Flags().StringVar(&connectTimeout, "connect-timeout", "10s", "TCP connect timeout")
+	runCmd.Flags().StringVar(&tlsHandshakeTimeout, "tls-handshake-timeout", "10s", "TLS handshake timeout")
+	runCmd.Flags().IntVar(&maxIdleConnsPerHost, "max-idle-conns-per-host", 0, "Idle connections kept for reuse per host (default: concurrency)")
+	runCmd.Flags().IntVar(&maxConnsPerHost, "max-conns-per-host", 0, "Maximum connections per host, including active ones (0 = unlimited)")
+	runCmd.Flags().BoolVar(&disableKeepAlive, "disable-keep-alive", false, "Open a new connection for every request")
+	runCmd.Flags().StringVar(&redirects, "redirects", "follow", "Redirect policy: follow (up to 10 hops), none, or a maximum number of hops")
+	runCmd.Flags().StringVar(&httpVersion, "http", "auto", "HTTP version: auto (HTTP/2 if the TLS server offers it), 1.1, 2, h2c (HTTP/2 without TLS) or 3 (HTTP/3 over QUIC)")
+	runCmd.Flags().IntVar(&h2Conns, "h2-conns", 1, "HTTP/2 connections per host with --http 2 or h2c")
+	runCmd.Flags().IntVar(&h2MaxStreams, "h2-max-streams", 0, "Concurrent streams per HTTP/2 connection with --http 2 or h2c (0 = server limit)")
+
+	runCmd.Flags().StringVar(&caCert, "cacert", "", "PEM file with CA certificates to trust in addition to the system roots")
+	runCmd.Flags().StringVar(&clientCert, "cert", "", "PEM client certificate for mutual TLS (requires --key)")
+	runCmd.Flags().StringVar(&clientKey, "key", "", "PEM private key for --cert")
+	runCmd.Flags().BoolVarP(&insecure, "insecure", "k", false, "Skip TLS certificate verification")
+	runCmd.Flags().StringVar(&serverName, "server-name", "", "TLS server name for SNI and certificate verification (default: the URL host)")
+	runCmd.Flags().StringVar(&tlsMinVersion, "tls-min-version", "", "Minimum TLS version: 1.0, 1.1, 1.2 or 1.3")
+	runCmd.Flags().StringVar(&tlsMaxVersion, "tls-max-version", "", "Maximum TLS version: 1.0, 1.1, 1.2 or 1.3")
+	runCmd.Flags().StringVar(&cipherSuites, "ciphers", "", "Comma-separated TLS 1.0-1.2 cipher suites, e.g. TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256")
 
 	runCmd.MarkFlagRequired("url")
 }
@@ -56,6 +154,13 @@ func runLoadTest(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("invalid duration format: %w", err)
 	}
+	warmupDuration, err := time.ParseDuration(warmup)
+	if err != nil {
+		return fmt.Errorf("invalid warmup format: %w", err)
+	}
+	if warmupDuration < 0 {
+		return fmt.Errorf("warmup must not be negative")
+	}
 
 	// Validate URLs
 	if len(urls) == 0 {
@@ -68,37 +173,235 @@ func runLoadTest(cmd *cobra.Command, args []string) error {
 	}
 
 	// Parse headers
-	headerMap := make(map[string]string)
-	for _, h := range headers {
-		parts := strings.SplitN(h, ":", 2)
-		if len(parts) != 2 {
-			return fmt.Errorf("invalid header format: %s (expected 'Key: Value')", h)
+	headerMap, err := parseHeaders(headers)
+	if err != nil {
+		return err
+	}
+
+	// Build GraphQL requests
+	operations, err := loadGraphQL(cmd, headerMap)
+	if err != nil {
+		return err
+	}
+	if len(operations) > 0 {
+		method = "POST"
+	}
+
+	// Validate tracing options
+	if traceSample < 0 || traceSample > 1 {
+		return fmt.Errorf("trace-sample must be between 0 and 1")
+	}
+	traceSlowThreshold, err := time.ParseDuration(traceSlow)
+	if err != nil {
+		return fmt.Errorf("invalid trace-slow format: %w", err)
+	}
+
+	if topErrors <= 0 {
+		return fmt.Errorf("top-errors must be greater than 0")
+	}
+
+	// Parse transport options
+	transport, err := parseTransportOptions()
+	if err != nil {
+		return err
+	}
+	for _, u := range urls {
+		switch {
+		case transport.Protocol == httpclient.ProtocolHTTP2 && !strings.HasPrefix(u, "https://"):
+			return fmt.Errorf("--http 2 requires https:// URLs (use --http h2c for cleartext HTTP/2): %s", u)
+		case transport.Protocol == httpclient.ProtocolH2C && !strings.HasPrefix(u, "http://"):
+			return fmt.Errorf("--http h2c requires http:// URLs (use --http 2 for HTTPS): %s", u)
+		case transport.Protocol == httpclient.ProtocolHTTP3 && !strings.HasPrefix(u, "https://"):
+			return fmt.Errorf("--http 3 requires https:// URLs: %s", u)
 		}
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
-		headerMap[key] = value
+	}
+
+	// Parse thresholds
+	thresholds, err := parseThresholds(thresholdExprs)
+	if err != nil {
+		return err
+	}
+
+	// Parse checks
+	var checks []check.Check
+	for _, expr := range checkExprs {
+		parsed, err := check.Parse(expr)
+		if err != nil {
+			return err
+		}
+		checks = append(checks, parsed)
+	}
+	if stream && check.NeedsBody(checks) {
+		return fmt.Errorf("body checks cannot be used with --stream, which does not keep the body")
 	}
 
 	// Print logo
 	printer.PrintLogo()
 
 	// Print test configuration
-	printer.PrintTestStart(urls, concurrency, testDuration)
+	printer.PrintTestStart(urls, concurrency, testDuration, warmupDuration)
+	if len(operations) > 0 {
+		printer.PrintGraphQLOperations(operations)
+	}
 
 	// Validate max RPS if specified
 	if maxRPS < 0 {
 		return fmt.Errorf("max-rps must be greater than or equal to 0")
 	}
+	if burst < 1 {
+		return fmt.Errorf("burst must be greater than 0")
+	}
+	arrivalDist, err := runner.ParseArrival(arrival, jitter)
+	if err != nil {
+		return err
+	}
+	if arrivalDist.Kind != runner.ArrivalConstant && maxRPS == 0 {
+		return fmt.Errorf("--arrival %s requires --max-rps", arrivalDist.Kind)
+	}
+	if adaptive && maxRPS == 0 {
+		return fmt.Errorf("--adaptive requires --max-rps")
+	}
+	if cmd.Flags().Changed("jitter") && arrivalDist.Kind != runner.ArrivalUniform {
+		return fmt.Errorf("--jitter only applies to --arrival uniform")
+	}
+	if adaptive && len(agents) > 0 {
+		return fmt.Errorf("--adaptive cannot be combined with --agents")
+	}
+	if ui && len(agents) > 0 {
+		return fmt.Errorf("--ui cannot be combined with --agents")
+	}
+	if controlAddr != "" && len(agents) > 0 {
+		return fmt.Errorf("--control cannot be combined with --agents")
+	}
+	if controlAddr != "" && adaptive {
+		return fmt.Errorf("--control cannot be combined with --adaptive, which sets the rate itself")
+	}
 
 	// Create and run the load test
 	config := runner.Config{
 		URLs:        urls,
 		Concurrency: concurrency,
 		Duration:    testDuration,
+		Warmup:      warmupDuration,
 		Method:      method,
 		Body:        body,
 		Headers:     headerMap,
 		MaxRPS:      maxRPS,
+		Burst:       burst,
+		Arrival:     arrivalDist,
+		Adaptive:    adaptive,
+
+		TraceSampleRate:    traceSample,
+		TraceSlowThreshold: traceSlowThreshold,
+
+		TopErrors: topErrors,
+
+		Stream:  stream,
+		GraphQL: operations,
+		Checks:  checks,
+
+		Transport: transport,
+	}
+	// Report the options the run actually uses
+	transport = config.HTTPTransport()
+
+	// Split the load across agents if requested
+	var assignments []distributed.Assignment
+	if len(agents) > 0 {
+		assignments, err = distributed.Split(config, agents)
+		if err != nil {
+			return err
+		}
+		printer.PrintAgents(assignments)
+	}
+
+	// Start Prometheus metrics endpoint if requested
+	var promServer *metrics.PrometheusServer
+	if prometheusListen != "" {
+		promServer = metrics.NewPrometheusServer(prometheusListen)
+		if err := promServer.Start(); err != nil {
+			return fmt.Errorf("failed to start Prometheus endpoint: %w", err)
+		}
+		defer promServer.Stop()
+		fmt.Printf("Prometheus metrics: http://%s/metrics\n\n", promServer.Addr())
+	}
+
+	// Set up streaming outputs if requested
+	outputs, err := startOutputs(outSpecs, outInterval, runID)
+	if err != nil {
+		return err
+	}
+	if outputs != nil {
+		defer outputs.Stop()
+	}
+
+	// The dashboard and the control API steer the run through the same control
+	if ui || controlAddr != "" {
+		config.Control = runner.NewControl()
+	}
+
+	// Start the control API if requested
+	var controlServer *control.Server
+	if controlAddr != "" {
+		controlServer = control.NewServer(controlAddr, control.Options{
+			Control: config.Control,
+			Token:   os.Getenv(control.TokenEnv),
+			Report: func(summary *runner.Summary) interface{} {
+				results := threshold.EvaluateAll(thresholds, threshold.SummaryValues(summary))
+				return printer.BuildResultsJSON(summary, urls, concurrency, testDuration, method, headerMap, transport, results)
+			},
+			Logf: func(format string, args ...interface{}) {
+				// The dashboard owns the terminal; otherwise make room next to the progress line
+				if ui {
+					return
+				}
+				printer.ClearProgress()
+				fmt.Fprintf(os.Stderr, "control: "+format+"\n", args...)
+			},
+		})
+		if err := controlServer.Start(); err != nil {
+			return fmt.Errorf("failed to start control API: %w", err)
+		}
+		defer controlServer.Stop()
+		fmt.Printf("Control API: http://%s\n\n", controlServer.Addr())
+	}
+
+	// Take over the terminal with the dashboard if requested
+	var dash *dashboard.Dashboard
+	if ui {
+		dash, err = dashboard.Start(dashboard.Options{
+			URLs:        urls,
+			Method:      method,
+			Concurrency: concurrency,
+			MaxRPS:      maxRPS,
+			Warmup:      warmupDuration,
+			Duration:    testDuration,
+			Thresholds:  thresholds,
+			Control:     config.Control,
+			Save: func(summary *runner.Summary, results []threshold.Result) (string, error) {
+				return printer.PrintResultsJSON(summary, urls, concurrency, testDuration, method, headerMap, transport, results, "")
+			},
+		})
+		if err != nil {
+			return err
+		}
+		defer dash.Stop()
+	}
+
+	// onStats hands the live stats instance to everything that reads it during the run
+	onStats := func(s *runner.Stats) {
+		if dash != nil {
+			dash.SetStats(s)
+		}
+		if controlServer != nil {
+			controlServer.SetStats(s)
+		}
+		if promServer != nil {
+			promServer.SetStats(s)
+		}
+		if outputs != nil {
+			outputs.SetStats(s)
+		}
 	}
 
 	// Channel to receive test result
@@ -110,11 +413,18 @@ func runLoadTest(cmd *cobra.Command, args []string) error {
 	progressDone := make(chan struct{})
 	testCompleted := make(chan struct{}) // Signal when test is actually done
 	startTime := time.Now()
+	totalDuration := warmupDuration + testDuration
 	var stats *runner.Stats
 
 	// Start the test in a goroutine
 	go func() {
-		result, err := runner.RunWithStatsAndChannel(config, statsChan)
+		var result *runner.RunResult
+		var err error
+		if len(assignments) > 0 {
+			result, err = distributed.Run(config, assignments, os.Getenv(distributed.TokenEnv), statsChan)
+		} else {
+			result, err = runner.RunWithStatsAndChannel(config, statsChan)
+		}
 		if err != nil {
 			errChan <- err
 			return
@@ -128,6 +438,11 @@ func runLoadTest(cmd *cobra.Command, args []string) error {
 		select {
 		case s := <-statsChan:
 			stats = s
+			onStats(s)
+			if len(assignments) > 0 {
+				// Agents start together after a delay; count progress from then
+				startTime = time.Now()
+			}
 		case <-time.After(2 * time.Second):
 			// Stats not available yet, continue anyway (shouldn't happen normally)
 		}
@@ -140,6 +455,10 @@ func runLoadTest(cmd *cobra.Command, args []string) error {
 			case s := <-statsChan:
 				// Stats instance is now available (if not received earlier)
 				stats = s
+				onStats(s)
+				if len(assignments) > 0 {
+					startTime = time.Now()
+				}
 			case <-ticker.C:
 				// Check if test completed first - if so, stop immediately
 				select {
@@ -148,21 +467,25 @@ func runLoadTest(cmd *cobra.Command, args []string) error {
 				case <-progressDone:
 					return
 				default:
+					// The dashboard shows its own progress
+					if dash != nil {
+						continue
+					}
 					// Test still running, continue updating
 					elapsed := time.Since(startTime)
-					// Only update if elapsed < testDuration (don't show 100% from progress goroutine)
+					// Only update if elapsed < totalDuration (don't show 100% from progress goroutine)
 					// Main goroutine will handle 100% and "Generating report" display
-					if elapsed < testDuration {
+					if elapsed < totalDuration {
 						if stats != nil {
 							progressStats := stats.GetProgressStats()
-							printer.PrintProgress(elapsed, testDuration, &progressStats, 0)
+							printer.PrintProgress(elapsed, totalDuration, &progressStats, 0)
 						} else {
 							// Stats not available yet, show basic progress with zero stats
 							zeroStats := runner.ProgressStats{}
-							printer.PrintProgress(elapsed, testDuration, &zeroStats, 0)
+							printer.PrintProgress(elapsed, totalDuration, &zeroStats, 0)
 						}
 					}
-					// If elapsed >= testDuration, don't update anymore - let main goroutine handle it
+					// If elapsed >= totalDuration, don't update anymore - let main goroutine handle it
 				}
 			case <-progressDone:
 				// Stop immediately when test is done
@@ -178,11 +501,20 @@ func runLoadTest(cmd *cobra.Command, args []string) error {
 	var result *runner.RunResult
 	select {
 	case err := <-errChan:
+		if dash != nil {
+			dash.Stop()
+		}
+		if controlServer != nil {
+			controlServer.Finish(nil)
+		}
 		close(progressDone)
 		time.Sleep(50 * time.Millisecond)
 		printer.ClearProgress()
 		return fmt.Errorf("load test failed: %w", err)
 	case result = <-resultChan:
+		if dash != nil {
+			dash.Stop()
+		}
 		// Test completed - signal to stop progress updates immediately
 		// Close testCompleted first to signal completion
 		close(testCompleted)
@@ -192,7 +524,7 @@ func runLoadTest(cmd *cobra.Command, args []string) error {
 		time.Sleep(250 * time.Millisecond)
 		
 		// Show final "Generating report..." message once
-		if stats != nil {
+		if stats != nil && dash == nil {
 			progressStats := stats.GetProgressStats()
 			var rps float64
 			if testDuration > 0 {
@@ -207,17 +539,223 @@ func runLoadTest(cmd *cobra.Command, args []string) error {
 		fmt.Println() // Add a newline after clearing progress
 	}
 
+	// Answer stop requests of the control API with the final results
+	if controlServer != nil {
+		controlServer.Finish(result.Summary)
+	}
+
+	// Flush the last interval to the outputs before reporting
+	if outputs != nil {
+		for _, err := range outputs.Stop() {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
 	// Print results in text format
 	printer.PrintResults(result.Summary)
-	
+
+	// Evaluate thresholds against the final summary
+	thresholdResults := threshold.EvaluateAll(thresholds, threshold.SummaryValues(result.Summary))
+	printer.PrintThresholds(thresholdResults)
+
 	// If JSON output is enabled, also save to file
 	if jsonOutput {
-		filePath, err := printer.PrintResultsJSON(result.Summary, urls, concurrency, testDuration, method, headerMap, outputFile)
+		filePath, err := printer.PrintResultsJSON(result.Summary, urls, concurrency, testDuration, method, headerMap, transport, thresholdResults, outputFile)
 		if err != nil {
 			return fmt.Errorf("failed to save JSON output: %w", err)
 		}
 		fmt.Fprintf(os.Stderr, "\nResults saved to: %s\n", filePath)
 	}
 
+	// CI reports
+	if junitFile != "" {
+		if _, err := printer.WriteJUnit(junitFile, result.Summary, thresholds); err != nil {
+			return fmt.Errorf("failed to save JUnit report: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "JUnit report saved to: %s\n", junitFile)
+	}
+	if summaryMDFile != "" {
+		if err := printer.WriteMarkdownSummary(summaryMDFile, result.Summary, thresholds); err != nil {
+			return fmt.Errorf("failed to save Markdown summary: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Markdown summary saved to: %s\n", summaryMDFile)
+	}
+	if githubAnnotate {
+		printer.PrintGitHubAnnotations(result.Summary, thresholds)
+	}
+
+	// Fail the command (non-zero exit code) if any threshold was crossed
+	if !threshold.AllPassed(thresholdResults) {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("one or more thresholds failed")
+	}
+
 	return nil
 }
+
+// parseThresholds parses --threshold expressions, each a comma-separated list
+func parseThresholds(exprs []string) ([]threshold.Threshold, error) {
+	var thresholds []threshold.Threshold
+	for _, expr := range exprs {
+		parsed, err := threshold.ParseList(expr)
+		if err != nil {
+			return nil, err
+		}
+		thresholds = append(thresholds, parsed...)
+	}
+	return thresholds, nil
+}
+
+// startOutputs starts streaming metrics to the --out outputs, or returns nil if there are none
+// The caller hands the manager the live stats and stops it after the run
+func startOutputs(specs []string, interval, id string) (*output.Manager, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+	flushInterval, err := time.ParseDuration(interval)
+	if err != nil || flushInterval <= 0 {
+		return nil, fmt.Errorf("invalid out-interval: %s", interval)
+	}
+	sinks := make([]output.Sink, 0, len(specs))
+	for _, spec := range specs {
+		sink, err := output.ParseSink(spec)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	if id == "" {
+		id = output.NewRunID()
+	}
+	outputs := output.NewManager(sinks, flushInterval, id)
+	outputs.Start()
+	fmt.Printf("Streaming metrics to %d output(s), run ID: %s\n\n", len(sinks), id)
+	return outputs, nil
+}
+
+// parseTransportOptions builds the HTTP transport options from the command-line flags
+func parseTransportOptions() (httpclient.TransportOptions, error) {
+	var opts httpclient.TransportOptions
+	var err error
+
+	if opts.Timeout, err = time.ParseDuration(requestTimeout); err != nil || opts.Timeout <= 0 {
+		return opts, fmt.Errorf("invalid timeout %q (expected a positive duration, e.g. 30s)", requestTimeout)
+	}
+	if opts.ConnectTimeout, err = time.ParseDuration(connectTimeout); err != nil || opts.ConnectTimeout <= 0 {
+		return opts, fmt.Errorf("invalid connect-timeout %q (expected a positive duration, e.g. 10s)", connectTimeout)
+	}
+	if opts.TLSHandshakeTimeout, err = time.ParseDuration(tlsHandshakeTimeout); err != nil || opts.TLSHandshakeTimeout <= 0 {
+		return opts, fmt.Errorf("invalid tls-handshake-timeout %q (expected a positive duration, e.g. 10s)", tlsHandshakeTimeout)
+	}
+	if maxIdleConnsPerHost < 0 {
+		return opts, fmt.Errorf("max-idle-conns-per-host must not be negative")
+	}
+	if maxConnsPerHost < 0 {
+		return opts, fmt.Errorf("max-conns-per-host must not be negative")
+	}
+	opts.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	opts.MaxConnsPerHost = maxConnsPerHost
+	opts.DisableKeepAlives = disableKeepAlive
+
+	opts.NoRedirects, opts.MaxRedirects, err = httpclient.ParseRedirectPolicy(redirects)
+	if err != nil {
+		return opts, err
+	}
+
+	if opts.Protocol, err = httpclient.ParseProtocol(httpVersion); err != nil {
+		return opts, err
+	}
+	if h2Conns < 1 {
+		return opts, fmt.Errorf("h2-conns must be at least 1")
+	}
+	if h2MaxStreams < 0 {
+		return opts, fmt.Errorf("h2-max-streams must not be negative")
+	}
+	if (h2Conns != 1 || h2MaxStreams != 0) && opts.Protocol != httpclient.ProtocolHTTP2 && opts.Protocol != httpclient.ProtocolH2C {
+		return opts, fmt.Errorf("--h2-conns and --h2-max-streams require --http 2 or --http h2c")
+	}
+	if (opts.Protocol == httpclient.ProtocolHTTP2 || opts.Protocol == httpclient.ProtocolH2C) && (opts.DisableKeepAlives || opts.MaxConnsPerHost > 0) {
+		return opts, fmt.Errorf("--disable-keep-alive and --max-conns-per-host do not apply to --http 2 or h2c (use --h2-conns to set the connections per host)")
+	}
+	opts.H2Conns = h2Conns
+	opts.H2MaxStreams = h2MaxStreams
+
+	if (clientCert == "") != (clientKey == "") {
+		return opts, fmt.Errorf("--cert and --key must be used together")
+	}
+	opts.TLS = httpclient.TLSOptions{
+		CACert:     caCert,
+		Cert:       clientCert,
+		Key:        clientKey,
+		Insecure:   insecure,
+		ServerName: serverName,
+	}
+	if opts.TLS.MinVersion, err = httpclient.ParseTLSVersion(tlsMinVersion); err != nil {
+		return opts, err
+	}
+	if opts.TLS.MaxVersion, err = httpclient.ParseTLSVersion(tlsMaxVersion); err != nil {
+		return opts, err
+	}
+	if opts.TLS.CipherSuites, err = httpclient.ParseCipherSuites(cipherSuites); err != nil {
+		return opts, err
+	}
+	if opts.Protocol == httpclient.ProtocolHTTP3 && opts.TLS.MaxVersion != 0 && opts.TLS.MaxVersion < tls.VersionTLS13 {
+		return opts, fmt.Errorf("--http 3 requires TLS 1.3 (QUIC does not support earlier versions)")
+	}
+	return opts, nil
+}
+
+// loadGraphQL builds the requests for --graphql-query, setting a JSON Content-Type unless one was given
+// Returns nil without --graphql-query
+func loadGraphQL(cmd *cobra.Command, headerMap map[string]string) ([]graphql.Operation, error) {
+	if len(graphqlQueries) == 0 {
+		if graphqlVars != "" {
+			return nil, fmt.Errorf("--variables requires --graphql-query")
+		}
+		return nil, nil
+	}
+	if body != "" {
+		return nil, fmt.Errorf("--graphql-query builds the request body and cannot be used with --body")
+	}
+	if cmd.Flags().Changed("method") && !strings.EqualFold(method, "POST") {
+		return nil, fmt.Errorf("--graphql-query sends POST requests (got --method %s)", method)
+	}
+	if stream {
+		return nil, fmt.Errorf("--graphql-query cannot be used with --stream")
+	}
+
+	var variables map[string]interface{}
+	if graphqlVars != "" {
+		var err error
+		if variables, err = graphql.LoadVariables(graphqlVars); err != nil {
+			return nil, err
+		}
+	}
+	operations, err := graphql.LoadOperations(graphqlQueries, variables)
+	if err != nil {
+		return nil, err
+	}
+
+	for key := range headerMap {
+		if strings.EqualFold(key, "Content-Type") {
+			return operations, nil
+		}
+	}
+	headerMap["Content-Type"] = "application/json"
+	return operations, nil
+}
+
+// parseHeaders converts "Key: Value" flags into a header map
+func parseHeaders(headers []string) (map[string]string, error) {
+	headerMap := make(map[string]string)
+	for _, h := range headers {
+		parts := strings.SplitN(h, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid header format: %s (expected 'Key: Value')", h)
+		}
+		key := strings.TrimSpace(parts[0])
+		value := strings.TrimSpace(parts[1])
+		headerMap[key] = value
+	}
+	return headerMap, nil
+}
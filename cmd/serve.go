@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/calummacc/g0/internal/target"
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveListen        string
+	serveSize          int
+	serveStatus        int
+	serveLatency       string
+	serveLatencyDist   string
+	serveLatencyJitter float64
+	serveErrorRate     float64
+	serveErrorStatus   int
+	serveChunks        int
+	serveChunkDelay    string
+	serveQuiet         bool
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run a target server with configurable responses",
+	Long: `Run a minimal HTTP server to calibrate g0 and reproduce scenarios without a real service.
+
+Every path answers the same way. The query string of a request overrides the flags for that
+request: size, status, latency, error-rate, error-status, chunks and chunk-delay.
+
+Example:
+  g0 serve --listen :8080
+  g0 serve --listen :8080 --size 4096 --latency 20ms --latency-dist normal --error-rate 0.01
+  g0 run --url 'http://localhost:8080/slow?chunks=10&chunk-delay=100ms' --stream -c 10 -d 10s`,
+	RunE: runServe,
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&serveListen, "listen", ":8080", "Address to listen on")
+	serveCmd.Flags().IntVar(&serveSize, "size", 128, "Response body size in bytes")
+	serveCmd.Flags().IntVar(&serveStatus, "status", 200, "Status code of successful responses")
+	serveCmd.Flags().StringVar(&serveLatency, "latency", "0s", "Mean delay before responding (e.g., 20ms)")
+	serveCmd.Flags().StringVar(&serveLatencyDist, "latency-dist", "constant", "Latency distribution: constant, uniform, normal or exponential")
+	serveCmd.Flags().Float64Var(&serveLatencyJitter, "latency-jitter", 0.5, "For --latency-dist uniform: spread of ±jitter × latency (0-1); for normal: standard deviation as a fraction of latency")
+	serveCmd.Flags().Float64Var(&serveErrorRate, "error-rate", 0, "Fraction of responses (0-1) answered with --error-status")
+	serveCmd.Flags().IntVar(&serveErrorStatus, "error-status", 500, "Status code of failed responses")
+	serveCmd.Flags().IntVar(&serveChunks, "chunks", 0, "Send the body in this many flushed chunks with chunked encoding (0 = one write with Content-Length)")
+	serveCmd.Flags().StringVar(&serveChunkDelay, "chunk-delay", "0s", "Pause between chunks, for slow responses")
+	serveCmd.Flags().BoolVarP(&serveQuiet, "quiet", "q", false, "Do not log the request rate every second")
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	latencyMean, err := time.ParseDuration(serveLatency)
+	if err != nil {
+		return fmt.Errorf("invalid latency format: %w", err)
+	}
+	latency, err := target.ParseLatency(latencyMean, serveLatencyDist, serveLatencyJitter)
+	if err != nil {
+		return err
+	}
+	if cmd.Flags().Changed("latency-jitter") && latency.Kind != target.LatencyUniform && latency.Kind != target.LatencyNormal {
+		return fmt.Errorf("--latency-jitter only applies to --latency-dist uniform or normal")
+	}
+	chunkDelay, err := time.ParseDuration(serveChunkDelay)
+	if err != nil {
+		return fmt.Errorf("invalid chunk-delay format: %w", err)
+	}
+
+	server, err := target.New(target.Options{
+		Size:        serveSize,
+		Status:      serveStatus,
+		Latency:     latency,
+		ErrorRate:   serveErrorRate,
+		ErrorStatus: serveErrorStatus,
+		Chunks:      serveChunks,
+		ChunkDelay:  chunkDelay,
+	})
+	if err != nil {
+		return err
+	}
+	addr, err := server.Listen(serveListen)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	defer server.Close()
+
+	logger := log.New(os.Stderr, "", log.LstdFlags)
+	logger.Printf("g0 target server listening on http://%s", addr)
+	logger.Printf("responses: %d bytes, status %d, latency %s", serveSize, serveStatus, latency)
+	if serveErrorRate > 0 {
+		logger.Printf("errors: %.2f%% answered with status %d", serveErrorRate*100, serveErrorStatus)
+	}
+	if serveChunks > 0 {
+		logger.Printf("chunked: %d chunks, %s apart", serveChunks, chunkDelay)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	// Log the rate of answered requests each second there was traffic
+	var lastRequests, lastErrors int64
+	for {
+		select {
+		case <-ticker.C:
+			requests, errors := server.Counts()
+			if !serveQuiet && requests > lastRequests {
+				logger.Printf("%d req/s, %d errors", requests-lastRequests, errors-lastErrors)
+			}
+			lastRequests, lastErrors = requests, errors
+		case <-signals:
+			requests, errors := server.Counts()
+			logger.Printf("shutting down after %d requests (%d errors)", requests, errors)
+			return nil
+		}
+	}
+}
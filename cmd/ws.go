@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/calummacc/g0/internal/printer"
+	"github.com/calummacc/g0/internal/ws"
+	"github.com/spf13/cobra"
+)
+
+var (
+	wsURL              string
+	wsConnections      int
+	wsDuration         string
+	wsHeaders          []string
+	wsMessages         []string
+	wsMessagesFile     string
+	wsRate             float64
+	wsCorrelationField string
+	wsReplyTimeout     string
+	wsConnectTimeout   string
+	wsInsecure         bool
+)
+
+var wsCmd = &cobra.Command{
+	Use:   "ws",
+	Short: "Run a WebSocket load test",
+	Long: `Open WebSocket connections, optionally send templated messages, and measure connect time,
+message round-trip latency, message rates and disconnect reasons.
+
+Message templates may contain {{id}}, {{vu}}, {{seq}} and {{timestamp}}. Replies are matched to
+sent messages by the --correlation-field of JSON messages, or by their whole content otherwise.
+
+Example:
+  g0 ws --url wss://chat.example.com/ws -c 500 -d 1m
+  g0 ws --url ws://localhost:8080/echo -c 50 -d 30s --message '{"type":"ping","id":"{{id}}"}' --rate 5`,
+	RunE: runWSTest,
+}
+
+func init() {
+	rootCmd.AddCommand(wsCmd)
+
+	wsCmd.Flags().StringVarP(&wsURL, "url", "u", "", "WebSocket URL (ws:// or wss://)")
+	wsCmd.Flags().IntVarP(&wsConnections, "concurrency", "c", 10, "Number of virtual users, each holding one connection")
+	wsCmd.Flags().StringVarP(&wsDuration, "duration", "d", "10s", "Test duration (e.g., 10s, 1m)")
+	wsCmd.Flags().StringArrayVarP(&wsHeaders, "headers", "H", []string{}, "Handshake headers (can be specified multiple times)")
+	wsCmd.Flags().StringArrayVar(&wsMessages, "message", []string{}, "Message template to send (can be specified multiple times; sent in turn)")
+	wsCmd.Flags().StringVar(&wsMessagesFile, "messages-file", "", "File with one message template per line, sent in turn")
+	wsCmd.Flags().Float64Var(&wsRate, "rate", 0, "Messages per second per connection (0 = send the next message once the previous one is answered)")
+	wsCmd.Flags().StringVar(&wsCorrelationField, "correlation-field", "id", "JSON field matching replies to sent messages (dotted paths allowed, e.g. meta.id)")
+	wsCmd.Flags().StringVar(&wsReplyTimeout, "reply-timeout", "5s", "Messages without a reply after this long count as lost")
+	wsCmd.Flags().StringVar(&wsConnectTimeout, "connect-timeout", "10s", "WebSocket handshake timeout, including TCP connect and TLS")
+	wsCmd.Flags().BoolVarP(&wsInsecure, "insecure", "k", false, "Skip TLS certificate verification")
+
+	wsCmd.MarkFlagRequired("url")
+}
+
+func runWSTest(cmd *cobra.Command, args []string) error {
+	duration, err := time.ParseDuration(wsDuration)
+	if err != nil {
+		return fmt.Errorf("invalid duration format: %w", err)
+	}
+	replyTimeout, err := time.ParseDuration(wsReplyTimeout)
+	if err != nil || replyTimeout <= 0 {
+		return fmt.Errorf("invalid reply-timeout %q (expected a positive duration, e.g. 5s)", wsReplyTimeout)
+	}
+	connectTimeout, err := time.ParseDuration(wsConnectTimeout)
+	if err != nil || connectTimeout <= 0 {
+		return fmt.Errorf("invalid connect-timeout %q (expected a positive duration, e.g. 10s)", wsConnectTimeout)
+	}
+	if wsConnections <= 0 {
+		return fmt.Errorf("concurrency must be greater than 0")
+	}
+	if wsRate < 0 {
+		return fmt.Errorf("rate must not be negative")
+	}
+
+	headerMap, err := parseHeaders(wsHeaders)
+	if err != nil {
+		return err
+	}
+
+	messages := wsMessages
+	if wsMessagesFile != "" {
+		fileMessages, err := readMessagesFile(wsMessagesFile)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, fileMessages...)
+	}
+	if len(messages) == 0 && wsRate > 0 {
+		return fmt.Errorf("--rate requires --message or --messages-file")
+	}
+
+	config := ws.Config{
+		URL:              wsURL,
+		Connections:      wsConnections,
+		Duration:         duration,
+		Headers:          headerMap,
+		Messages:         messages,
+		Rate:             wsRate,
+		CorrelationField: wsCorrelationField,
+		ReplyTimeout:     replyTimeout,
+		ConnectTimeout:   connectTimeout,
+		Insecure:         wsInsecure,
+	}
+
+	printer.PrintLogo()
+	printer.PrintWSStart(config)
+	printer.PrintWSRunning(duration)
+
+	summary, err := ws.Run(config)
+	if err != nil {
+		return fmt.Errorf("WebSocket test failed: %w", err)
+	}
+
+	printer.PrintWSResults(summary)
+	return nil
+}
+
+// readMessagesFile reads one message template per non-empty line
+func readMessagesFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open messages file: %w", err)
+	}
+	defer file.Close()
+
+	var messages []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			messages = append(messages, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read messages file: %w", err)
+	}
+	return messages, nil
+}
@@ -0,0 +1,171 @@
+package capacity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/calummacc/g0/internal/runner"
+	"github.com/calummacc/g0/internal/threshold"
+)
+
+// Search strategies
+const (
+	SearchBinary = "binary" // Double the rate until the SLO breaks, then bisect
+	SearchStep   = "step"   // Increase the rate by a fixed step until the SLO breaks
+)
+
+// minAchievedRatio is the fraction of the target rate a step must reach to count as sustained
+const minAchievedRatio = 0.9
+
+// Config configures a capacity search
+type Config struct {
+	Base      runner.Config // Run configuration for every step; MaxRPS is set per step
+	SLO       []threshold.Threshold
+	Search    string
+	StartRPS  float64
+	MaxRPS    float64       // Upper bound of the search
+	StepRPS   float64       // Rate increase per step (step search only)
+	Precision float64       // Binary search stops once the gap is below this fraction of the rate
+	Cooldown  time.Duration // Pause between steps so the target can recover
+
+	// Run runs the load test of one step (nil = runner.Run)
+	Run func(runner.Config) (*runner.Summary, error)
+}
+
+// Step is the outcome of running one load level
+type Step struct {
+	Number    int
+	TargetRPS float64
+	Summary   *runner.Summary
+	SLO       []threshold.Result
+	Passed    bool
+	Reason    string // Why the step failed (empty if it passed)
+}
+
+// Result is the outcome of a capacity search
+type Result struct {
+	Steps     []Step
+	Best      *Step // Highest rate that met the SLO (nil if none did)
+	HitMaxRPS bool  // The SLO still held at MaxRPS, so capacity may be higher
+}
+
+// Search runs steps of increasing load until the SLO breaks and returns the highest rate that met it
+// onStart and onStep are called before and after each step (either may be nil)
+func Search(cfg Config, onStart func(number int, rps float64), onStep func(Step)) (*Result, error) {
+	if cfg.StartRPS <= 0 {
+		return nil, fmt.Errorf("start rate must be greater than 0")
+	}
+	if cfg.MaxRPS < cfg.StartRPS {
+		return nil, fmt.Errorf("maximum rate must be at least the start rate")
+	}
+	if cfg.Search == SearchStep && cfg.StepRPS <= 0 {
+		return nil, fmt.Errorf("step rate must be greater than 0")
+	}
+	if cfg.Run == nil {
+		cfg.Run = runner.Run
+	}
+
+	result := &Result{}
+	run := func(rps float64) (Step, error) {
+		if len(result.Steps) > 0 && cfg.Cooldown > 0 {
+			time.Sleep(cfg.Cooldown)
+		}
+
+		number := len(result.Steps) + 1
+		if onStart != nil {
+			onStart(number, rps)
+		}
+		step, err := runStep(cfg, number, rps)
+		if err != nil {
+			return Step{}, err
+		}
+
+		result.Steps = append(result.Steps, step)
+		if step.Passed && (result.Best == nil || step.TargetRPS > result.Best.TargetRPS) {
+			best := step
+			result.Best = &best
+		}
+		if onStep != nil {
+			onStep(step)
+		}
+		return step, nil
+	}
+
+	// Ramp up until the SLO breaks or the maximum is reached
+	rps := cfg.StartRPS
+	var failedRPS float64
+	for {
+		step, err := run(rps)
+		if err != nil {
+			return nil, err
+		}
+		if !step.Passed {
+			failedRPS = rps
+			break
+		}
+		if rps >= cfg.MaxRPS {
+			result.HitMaxRPS = true
+			return result, nil
+		}
+		if cfg.Search == SearchStep {
+			rps += cfg.StepRPS
+		} else {
+			rps *= 2
+		}
+		if rps > cfg.MaxRPS {
+			rps = cfg.MaxRPS
+		}
+	}
+
+	// Bisect between the last passing and first failing rate
+	if cfg.Search == SearchStep || result.Best == nil {
+		return result, nil
+	}
+	passedRPS := result.Best.TargetRPS
+	for failedRPS-passedRPS > cfg.Precision*passedRPS {
+		mid := (passedRPS + failedRPS) / 2
+		step, err := run(mid)
+		if err != nil {
+			return nil, err
+		}
+		if step.Passed {
+			passedRPS = mid
+		} else {
+			failedRPS = mid
+		}
+	}
+	return result, nil
+}
+
+// runStep runs the load test at one rate and checks it against the SLO
+func runStep(cfg Config, number int, rps float64) (Step, error) {
+	config := cfg.Base
+	config.MaxRPS = rps
+
+	summary, err := cfg.Run(config)
+	if err != nil {
+		return Step{}, err
+	}
+
+	step := Step{
+		Number:    number,
+		TargetRPS: rps,
+		Summary:   summary,
+		SLO:       threshold.EvaluateAll(cfg.SLO, threshold.SummaryValues(summary)),
+	}
+	step.Passed = threshold.AllPassed(step.SLO)
+
+	for _, r := range step.SLO {
+		if !r.Passed {
+			step.Reason = r.Message()
+			break
+		}
+	}
+
+	// A rate that could not be reached was not sustained, whatever the latencies say
+	if step.Passed && summary.RPS < rps*minAchievedRatio {
+		step.Passed = false
+		step.Reason = fmt.Sprintf("achieved %.1f/s of %.1f/s target", summary.RPS, rps)
+	}
+	return step, nil
+}
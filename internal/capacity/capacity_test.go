@@ -0,0 +1,187 @@
+package capacity
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/calummacc/g0/internal/runner"
+	"github.com/calummacc/g0/internal/threshold"
+)
+
+// fakeTarget simulates a target that keeps p95 under 100ms up to capacity requests per second,
+// while the load generator reaches at most reachable requests per second
+type fakeTarget struct {
+	capacity  float64
+	reachable float64
+	rates     []float64 // Rate of each step run
+}
+
+// run is a step runner that returns the summary the target would produce at config.MaxRPS
+func (f *fakeTarget) run(config runner.Config) (*runner.Summary, error) {
+	f.rates = append(f.rates, config.MaxRPS)
+	summary := &runner.Summary{RPS: config.MaxRPS, P95Latency: 50 * time.Millisecond}
+	if f.reachable > 0 && summary.RPS > f.reachable {
+		summary.RPS = f.reachable
+	}
+	if config.MaxRPS > f.capacity {
+		summary.P95Latency = 200 * time.Millisecond
+	}
+	return summary, nil
+}
+
+// searchConfig returns a search configuration checking p95<100ms against target
+func searchConfig(t *testing.T, target *fakeTarget, search string, start, max, step float64) Config {
+	t.Helper()
+	slo, err := threshold.ParseList("p95<100ms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Config{
+		Base:      runner.Config{URLs: []string{"http://example.test/"}, Concurrency: 4},
+		SLO:       slo,
+		Search:    search,
+		StartRPS:  start,
+		MaxRPS:    max,
+		StepRPS:   step,
+		Precision: 0.05,
+		Run:       target.run,
+	}
+}
+
+func TestBinarySearch(t *testing.T) {
+	target := &fakeTarget{capacity: 130}
+	var started, finished []int
+	result, err := Search(searchConfig(t, target, SearchBinary, 10, 1000, 0),
+		func(number int, rps float64) { started = append(started, number) },
+		func(step Step) { finished = append(finished, step.Number) })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Doubling until 160 breaks the SLO, then bisecting down to a 5% gap
+	want := []float64{10, 20, 40, 80, 160, 120, 140, 130, 135}
+	if !reflect.DeepEqual(target.rates, want) {
+		t.Errorf("rates = %v, want %v", target.rates, want)
+	}
+	if result.Best == nil || result.Best.TargetRPS != 130 || result.HitMaxRPS {
+		t.Errorf("best = %+v (hit max %v), want 130/s", result.Best, result.HitMaxRPS)
+	}
+	if len(result.Steps) != len(want) || len(started) != len(want) || !reflect.DeepEqual(started, finished) {
+		t.Errorf("%d steps, started %v, finished %v", len(result.Steps), started, finished)
+	}
+	for i, step := range result.Steps {
+		if step.Number != i+1 || step.TargetRPS != want[i] || step.Passed != (want[i] <= 130) {
+			t.Errorf("step %d = %d at %.1f/s passed %v", i, step.Number, step.TargetRPS, step.Passed)
+		}
+	}
+	if failed := result.Steps[4]; failed.Reason != failed.SLO[0].Message() || !strings.HasPrefix(failed.Reason, "p95 = ") {
+		t.Errorf("reason = %q, want the broken SLO", failed.Reason)
+	}
+}
+
+func TestStepSearch(t *testing.T) {
+	target := &fakeTarget{capacity: 260}
+	result, err := Search(searchConfig(t, target, SearchStep, 100, 1000, 50), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// No bisection after the first failure
+	if want := []float64{100, 150, 200, 250, 300}; !reflect.DeepEqual(target.rates, want) {
+		t.Errorf("rates = %v, want %v", target.rates, want)
+	}
+	if result.Best == nil || result.Best.TargetRPS != 250 {
+		t.Errorf("best = %+v, want 250/s", result.Best)
+	}
+}
+
+func TestSearchStopsAtMaxRPS(t *testing.T) {
+	for search, want := range map[string][]float64{
+		SearchBinary: {10, 20, 40, 80, 100},
+		SearchStep:   {10, 40, 70, 100},
+	} {
+		target := &fakeTarget{capacity: 1000}
+		result, err := Search(searchConfig(t, target, search, 10, 100, 30), nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(target.rates, want) {
+			t.Errorf("%s: rates = %v, want %v", search, target.rates, want)
+		}
+		if !result.HitMaxRPS || result.Best == nil || result.Best.TargetRPS != 100 {
+			t.Errorf("%s: best = %+v (hit max %v), want 100/s at the maximum", search, result.Best, result.HitMaxRPS)
+		}
+	}
+}
+
+func TestSearchFailsUnreachedRates(t *testing.T) {
+	// Latencies stay low, but the generator cannot send more than 70/s
+	target := &fakeTarget{capacity: 1000, reachable: 70}
+	result, err := Search(searchConfig(t, target, SearchBinary, 10, 1000, 0), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if step := result.Steps[3]; step.TargetRPS != 80 || step.Passed || step.Reason != "achieved 70.0/s of 80.0/s target" {
+		t.Errorf("step 4 = %.1f/s passed %v (%q), want 80/s failed for the rate", step.TargetRPS, step.Passed, step.Reason)
+	}
+	if result.Best == nil || result.Best.TargetRPS > 70/minAchievedRatio {
+		t.Errorf("best = %+v, want at most %.1f/s", result.Best, 70/minAchievedRatio)
+	}
+}
+
+func TestSearchWithoutPassingStep(t *testing.T) {
+	target := &fakeTarget{capacity: 5}
+	result, err := Search(searchConfig(t, target, SearchBinary, 10, 1000, 0), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Steps) != 1 || result.Best != nil || result.HitMaxRPS {
+		t.Errorf("%d steps with best %+v, want a single failed step", len(result.Steps), result.Best)
+	}
+}
+
+func TestSearchPassesBaseConfig(t *testing.T) {
+	var configs []runner.Config
+	cfg := searchConfig(t, &fakeTarget{}, SearchBinary, 10, 1000, 0)
+	cfg.Run = func(config runner.Config) (*runner.Summary, error) {
+		configs = append(configs, config)
+		return &runner.Summary{RPS: config.MaxRPS, P95Latency: time.Second}, nil
+	}
+	if _, err := Search(cfg, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := cfg.Base
+	want.MaxRPS = 10
+	if len(configs) != 1 || !reflect.DeepEqual(configs[0], want) {
+		t.Errorf("step configs = %+v, want %+v", configs, want)
+	}
+}
+
+func TestSearchReturnsStepErrors(t *testing.T) {
+	cfg := searchConfig(t, &fakeTarget{}, SearchBinary, 10, 1000, 0)
+	failure := errors.New("no URLs")
+	cfg.Run = func(runner.Config) (*runner.Summary, error) { return nil, failure }
+	if _, err := Search(cfg, nil, nil); !errors.Is(err, failure) {
+		t.Errorf("error = %v, want the step's error", err)
+	}
+}
+
+func TestSearchRejectsInvalidConfig(t *testing.T) {
+	for name, cfg := range map[string]Config{
+		"no start rate":       {Search: SearchBinary, MaxRPS: 100},
+		"max below start":     {Search: SearchBinary, StartRPS: 100, MaxRPS: 10},
+		"step search no step": {Search: SearchStep, StartRPS: 10, MaxRPS: 100},
+	} {
+		cfg.Run = func(runner.Config) (*runner.Summary, error) {
+			t.Fatalf("%s: step run despite the invalid config", name)
+			return nil, nil
+		}
+		if _, err := Search(cfg, nil, nil); err == nil {
+			t.Errorf("%s: Search succeeded, want an error", name)
+		}
+	}
+}
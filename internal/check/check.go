@@ -0,0 +1,184 @@
+package check
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Subjects of a response that a check can inspect
+const (
+	SubjectStatus = "status"
+	SubjectHeader = "header"
+	SubjectBody   = "body"
+)
+
+// statusOperators in match order (two-character operators first)
+var statusOperators = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+// Check is an assertion evaluated against every response, such as "status==200",
+// "header Content-Type contains json" or `body contains "ok"`
+// A failed check does not fail the request; passes and failures are counted per check
+type Check struct {
+	Expr     string // Original expression
+	Subject  string // SubjectStatus, SubjectHeader or SubjectBody
+	Header   string // Header name (SubjectHeader only)
+	Operator string // ==, !=, <, <=, >, >= for status; contains, !contains, ==, != for headers; contains, !contains for the body
+	Value    string
+}
+
+// Response is what checks are evaluated against
+type Response struct {
+	StatusCode int // 0 if no response was received, which fails every check
+	Header     http.Header
+	Body       []byte // Only read if a check needs it
+}
+
+// Parse parses a single check expression:
+//
+//	status==200, status<400 (also !=, <=, >, >=)
+//	header Content-Type contains json (also !contains, ==, !=)
+//	body contains "ok" (also !contains)
+//
+// Values may be double-quoted, e.g. to include leading or trailing spaces
+func Parse(expr string) (Check, error) {
+	expr = strings.TrimSpace(expr)
+	subject, rest, _ := strings.Cut(expr, " ")
+	if strings.HasPrefix(strings.ToLower(expr), SubjectStatus) {
+		subject, rest = expr[:len(SubjectStatus)], expr[len(SubjectStatus):]
+	}
+	rest = strings.TrimSpace(rest)
+
+	c := Check{Expr: expr, Subject: strings.ToLower(subject)}
+	switch c.Subject {
+	case SubjectStatus:
+		for _, op := range statusOperators {
+			if strings.HasPrefix(rest, op) {
+				c.Operator = op
+				c.Value = strings.TrimSpace(rest[len(op):])
+				break
+			}
+		}
+		if c.Operator == "" {
+			return Check{}, fmt.Errorf("invalid check %q: expected a comparison such as status==200", expr)
+		}
+		if _, err := strconv.Atoi(c.Value); err != nil {
+			return Check{}, fmt.Errorf("invalid check %q: invalid status code %q", expr, c.Value)
+		}
+		return c, nil
+	case SubjectHeader:
+		c.Header, rest, _ = strings.Cut(rest, " ")
+		if c.Header == "" {
+			return Check{}, fmt.Errorf("invalid check %q: expected e.g. header Content-Type contains json", expr)
+		}
+		if err := c.parseMatch(strings.TrimSpace(rest), "contains", "!contains", "==", "!="); err != nil {
+			return Check{}, err
+		}
+		return c, nil
+	case SubjectBody:
+		if err := c.parseMatch(rest, "contains", "!contains"); err != nil {
+			return Check{}, err
+		}
+		return c, nil
+	default:
+		return Check{}, fmt.Errorf("invalid check %q (expected e.g. status==200, header Content-Type contains json or body contains ok)", expr)
+	}
+}
+
+// parseMatch parses "<operator> <value>" for header and body checks
+func (c *Check) parseMatch(rest string, operators ...string) error {
+	op, value, _ := strings.Cut(rest, " ")
+	for _, allowed := range operators {
+		if op == allowed {
+			c.Operator = op
+		}
+	}
+	if c.Operator == "" {
+		return fmt.Errorf("invalid check %q: unknown operator %q (supported: %s)", c.Expr, op, strings.Join(operators, ", "))
+	}
+
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, `"`) {
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return fmt.Errorf("invalid check %q: invalid quoted value %s", c.Expr, value)
+		}
+		value = unquoted
+	}
+	if value == "" && (op == "contains" || op == "!contains") {
+		return fmt.Errorf("invalid check %q: missing value", c.Expr)
+	}
+	c.Value = value
+	return nil
+}
+
+// String returns the original expression
+func (c Check) String() string {
+	return c.Expr
+}
+
+// Evaluate reports whether the response passes the check
+func (c Check) Evaluate(r Response) bool {
+	if r.StatusCode == 0 {
+		return false
+	}
+
+	switch c.Subject {
+	case SubjectStatus:
+		want, _ := strconv.Atoi(c.Value)
+		switch c.Operator {
+		case "==":
+			return r.StatusCode == want
+		case "!=":
+			return r.StatusCode != want
+		case "<":
+			return r.StatusCode < want
+		case "<=":
+			return r.StatusCode <= want
+		case ">":
+			return r.StatusCode > want
+		case ">=":
+			return r.StatusCode >= want
+		}
+	case SubjectHeader:
+		value := r.Header.Get(c.Header)
+		switch c.Operator {
+		case "contains":
+			return strings.Contains(value, c.Value)
+		case "!contains":
+			return !strings.Contains(value, c.Value)
+		case "==":
+			return value == c.Value
+		case "!=":
+			return value != c.Value
+		}
+	case SubjectBody:
+		contains := bytes.Contains(r.Body, []byte(c.Value))
+		if c.Operator == "!contains" {
+			return !contains
+		}
+		return contains
+	}
+	return false
+}
+
+// EvaluateAll evaluates every check against the response, in order
+func EvaluateAll(checks []Check, r Response) []bool {
+	passed := make([]bool, len(checks))
+	for i, c := range checks {
+		passed[i] = c.Evaluate(r)
+	}
+	return passed
+}
+
+// NeedsBody reports whether any check inspects the response body
+func NeedsBody(checks []Check) bool {
+	for _, c := range checks {
+		if c.Subject == SubjectBody {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package check
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr string
+		want Check
+	}{
+		{"status==200", Check{Subject: SubjectStatus, Operator: "==", Value: "200"}},
+		{"status < 400", Check{Subject: SubjectStatus, Operator: "<", Value: "400"}},
+		{"Status>=200", Check{Subject: SubjectStatus, Operator: ">=", Value: "200"}},
+		{"header Content-Type contains json", Check{Subject: SubjectHeader, Header: "Content-Type", Operator: "contains", Value: "json"}},
+		{"header X-Cache == HIT", Check{Subject: SubjectHeader, Header: "X-Cache", Operator: "==", Value: "HIT"}},
+		{`body contains "\"ok\": true"`, Check{Subject: SubjectBody, Operator: "contains", Value: `"ok": true`}},
+		{"body !contains error", Check{Subject: SubjectBody, Operator: "!contains", Value: "error"}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q) error = %v", tt.expr, err)
+			continue
+		}
+		tt.want.Expr = tt.expr
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"status",
+		"status==ok",
+		"status=~200",
+		"header",
+		"header Content-Type matches json",
+		"body == ok",
+		"body contains",
+		`body contains "unterminated`,
+		"latency<300ms",
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", expr)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	response := Response{
+		StatusCode: 201,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       []byte(`{"ok": true}`),
+	}
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"status==201", true},
+		{"status==200", false},
+		{"status!=500", true},
+		{"status<300", true},
+		{"status>=300", false},
+		{"header Content-Type contains json", true},
+		{"header content-type == application/json", true},
+		{"header Content-Type != application/json", false},
+		{"header X-Missing !contains anything", true},
+		{`body contains "\"ok\": true"`, true},
+		{"body !contains ok", false},
+	}
+	for _, tt := range tests {
+		c, err := Parse(tt.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := c.Evaluate(response); got != tt.want {
+			t.Errorf("%q on %+v = %v, want %v", tt.expr, response, got, tt.want)
+		}
+	}
+}
+
+func TestEvaluateWithoutResponse(t *testing.T) {
+	for _, expr := range []string{"status!=500", "header X-Missing !contains x", "body !contains error"} {
+		c, err := Parse(expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Evaluate(Response{}) {
+			t.Errorf("%q passed without a response", expr)
+		}
+	}
+}
+
+func TestNeedsBody(t *testing.T) {
+	status, _ := Parse("status==200")
+	body, _ := Parse("body contains ok")
+	if NeedsBody([]Check{status}) {
+		t.Error("NeedsBody(status) = true, want false")
+	}
+	if !NeedsBody([]Check{status, body}) {
+		t.Error("NeedsBody(status, body) = false, want true")
+	}
+}
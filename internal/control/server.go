@@ -0,0 +1,325 @@
+package control
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/calummacc/g0/internal/runner"
+)
+
+// TokenEnv is the environment variable holding the bearer token required by the control API
+const TokenEnv = "G0_CONTROL_TOKEN"
+
+// States of a load test reported by the control API
+const (
+	StateStarting = "starting" // The load test has not started yet
+	StateWarmup   = "warmup"   // Results are excluded as warm-up
+	StateRunning  = "running"
+	StatePaused   = "paused"
+	StateStopping = "stopping" // Stopped early, waiting for requests in flight
+	StateFinished = "finished"
+)
+
+// liveReportInterval is how often the report of a running load test is rebuilt, however
+// often the API is polled: building it computes every percentile of the results so far
+const liveReportInterval = time.Second
+
+// Options configure the control API
+type Options struct {
+	Control *runner.Control
+	Token   string                                    // Required as a bearer token if set
+	Report  func(summary *runner.Summary) interface{} // Builds the JSON report of a summary
+	Logf    func(format string, args ...interface{})  // Logs changes made through the API (optional)
+}
+
+// Status is the response of every endpoint
+type Status struct {
+	State     string      `json:"state"`
+	Elapsed   string      `json:"elapsed"`
+	ElapsedMs int64       `json:"elapsed_ms"`
+	PausedMs  int64       `json:"paused_ms"`
+	Workers   int         `json:"workers"`
+	MaxRPS    float64     `json:"max_rps"`          // 0 = unlimited
+	Report    interface{} `json:"report,omitempty"` // Results so far, or the final results once finished
+}
+
+// Server is an HTTP API to watch and steer a running load test
+type Server struct {
+	options  Options
+	server   *http.Server
+	listener net.Listener
+
+	mu       sync.Mutex
+	stats    *runner.Stats
+	started  time.Time
+	final    *runner.Summary
+	live     interface{} // Last report built while running
+	liveAt   time.Time
+	finished chan struct{} // Closed by Finish
+	finish   sync.Once
+}
+
+// NewServer creates a control API that will listen on addr (e.g. "127.0.0.1:6565")
+func NewServer(addr string, options Options) *Server {
+	s := &Server{options: options, finished: make(chan struct{})}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/stats", s.handleStats)
+	mux.HandleFunc("/workers", s.handleWorkers)
+	mux.HandleFunc("/rate", s.handleRate)
+	mux.HandleFunc("/pause", s.handlePause)
+	mux.HandleFunc("/resume", s.handleResume)
+	mux.HandleFunc("/stop", s.handleStop)
+
+	s.server = &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	return s
+}
+
+// Start binds the listen address and serves the API in the background
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.server.Addr, err)
+	}
+	s.listener = listener
+
+	go s.server.Serve(listener)
+	return nil
+}
+
+// Addr returns the address the server is listening on
+func (s *Server) Addr() string {
+	if s.listener == nil {
+		return s.server.Addr
+	}
+	return s.listener.Addr().String()
+}
+
+// SetStats sets the stats of the running load test; its clock starts now
+func (s *Server) SetStats(stats *runner.Stats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats = stats
+	s.started = time.Now()
+}
+
+// Finish records the final summary of the load test (nil if it failed) and answers pending stop requests
+func (s *Server) Finish(summary *runner.Summary) {
+	s.finish.Do(func() {
+		s.mu.Lock()
+		s.final = summary
+		s.mu.Unlock()
+		close(s.finished)
+	})
+}
+
+// Stop shuts the server down, waiting briefly for responses in progress
+func (s *Server) Stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	s.server.Shutdown(ctx)
+}
+
+// handleStats returns the state and the results so far
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	if !s.accept(w, r, http.MethodGet) {
+		return
+	}
+	s.writeStatus(w)
+}
+
+// handleWorkers changes the number of workers, e.g. {"workers": 50}
+func (s *Server) handleWorkers(w http.ResponseWriter, r *http.Request) {
+	if !s.accept(w, r, http.MethodPost) {
+		return
+	}
+	var request struct {
+		Workers *int `json:"workers"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Workers == nil {
+		http.Error(w, `invalid request: expected {"workers": <count>}`, http.StatusBadRequest)
+		return
+	}
+	previous := s.options.Control.Workers()
+	if err := s.options.Control.SetWorkers(*request.Workers); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	s.logf("workers: %d -> %d", previous, *request.Workers)
+	s.writeStatus(w)
+}
+
+// handleRate changes the rate limit, e.g. {"max_rps": 200} (0 = unlimited)
+func (s *Server) handleRate(w http.ResponseWriter, r *http.Request) {
+	if !s.accept(w, r, http.MethodPost) {
+		return
+	}
+	var request struct {
+		MaxRPS *float64 `json:"max_rps"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.MaxRPS == nil {
+		http.Error(w, `invalid request: expected {"max_rps": <requests per second>}`, http.StatusBadRequest)
+		return
+	}
+	previous := s.options.Control.Rate()
+	if err := s.options.Control.SetRate(*request.MaxRPS); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	s.logf("max-rps: %s -> %s", rateString(previous), rateString(*request.MaxRPS))
+	s.writeStatus(w)
+}
+
+// handlePause holds the workers back until resumed
+func (s *Server) handlePause(w http.ResponseWriter, r *http.Request) {
+	if !s.accept(w, r, http.MethodPost) {
+		return
+	}
+	if s.options.Control.Pause() {
+		s.logf("paused")
+	}
+	s.writeStatus(w)
+}
+
+// handleResume lets paused workers continue
+func (s *Server) handleResume(w http.ResponseWriter, r *http.Request) {
+	if !s.accept(w, r, http.MethodPost) {
+		return
+	}
+	if s.options.Control.Resume() {
+		s.logf("resumed")
+	}
+	s.writeStatus(w)
+}
+
+// handleStop ends the load test early and responds with the final results once it has finished
+func (s *Server) handleStop(w http.ResponseWriter, r *http.Request) {
+	if !s.accept(w, r, http.MethodPost) {
+		return
+	}
+	s.logf("stop requested")
+	s.options.Control.Resume()
+	s.options.Control.Stop()
+
+	select {
+	case <-s.finished:
+	case <-r.Context().Done():
+		return
+	}
+	s.mu.Lock()
+	failed := s.final == nil
+	s.mu.Unlock()
+	if failed {
+		http.Error(w, "the load test failed", http.StatusInternalServerError)
+		return
+	}
+	s.writeStatus(w)
+}
+
+// accept checks the method and token of a request, answering it if either is wrong
+func (s *Server) accept(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method != method {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return false
+	}
+	if s.options.Token != "" {
+		expected := "Bearer " + s.options.Token
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(expected)) != 1 {
+			http.Error(w, "invalid control token", http.StatusUnauthorized)
+			return false
+		}
+	}
+	return true
+}
+
+// writeStatus responds with the current status
+func (s *Server) writeStatus(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(s.status())
+}
+
+// status describes the load test and its results so far
+func (s *Server) status() Status {
+	control := s.options.Control
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := Status{
+		State:    StateStarting,
+		PausedMs: control.PausedTime().Milliseconds(),
+		Workers:  control.Workers(),
+		MaxRPS:   control.Rate(),
+	}
+	if s.stats == nil {
+		return status
+	}
+
+	var stopped bool
+	select {
+	case <-control.Stopped():
+		stopped = true
+	default:
+	}
+
+	elapsed := time.Since(s.started)
+	summary := s.final
+	switch {
+	case summary != nil:
+		status.State = StateFinished
+		elapsed = summary.Duration
+		if summary.Warmup != nil {
+			elapsed += summary.Warmup.Duration
+		}
+	case stopped:
+		status.State = StateStopping
+	case control.Paused():
+		status.State = StatePaused
+	case s.stats.GetProgressStats().WarmingUp:
+		status.State = StateWarmup
+	default:
+		status.State = StateRunning
+	}
+	status.Elapsed = elapsed.Round(time.Millisecond).String()
+	status.ElapsedMs = elapsed.Milliseconds()
+	if s.options.Report == nil {
+		return status
+	}
+	if summary != nil {
+		status.Report = s.options.Report(summary)
+		return status
+	}
+	if s.live == nil || time.Since(s.liveAt) >= liveReportInterval {
+		live := s.stats.GetLiveSummary()
+		s.live = s.options.Report(&live)
+		s.liveAt = time.Now()
+	}
+	status.Report = s.live
+	return status
+}
+
+// logf logs through Logf if set
+func (s *Server) logf(format string, args ...interface{}) {
+	if s.options.Logf != nil {
+		s.options.Logf(format, args...)
+	}
+}
+
+// rateString describes a rate limit
+func rateString(maxRPS float64) string {
+	if maxRPS <= 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%g req/s", maxRPS)
+}
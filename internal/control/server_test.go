@@ -0,0 +1,40 @@
+package control
+
+import (
+	"testing"
+
+	"github.com/calummacc/g0/internal/runner"
+)
+
+func TestStatusRebuildsLiveReportAtMostOncePerInterval(t *testing.T) {
+	var builds int
+	s := NewServer("127.0.0.1:0", Options{
+		Control: runner.NewControl(),
+		Report: func(summary *runner.Summary) interface{} {
+			builds++
+			return summary.TotalRequests
+		},
+	})
+	stats := runner.NewStats()
+	s.SetStats(stats)
+
+	stats.AddResult(runner.Result{StatusCode: 200})
+	if got := s.status(); got.State != StateRunning || got.Report != int64(1) {
+		t.Fatalf("status = %+v, want running with 1 request", got)
+	}
+
+	// Polling again right away serves the same report
+	stats.AddResult(runner.Result{StatusCode: 200})
+	for i := 0; i < 10; i++ {
+		s.status()
+	}
+	if builds != 1 {
+		t.Errorf("report built %d times for 11 polls within %s, want 1", builds, liveReportInterval)
+	}
+
+	// The final summary is always reported as is
+	s.Finish(&runner.Summary{TotalRequests: 2})
+	if got := s.status(); got.State != StateFinished || got.Report != int64(2) {
+		t.Errorf("status = %+v, want finished with 2 requests", got)
+	}
+}
@@ -0,0 +1,332 @@
+package dashboard
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/calummacc/g0/internal/runner"
+	"github.com/calummacc/g0/internal/threshold"
+	"golang.org/x/term"
+)
+
+// refreshInterval is how often the dashboard samples the stats and redraws
+const refreshInterval = time.Second
+
+// maxHistory is the number of per-second samples kept for the charts
+const maxHistory = 600
+
+// maxErrorEvents is the number of entries kept in the error feed
+const maxErrorEvents = 50
+
+// Options describe the load test shown on the dashboard
+type Options struct {
+	URLs        []string
+	Method      string
+	Concurrency int
+	MaxRPS      float64
+	Warmup      time.Duration
+	Duration    time.Duration
+	Thresholds  []threshold.Threshold
+	Control     *runner.Control // Receives pause, resume and stop requests
+
+	// Save writes a report of the results so far and returns where it was saved
+	Save func(summary *runner.Summary, thresholds []threshold.Result) (string, error)
+}
+
+// errorEvent is an entry of the error feed: new occurrences of a message since the previous sample
+type errorEvent struct {
+	At      time.Time
+	Class   string
+	Message string
+	Count   int64
+}
+
+// Dashboard is a full-screen terminal view of a running load test
+// It redraws every second from the live stats and reads single-key commands from the terminal
+type Dashboard struct {
+	options Options
+	in      *os.File
+	out     *os.File
+	state   *term.State
+
+	mu      sync.Mutex
+	stats   *runner.Stats
+	started time.Time // When the stats were received (the run's clock)
+
+	source       *runner.Stats // Stats of the current period (warm-up or measurement)
+	lastSample   time.Time
+	lastTotal    int64
+	latencyIndex int
+
+	rps, p50, p95, p99 []float64 // One point per second, latencies in milliseconds
+
+	progress   runner.ProgressStats
+	summary    runner.Summary // Of the current period
+	thresholds []threshold.Result
+	inFlight   int64
+
+	errors     []errorEvent // Newest last
+	errorSeen  map[string]int64
+	notice     string // Outcome of the last command, shown in the footer
+	stopping   bool
+	savedPaths []string
+
+	done     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// Start switches the terminal to a full-screen dashboard until Stop is called
+// Both stdin and stdout must be terminals
+func Start(options Options) (*Dashboard, error) {
+	in, out := os.Stdin, os.Stdout
+	if !term.IsTerminal(int(in.Fd())) || !term.IsTerminal(int(out.Fd())) {
+		return nil, fmt.Errorf("--ui requires an interactive terminal")
+	}
+	state, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up the terminal: %w", err)
+	}
+
+	d := &Dashboard{
+		options:   options,
+		in:        in,
+		out:       out,
+		state:     state,
+		started:   time.Now(),
+		errorSeen: make(map[string]int64),
+		done:      make(chan struct{}),
+	}
+	// Alternate screen, hidden cursor
+	out.WriteString("\033[?1049h\033[?25l")
+	d.draw()
+
+	d.wg.Add(1)
+	go d.run()
+	go d.readKeys()
+	return d, nil
+}
+
+// SetStats attaches the stats of the run; the run's clock starts now
+func (d *Dashboard) SetStats(stats *runner.Stats) {
+	d.mu.Lock()
+	d.stats = stats
+	d.started = time.Now()
+	d.lastSample = d.started
+	d.mu.Unlock()
+	d.draw()
+}
+
+// Stop restores the terminal and notes how the run was controlled
+func (d *Dashboard) Stop() {
+	d.stopOnce.Do(func() {
+		close(d.done)
+		d.wg.Wait()
+		d.out.WriteString("\033[?25h\033[?1049l")
+		term.Restore(int(d.in.Fd()), d.state)
+
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		if d.stopping {
+			fmt.Fprintf(os.Stderr, "Stopped early from the dashboard after %s\n", formatElapsed(time.Since(d.started)))
+		}
+		if paused := d.options.Control.PausedTime(); paused > 0 {
+			fmt.Fprintf(os.Stderr, "Paused for %s (counted in the duration)\n", formatElapsed(paused))
+		}
+		for _, path := range d.savedPaths {
+			fmt.Fprintf(os.Stderr, "Report saved from the dashboard: %s\n", path)
+		}
+	})
+}
+
+// run samples and redraws every refreshInterval until stopped
+func (d *Dashboard) run() {
+	defer d.wg.Done()
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			d.sample()
+			d.draw()
+		case <-d.done:
+			return
+		}
+	}
+}
+
+// readKeys handles single-key commands until the process exits
+// (a blocked read on stdin cannot be interrupted, so this goroutine is not waited for)
+func (d *Dashboard) readKeys() {
+	buf := make([]byte, 16)
+	for {
+		n, err := d.in.Read(buf)
+		if err != nil {
+			return
+		}
+		for _, key := range buf[:n] {
+			select {
+			case <-d.done:
+				return
+			default:
+			}
+			d.handleKey(key)
+		}
+	}
+}
+
+// handleKey runs the command bound to key and redraws
+func (d *Dashboard) handleKey(key byte) {
+	control := d.options.Control
+	d.mu.Lock()
+	switch key {
+	case 'p', 'P', ' ':
+		if d.stopping {
+			break
+		}
+		if control.Paused() {
+			control.Resume()
+			d.notice = "Resumed"
+		} else {
+			control.Pause()
+			d.notice = "Paused: press p to resume"
+		}
+	case 'q', 'Q', 3: // 3 is Ctrl+C, which raw mode delivers as a key
+		if !d.stopping {
+			d.stopping = true
+			d.notice = "Stopping: waiting for requests in flight"
+			control.Resume()
+			control.Stop()
+		}
+	case 's', 'S':
+		d.notice = d.save()
+	}
+	d.mu.Unlock()
+	d.draw()
+}
+
+// save writes a report of the results so far and describes the outcome (caller holds the lock)
+func (d *Dashboard) save() string {
+	if d.stats == nil || d.options.Save == nil {
+		return "Nothing to save yet"
+	}
+	summary := d.stats.GetLiveSummary()
+	results := threshold.EvaluateAll(d.options.Thresholds, threshold.SummaryValues(&summary))
+	path, err := d.options.Save(&summary, results)
+	if err != nil {
+		return fmt.Sprintf("Failed to save the report: %v", err)
+	}
+	d.savedPaths = append(d.savedPaths, path)
+	return "Report saved to " + path
+}
+
+// sample records the requests and latencies since the previous sample
+func (d *Dashboard) sample() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.stats == nil {
+		return
+	}
+
+	now := time.Now()
+	progress := d.stats.GetProgressStats()
+	source := d.stats
+	if progress.WarmingUp {
+		source = d.stats.Warmup
+	}
+	// The measurement starts from zero when the warm-up ends
+	if source != d.source {
+		d.source = source
+		d.lastTotal = 0
+		d.latencyIndex = 0
+		d.errorSeen = make(map[string]int64)
+	}
+
+	latencies, next := source.GetLatenciesSince(d.latencyIndex)
+	d.latencyIndex = next
+	var rps float64
+	if elapsed := now.Sub(d.lastSample); elapsed > 0 {
+		rps = float64(progress.TotalRequests-d.lastTotal) / elapsed.Seconds()
+	}
+	d.lastSample = now
+	d.lastTotal = progress.TotalRequests
+
+	d.rps = appendHistory(d.rps, rps)
+	d.p50 = appendHistory(d.p50, milliseconds(runner.Percentile(latencies, 50)))
+	d.p95 = appendHistory(d.p95, milliseconds(runner.Percentile(latencies, 95)))
+	d.p99 = appendHistory(d.p99, milliseconds(runner.Percentile(latencies, 99)))
+
+	d.progress = progress
+	d.summary = source.GetLiveSummary()
+	d.inFlight = d.stats.GetMetricsSnapshot().InFlight
+	d.thresholds = nil
+	if !progress.WarmingUp {
+		d.thresholds = threshold.EvaluateAll(d.options.Thresholds, threshold.SummaryValues(&d.summary))
+	}
+	d.recordErrors(now)
+}
+
+// recordErrors adds the failures that occurred since the previous sample to the error feed:
+// error status codes, then network errors by message
+func (d *Dashboard) recordErrors(now time.Time) {
+	codes := make([]int, 0, len(d.summary.StatusCodeCounts))
+	for code := range d.summary.StatusCodeCounts {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	for _, code := range codes {
+		class, message := statusError(code, d.summary.GRPC)
+		if class != "" {
+			d.recordError(now, class, message, d.summary.StatusCodeCounts[code])
+		}
+	}
+	for _, class := range d.summary.Errors {
+		for _, message := range class.Messages {
+			d.recordError(now, class.Class, message.Message, message.Count)
+		}
+	}
+	if len(d.errors) > maxErrorEvents {
+		d.errors = d.errors[len(d.errors)-maxErrorEvents:]
+	}
+}
+
+// recordError adds an entry to the error feed if count grew since the previous sample
+func (d *Dashboard) recordError(now time.Time, class, message string, count int64) {
+	key := class + "\x00" + message
+	if added := count - d.errorSeen[key]; added > 0 {
+		d.errors = append(d.errors, errorEvent{At: now, Class: class, Message: message, Count: added})
+	}
+	d.errorSeen[key] = count
+}
+
+// statusError describes a failed status code for the error feed (empty class for a success,
+// or for the network errors that have their own messages)
+func statusError(code int, grpc bool) (class, message string) {
+	switch {
+	case grpc && code != 0:
+		return "grpc", fmt.Sprintf("status %d", code)
+	case !grpc && code >= 400:
+		return "http", strings.TrimSpace(fmt.Sprintf("%d %s", code, http.StatusText(code)))
+	default:
+		return "", ""
+	}
+}
+
+// appendHistory adds a sample, dropping the oldest beyond maxHistory
+func appendHistory(history []float64, v float64) []float64 {
+	history = append(history, v)
+	if len(history) > maxHistory {
+		history = history[len(history)-maxHistory:]
+	}
+	return history
+}
+
+// milliseconds converts a duration to fractional milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,430 @@
+package dashboard
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// Fallback size when the terminal does not report one
+const (
+	defaultWidth  = 100
+	defaultHeight = 30
+)
+
+// rpsChartHeight is the number of rows of the requests per second chart
+const rpsChartHeight = 5
+
+// levels are the partial blocks used to draw chart bars, from empty to full
+var levels = []rune(" ▁▂▃▄▅▆▇█")
+
+// Text attributes
+const (
+	bold  = "\033[1m"
+	dim   = "\033[2m"
+	reset = "\033[0m"
+)
+
+// draw renders the whole screen in a single write
+func (d *Dashboard) draw() {
+	width, height, err := term.GetSize(int(d.out.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		width, height = defaultWidth, defaultHeight
+	}
+
+	d.mu.Lock()
+	lines := d.render(width, height)
+	d.mu.Unlock()
+
+	var b strings.Builder
+	b.WriteString("\033[H")
+	for i, line := range lines {
+		if i > 0 {
+			// Raw mode does not turn a line feed into a carriage return
+			b.WriteString("\r\n")
+		}
+		b.WriteString(line)
+		b.WriteString(reset + "\033[K")
+	}
+	b.WriteString("\033[J")
+	d.out.WriteString(b.String())
+}
+
+// render lays out the sections top to bottom, giving the error feed whatever rows are left
+// (caller holds the lock)
+func (d *Dashboard) render(width, height int) []string {
+	var lines []string
+	add := func(section ...string) {
+		for _, line := range section {
+			lines = append(lines, truncate(line, width))
+		}
+	}
+
+	add(d.header()...)
+	if d.stats != nil {
+		add("")
+		add(d.rpsChart(width)...)
+		add("")
+		add(d.latencyChart(width)...)
+		add("")
+		add(sideBySide(d.statusTable(), d.thresholdTable(), width)...)
+		add("")
+		add(d.endpointTable(width)...)
+		if rows := height - len(lines) - 3; rows > 0 {
+			add("")
+			add(d.errorFeed(rows)...)
+		}
+	}
+
+	// Keep the footer on the last row
+	if len(lines) > height-1 {
+		lines = lines[:height-1]
+	}
+	for len(lines) < height-1 {
+		lines = append(lines, "")
+	}
+	return append(lines, truncate(d.footer(width), width))
+}
+
+// header shows the target, the stage of the run and the totals so far
+func (d *Dashboard) header() []string {
+	target := d.options.Method + " " + d.options.URLs[0]
+	if len(d.options.URLs) > 1 {
+		target += fmt.Sprintf(" (+%d more)", len(d.options.URLs)-1)
+	}
+	// Both can change while the test runs
+	workers, maxRPS := d.options.Concurrency, d.options.MaxRPS
+	if n := d.options.Control.Workers(); n > 0 {
+		workers, maxRPS = n, d.options.Control.Rate()
+	}
+	load := fmt.Sprintf("%d workers", workers)
+	if maxRPS > 0 {
+		load += fmt.Sprintf(", max %g req/s", maxRPS)
+	}
+	lines := []string{bold + "g0" + reset + "  " + target + "  " + dim + load + reset}
+
+	if d.stats == nil {
+		return append(lines, "", "Waiting for the load test to start...")
+	}
+
+	total := d.options.Warmup + d.options.Duration
+	elapsed := time.Since(d.started)
+	if elapsed > total {
+		elapsed = total
+	}
+	barWidth := 30
+	filled := int(float64(barWidth) * float64(elapsed) / float64(total))
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
+	stage := fmt.Sprintf("%s%-9s%s [%s] %s / %s", bold, d.stage(), reset, bar, formatElapsed(elapsed), formatElapsed(total))
+	if paused := d.options.Control.PausedTime(); paused > 0 {
+		stage += fmt.Sprintf("   paused %s", formatElapsed(paused))
+	}
+	lines = append(lines, stage)
+
+	p := d.progress
+	var errorRate, avgRPS float64
+	if p.TotalRequests > 0 {
+		errorRate = float64(p.FailedRequests) / float64(p.TotalRequests) * 100
+	}
+	if p.Elapsed > 0 {
+		avgRPS = float64(p.TotalRequests) / p.Elapsed.Seconds()
+	}
+	period := "Requests"
+	if p.WarmingUp {
+		period = "Warm-up requests"
+	}
+	lines = append(lines, fmt.Sprintf("%s %d   ✓ %d   ✗ %d (%.2f%%)   RPS %.1f avg   In flight %d",
+		period, p.TotalRequests, p.SuccessRequests, p.FailedRequests, errorRate, avgRPS, d.inFlight))
+	return lines
+}
+
+// stage names what the run is doing
+func (d *Dashboard) stage() string {
+	var stopped bool // Possibly through the control API
+	select {
+	case <-d.options.Control.Stopped():
+		stopped = true
+	default:
+	}
+	switch {
+	case d.stopping || stopped:
+		return "STOPPING"
+	case d.options.Control.Paused():
+		return "PAUSED"
+	case d.progress.WarmingUp:
+		return "WARM-UP"
+	default:
+		return "RUNNING"
+	}
+}
+
+// rpsChart draws the requests completed each second
+func (d *Dashboard) rpsChart(width int) []string {
+	values := tail(d.rps, width-2)
+	peak := maxOf(values)
+	lines := []string{fmt.Sprintf("%sRequests/s%s   now %.1f   peak %.1f", bold, reset, last(values), peak)}
+	for _, row := range bars(values, peak, width-2, rpsChartHeight) {
+		lines = append(lines, "  "+row)
+	}
+	return lines
+}
+
+// latencyChart draws the per-second p50, p95 and p99 on a shared scale so they can be compared
+func (d *Dashboard) latencyChart(width int) []string {
+	chartWidth := width - 15
+	peak := maxOf(tail(d.p99, chartWidth))
+	lines := []string{fmt.Sprintf("%sLatency%s   per second, scale 0-%s", bold, reset, formatMs(peak))}
+	series := []struct {
+		name   string
+		values []float64
+	}{{"p50", d.p50}, {"p95", d.p95}, {"p99", d.p99}}
+	for _, s := range series {
+		value := fmt.Sprintf(" %9s", formatMs(last(s.values)))
+		chart := bars(s.values, peak, chartWidth, 1)[0]
+		lines = append(lines, fmt.Sprintf("%s  %s%s", s.name, chart, value))
+	}
+	return lines
+}
+
+// statusTable lists the status codes of the current period
+func (d *Dashboard) statusTable() []string {
+	title := "Status codes"
+	if d.summary.GRPC {
+		title = "gRPC status codes"
+	}
+	lines := []string{bold + title + reset}
+	codes := make([]int, 0, len(d.summary.StatusCodeCounts))
+	for code := range d.summary.StatusCodeCounts {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	for _, code := range codes {
+		count := d.summary.StatusCodeCounts[code]
+		label := fmt.Sprintf("%d", code)
+		if code == 0 && !d.summary.GRPC {
+			label = "error"
+		}
+		lines = append(lines, fmt.Sprintf("%-6s %10d  %6.2f%%", label, count, float64(count)/float64(d.summary.TotalRequests)*100))
+	}
+	if len(codes) == 0 {
+		lines = append(lines, dim+"no responses yet"+reset)
+	}
+	return lines
+}
+
+// thresholdTable shows whether each threshold passes on the results so far
+func (d *Dashboard) thresholdTable() []string {
+	if len(d.options.Thresholds) == 0 {
+		return nil
+	}
+	lines := []string{bold + "Thresholds" + reset}
+	if d.progress.WarmingUp || d.summary.TotalRequests == 0 {
+		for _, t := range d.options.Thresholds {
+			lines = append(lines, fmt.Sprintf("· %-20s %swaiting for results%s", t.String(), dim, reset))
+		}
+		return lines
+	}
+	for _, r := range d.thresholds {
+		mark := "✓"
+		if !r.Passed {
+			mark = "✗"
+		}
+		lines = append(lines, fmt.Sprintf("%s %-20s %s", mark, r.Threshold.String(), r.Threshold.FormatValue(r.Observed)))
+	}
+	return lines
+}
+
+// endpointTable has one row per URL
+func (d *Dashboard) endpointTable(width int) []string {
+	const columns = "%10s %9s %9s %9s %9s %7s"
+	urlWidth := width - 58
+	if urlWidth < 20 {
+		urlWidth = 20
+	}
+	lines := []string{fmt.Sprintf("%s%-*s"+columns+"%s", bold, urlWidth, "Endpoint", "Requests", "RPS", "Avg", "p95", "p99", "Errors", reset)}
+	for _, e := range d.summary.Endpoints {
+		var errorRate float64
+		if e.TotalRequests > 0 {
+			errorRate = float64(e.FailedRequests) / float64(e.TotalRequests) * 100
+		}
+		lines = append(lines, fmt.Sprintf("%-*s"+columns, urlWidth, truncate(e.URL, urlWidth), fmt.Sprint(e.TotalRequests), fmt.Sprintf("%.1f", e.RPS),
+			formatMs(milliseconds(e.AvgLatency)), formatMs(milliseconds(e.P95Latency)), formatMs(milliseconds(e.P99Latency)), fmt.Sprintf("%.2f%%", errorRate)))
+	}
+	return lines
+}
+
+// errorFeed lists the newest errors, at most rows lines including the title
+func (d *Dashboard) errorFeed(rows int) []string {
+	lines := []string{bold + "Recent errors" + reset}
+	if len(d.errors) == 0 {
+		return append(lines, dim+"none"+reset)
+	}
+	for i := len(d.errors) - 1; i >= 0 && len(lines) < rows; i-- {
+		e := d.errors[i]
+		lines = append(lines, fmt.Sprintf("%s  %-18s ×%-5d %s", e.At.Format("15:04:05"), e.Class, e.Count, e.Message))
+	}
+	return lines
+}
+
+// footer shows the key bindings and the outcome of the last command
+func (d *Dashboard) footer(width int) string {
+	pause := "pause"
+	if d.options.Control.Paused() {
+		pause = "resume"
+	}
+	keys := fmt.Sprintf("%s[p]%s %s  %s[q]%s stop  %s[s]%s save report", bold, reset, pause, bold, reset, bold, reset)
+	if d.notice == "" {
+		return keys
+	}
+	return keys + "   " + d.notice
+}
+
+// bars draws values as a bar chart of the given size, newest on the right
+// Each column is one value; partial blocks give eight steps per row
+func bars(values []float64, peak float64, width, height int) []string {
+	if width < 1 {
+		width = 1
+	}
+	if len(values) > width {
+		values = values[len(values)-width:]
+	}
+	rows := make([]string, height)
+	for row := range rows {
+		line := make([]rune, width)
+		for i := range line {
+			line[i] = ' '
+		}
+		// Row 0 is the top of the chart
+		base := float64((height - 1 - row) * 8)
+		offset := width - len(values)
+		for i, v := range values {
+			if peak <= 0 || v <= 0 {
+				continue
+			}
+			eighths := math.Ceil(v / peak * float64(height*8))
+			level := int(math.Min(math.Max(eighths-base, 0), 8))
+			line[offset+i] = levels[level]
+		}
+		rows[row] = string(line)
+	}
+	return rows
+}
+
+// sideBySide places two sections next to each other when they fit, or one above the other
+func sideBySide(left, right []string, width int) []string {
+	if len(right) == 0 {
+		return left
+	}
+	leftWidth := 0
+	for _, line := range left {
+		if w := visibleWidth(line); w > leftWidth {
+			leftWidth = w
+		}
+	}
+	leftWidth += 4
+	if leftWidth+30 > width {
+		return append(append(left, ""), right...)
+	}
+
+	lines := make([]string, 0, len(left)+len(right))
+	for i := 0; i < len(left) || i < len(right); i++ {
+		var l, r string
+		if i < len(left) {
+			l = left[i]
+		}
+		if i < len(right) {
+			r = right[i]
+		}
+		lines = append(lines, l+strings.Repeat(" ", leftWidth-visibleWidth(l))+r)
+	}
+	return lines
+}
+
+// visibleWidth counts the characters of s, ignoring escape sequences
+func visibleWidth(s string) int {
+	width, escape := 0, false
+	for _, r := range s {
+		switch {
+		case escape:
+			escape = r != 'm'
+		case r == '\033':
+			escape = true
+		default:
+			width++
+		}
+	}
+	return width
+}
+
+// truncate cuts s to width visible characters, keeping escape sequences intact
+func truncate(s string, width int) string {
+	if visibleWidth(s) <= width {
+		return s
+	}
+	var b strings.Builder
+	visible, escape := 0, false
+	for _, r := range s {
+		switch {
+		case escape:
+			escape = r != 'm'
+		case r == '\033':
+			escape = true
+		default:
+			if visible == width {
+				continue
+			}
+			visible++
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// tail returns the newest n values
+func tail(values []float64, n int) []float64 {
+	if n >= 0 && len(values) > n {
+		return values[len(values)-n:]
+	}
+	return values
+}
+
+// last returns the newest value (0 if there is none)
+func last(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	return values[len(values)-1]
+}
+
+// maxOf returns the largest value (0 if there is none)
+func maxOf(values []float64) float64 {
+	var peak float64
+	for _, v := range values {
+		peak = math.Max(peak, v)
+	}
+	return peak
+}
+
+// formatMs formats fractional milliseconds for display
+func formatMs(ms float64) string {
+	switch {
+	case ms <= 0:
+		return "-"
+	case ms < 1:
+		return fmt.Sprintf("%.0fµs", ms*1000)
+	case ms < 1000:
+		return fmt.Sprintf("%.1fms", ms)
+	default:
+		return fmt.Sprintf("%.2fs", ms/1000)
+	}
+}
+
+// formatElapsed formats a run time as m:ss
+func formatElapsed(d time.Duration) string {
+	seconds := int(d.Round(time.Second).Seconds())
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
@@ -0,0 +1,222 @@
+package distributed
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/calummacc/g0/internal/runner"
+)
+
+// Agent runs load tests on behalf of a controller, one at a time
+type Agent struct {
+	Token string                                   // Required as a bearer token if set
+	Logf  func(format string, args ...interface{}) // Logs runs (optional)
+
+	mu      sync.Mutex
+	running bool
+	server  *http.Server
+}
+
+// NewAgent creates an agent that requires token (empty = no authentication)
+func NewAgent(token string, logf func(format string, args ...interface{})) *Agent {
+	return &Agent{Token: token, Logf: logf}
+}
+
+// Listen starts serving on addr and returns the address listened on
+// Without a token, only loopback addresses are allowed: anyone reaching the agent could
+// otherwise make it generate load and read files on the host (e.g. --cert)
+func (a *Agent) Listen(addr string) (string, error) {
+	if a.Token == "" && !isLoopback(addr) {
+		return "", fmt.Errorf("refusing to listen on %s without a token: set %s or listen on a loopback address", addr, TokenEnv)
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", err
+	}
+	a.server = &http.Server{Handler: a.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	go a.server.Serve(listener)
+	return listener.Addr().String(), nil
+}
+
+// Close stops serving; a run in progress is abandoned
+func (a *Agent) Close() error {
+	if a.server == nil {
+		return nil
+	}
+	return a.server.Close()
+}
+
+// Handler returns the HTTP API of the agent
+func (a *Agent) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ping", a.handlePing)
+	mux.HandleFunc("/run", a.handleRun)
+	return mux
+}
+
+// handlePing answers the controller's round-trip measurement
+func (a *Agent) handlePing(w http.ResponseWriter, r *http.Request) {
+	if !a.authorized(r) {
+		http.Error(w, "invalid agent token", http.StatusUnauthorized)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleRun runs a job and streams its partial results until it completes
+func (a *Agent) handleRun(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !a.authorized(r) {
+		http.Error(w, "invalid agent token", http.StatusUnauthorized)
+		return
+	}
+	var j job
+	if err := json.NewDecoder(r.Body).Decode(&j); err != nil {
+		http.Error(w, fmt.Sprintf("invalid job: %v", err), http.StatusBadRequest)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	a.mu.Lock()
+	if a.running {
+		a.mu.Unlock()
+		http.Error(w, "agent is already running a load test", http.StatusConflict)
+		return
+	}
+	a.running = true
+	a.mu.Unlock()
+	defer func() {
+		a.mu.Lock()
+		a.running = false
+		a.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	// Writes stop at the first failure: the controller is gone and the run is stopped below
+	encoder := json.NewEncoder(w)
+	var writeErr error
+	send := func(m message) {
+		if writeErr != nil {
+			return
+		}
+		if writeErr = encoder.Encode(m); writeErr == nil {
+			flusher.Flush()
+		}
+	}
+
+	select {
+	case <-time.After(j.StartIn):
+	case <-r.Context().Done():
+		a.logf("run from %s cancelled before start", r.RemoteAddr)
+		return
+	}
+	a.logf("run from %s: %d workers for %s, %s", r.RemoteAddr, j.Config.Concurrency, j.Config.Warmup+j.Config.Duration, rateString(j.Config.MaxRPS))
+
+	// The run stops early if the controller disconnects (e.g. on Ctrl+C), rather than loading
+	// the target unobserved and keeping the agent busy
+	control := runner.NewControl()
+	j.Config.Control = control
+	disconnected := r.Context().Done()
+
+	statsChan := make(chan *runner.Stats, 1)
+	resultChan := make(chan *runner.RunResult, 1)
+	errChan := make(chan error, 1)
+	go func() {
+		result, err := runner.RunWithStatsAndChannel(j.Config, statsChan)
+		if err != nil {
+			errChan <- err
+			return
+		}
+		resultChan <- result
+	}()
+
+	var stats *runner.Stats
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case s := <-statsChan:
+			stats = s
+		case <-ticker.C:
+			if stats != nil {
+				send(message{Stats: stats.Flush()})
+			}
+			if writeErr != nil && disconnected != nil {
+				a.logf("run from %s stopped: %v", r.RemoteAddr, writeErr)
+				control.Stop()
+				disconnected = nil
+			}
+		case <-disconnected:
+			a.logf("run from %s stopped: the controller disconnected", r.RemoteAddr)
+			control.Stop()
+			disconnected = nil // Wait for the requests in flight
+		case err := <-errChan:
+			a.logf("run from %s failed: %v", r.RemoteAddr, err)
+			send(message{Error: err.Error()})
+			return
+		case result := <-resultChan:
+			// Results recorded since the last flush, then the end of the run
+			send(message{Stats: result.Stats.Flush()})
+			send(message{Done: true, Duration: result.Summary.Duration})
+			if writeErr != nil {
+				a.logf("run from %s completed, but results could not be sent: %v", r.RemoteAddr, writeErr)
+			} else {
+				a.logf("run from %s completed", r.RemoteAddr)
+			}
+			return
+		}
+	}
+}
+
+// authorized reports whether a request carries the agent's token
+func (a *Agent) authorized(r *http.Request) bool {
+	if a.Token == "" {
+		return true
+	}
+	expected := "Bearer " + a.Token
+	return subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(expected)) == 1
+}
+
+// isLoopback reports whether addr (host:port) only accepts local connections
+// An empty host listens on every interface
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// logf logs through Logf if set
+func (a *Agent) logf(format string, args ...interface{}) {
+	if a.Logf != nil {
+		a.Logf(format, args...)
+	}
+}
+
+// rateString describes a rate limit for logs
+func rateString(rps float64) string {
+	if rps <= 0 {
+		return "no rate limit"
+	}
+	return fmt.Sprintf("%.1f req/s", rps)
+}
@@ -0,0 +1,197 @@
+package distributed
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/calummacc/g0/internal/runner"
+)
+
+// startDelay leaves time for every agent to receive its job before the synchronized start
+const startDelay = time.Second
+
+// pingTimeout bounds the round-trip measurement of an agent
+const pingTimeout = 5 * time.Second
+
+// Run executes a load test on agents and combines their results into one summary
+// The stats instance combining the partial results is sent to statsChan at the start, as by runner.RunWithStatsAndChannel
+func Run(config runner.Config, assignments []Assignment, token string, statsChan chan<- *runner.Stats) (*runner.RunResult, error) {
+	client := &http.Client{}
+
+	// Measure round trips so that jobs can be sent to start at the same moment everywhere
+	rtts := make([]time.Duration, len(assignments))
+	var maxRTT time.Duration
+	for i, a := range assignments {
+		rtt, err := ping(client, a.Agent, token)
+		if err != nil {
+			return nil, fmt.Errorf("agent %s: %w", a.Agent, err)
+		}
+		rtts[i] = rtt
+		if rtt > maxRTT {
+			maxRTT = rtt
+		}
+	}
+	delay := startDelay + maxRTT
+	start := time.Now().Add(delay)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Partial results are merged once the combined stats instance exists
+	var stats *runner.Stats
+	ready := make(chan struct{})
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+	var measured time.Duration // Longest measured duration among agents
+	for i, a := range assignments {
+		wg.Add(1)
+		go func(a Assignment, startIn time.Duration) {
+			defer wg.Done()
+			d, err := runAgent(ctx, client, a, token, job{Config: agentConfig(config, a), StartIn: startIn}, ready, func(partial *runner.Stats) {
+				stats.Merge(partial)
+			})
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("agent %s: %w", a.Agent, err)
+				}
+				cancel()
+				return
+			}
+			if d > measured {
+				measured = d
+			}
+		}(a, delay-rtts[i]/2)
+	}
+
+	// Create the combined stats at the synchronized start so that elapsed times line up with the agents
+	select {
+	case <-time.After(time.Until(start)):
+	case <-ctx.Done():
+	}
+	stats = runner.NewRunStats(config)
+	close(ready)
+	if statsChan != nil {
+		select {
+		case statsChan <- stats:
+		default:
+		}
+	}
+
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	stats.FinalizeAt(stats.StartTime.Add(measured))
+	summary := stats.GetSummary()
+	return &runner.RunResult{
+		Stats:   stats,
+		Summary: &summary,
+	}, nil
+}
+
+// runAgent sends a job to an agent and merges the partial results it streams back
+// It returns the agent's measured duration once the run is done
+func runAgent(ctx context.Context, client *http.Client, a Assignment, token string, j job, ready <-chan struct{}, merge func(*runner.Stats)) (time.Duration, error) {
+	body, err := json.Marshal(j)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode job: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://"+a.Agent+"/run", bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	setToken(req, token)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if err := checkStatus(resp); err != nil {
+		return 0, err
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			var m message
+			if err := json.Unmarshal(line, &m); err != nil {
+				return 0, fmt.Errorf("invalid message: %w", err)
+			}
+			if m.Error != "" {
+				return 0, fmt.Errorf("%s", m.Error)
+			}
+			if m.Stats != nil {
+				select {
+				case <-ready:
+					merge(m.Stats)
+				case <-ctx.Done():
+					return 0, ctx.Err()
+				}
+			}
+			if m.Done {
+				return m.Duration, nil
+			}
+		}
+		if err == io.EOF {
+			return 0, fmt.Errorf("connection closed before the run completed")
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+// ping measures the round-trip time to an agent
+func ping(client *http.Client, agent, token string) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+agent+"/ping", nil)
+	if err != nil {
+		return 0, err
+	}
+	setToken(req, token)
+
+	sent := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	rtt := time.Since(sent)
+	if err := checkStatus(resp); err != nil {
+		return 0, err
+	}
+	return rtt, nil
+}
+
+// setToken adds the agent token to a request
+func setToken(req *http.Request, token string) {
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// checkStatus returns an error with the agent's message for a non-200 response
+func checkStatus(resp *http.Response) error {
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+}
@@ -0,0 +1,179 @@
+package distributed
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/calummacc/g0/internal/runner"
+)
+
+func TestSplit(t *testing.T) {
+	assignments, err := Split(runner.Config{Concurrency: 5, MaxRPS: 100, Burst: 4}, []string{"a", "http://b:7001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Assignment{
+		{Agent: "a:7000", Concurrency: 3, MaxRPS: 60, Burst: 2},
+		{Agent: "b:7001", Concurrency: 2, MaxRPS: 40, Burst: 2},
+	}
+	if len(assignments) != len(want) {
+		t.Fatalf("got %d assignments, want %d", len(assignments), len(want))
+	}
+	for i, a := range assignments {
+		if a.Agent != want[i].Agent || a.Concurrency != want[i].Concurrency || a.Burst != want[i].Burst || math.Abs(a.MaxRPS-want[i].MaxRPS) > 1e-9 {
+			t.Errorf("assignment %d = %+v, want %+v", i, a, want[i])
+		}
+	}
+
+	if _, err := Split(runner.Config{Concurrency: 1}, []string{"a", "b"}); err == nil {
+		t.Error("Split with fewer workers than agents succeeded, want an error")
+	}
+	if _, err := Split(runner.Config{Concurrency: 1}, nil); err == nil {
+		t.Error("Split without agents succeeded, want an error")
+	}
+}
+
+func TestListenRequiresTokenOffLoopback(t *testing.T) {
+	if _, err := NewAgent("", nil).Listen(":0"); err == nil {
+		t.Error("listening on every interface without a token succeeded, want an error")
+	}
+
+	agent := NewAgent("", nil)
+	if _, err := agent.Listen("127.0.0.1:0"); err != nil {
+		t.Errorf("listening on loopback without a token: %v", err)
+	}
+	agent.Close()
+
+	for addr, want := range map[string]bool{
+		"127.0.0.1:7000": true,
+		"localhost:7000": true,
+		"[::1]:7000":     true,
+		":7000":          false,
+		"0.0.0.0:7000":   false,
+		"10.0.0.1:7000":  false,
+		"127.0.0.1":      false,
+	} {
+		if got := isLoopback(addr); got != want {
+			t.Errorf("isLoopback(%q) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+// startAgents starts n agents with token on loopback ports and returns their addresses
+func startAgents(t *testing.T, n int, token string) []string {
+	t.Helper()
+	addrs := make([]string, n)
+	for i := range addrs {
+		agent := NewAgent(token, nil)
+		addr, err := agent.Listen("127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { agent.Close() })
+		addrs[i] = addr
+	}
+	return addrs
+}
+
+func TestRunAcrossAgents(t *testing.T) {
+	var served atomic.Int64
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer target.Close()
+
+	config := runner.Config{
+		URLs:        []string{target.URL},
+		Method:      http.MethodGet,
+		Concurrency: 6,
+		Duration:    time.Second,
+		MaxRPS:      150,
+	}
+	assignments, err := Split(config, startAgents(t, 3, "secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Run(config, assignments, "secret", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary := result.Summary
+
+	// The agents' shares of the rate add up to the configured one
+	if got := float64(summary.TotalRequests); math.Abs(got-150) > 15 {
+		t.Errorf("merged %d requests over 1s at 150 req/s across 3 agents", summary.TotalRequests)
+	}
+	if summary.FailedRequests != 0 || summary.StatusCodeCounts[http.StatusNoContent] != summary.TotalRequests {
+		t.Errorf("merged %d requests with %d failures and status counts %v", summary.TotalRequests, summary.FailedRequests, summary.StatusCodeCounts)
+	}
+	if served.Load() < summary.TotalRequests {
+		t.Errorf("target served %d requests, fewer than the %d merged", served.Load(), summary.TotalRequests)
+	}
+}
+
+func TestRunRejectsWrongToken(t *testing.T) {
+	assignments, err := Split(runner.Config{Concurrency: 1}, startAgents(t, 1, "secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Run(runner.Config{URLs: []string{"http://127.0.0.1:1"}, Concurrency: 1, Duration: time.Second}, assignments, "wrong", nil)
+	if err == nil || !strings.Contains(err.Error(), assignments[0].Agent) {
+		t.Errorf("error = %v, want the agent rejecting the token", err)
+	}
+}
+
+func TestAgentStopsWhenControllerDisconnects(t *testing.T) {
+	var served atomic.Int64
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer target.Close()
+
+	agent := NewAgent("", nil)
+	addr, err := agent.Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer agent.Close()
+
+	// A long run, cancelled by the controller once the first results arrive
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ready := make(chan struct{})
+	close(ready)
+	j := job{Config: runner.Config{URLs: []string{target.URL}, Method: http.MethodGet, Concurrency: 2, Duration: time.Minute, MaxRPS: 100}}
+	_, err = runAgent(ctx, &http.Client{}, Assignment{Agent: addr}, "", j, ready, func(*runner.Stats) { cancel() })
+	if err == nil {
+		t.Fatal("run completed, want it cancelled")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		agent.mu.Lock()
+		running := agent.running
+		agent.mu.Unlock()
+		if !running {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("agent is still running 5s after the controller disconnected")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// The target is no longer loaded
+	before := served.Load()
+	time.Sleep(200 * time.Millisecond)
+	if after := served.Load(); after != before {
+		t.Errorf("target served %d more requests after the agent stopped", after-before)
+	}
+}
@@ -0,0 +1,91 @@
+package distributed
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/calummacc/g0/internal/runner"
+)
+
+// TokenEnv names the environment variable holding the shared secret of agents and controller
+const TokenEnv = "G0_AGENT_TOKEN"
+
+// DefaultPort is used for agent addresses without a port
+const DefaultPort = "7000"
+
+// DefaultListen is the default address of an agent; other interfaces require a token
+const DefaultListen = "127.0.0.1:" + DefaultPort
+
+// flushInterval is how often an agent streams its partial results to the controller
+const flushInterval = 500 * time.Millisecond
+
+// job is the request body of POST /run
+type job struct {
+	Config  runner.Config
+	StartIn time.Duration // Delay before starting, so that all agents start at the same moment
+}
+
+// message is one line of the newline-delimited JSON stream returned by POST /run
+type message struct {
+	Stats    *runner.Stats `json:",omitempty"` // Results since the previous message (from Stats.Flush)
+	Done     bool          `json:",omitempty"` // Last message of a completed run
+	Duration time.Duration `json:",omitempty"` // Measured duration of the agent's run (with Done)
+	Error    string        `json:",omitempty"` // The run failed
+}
+
+// Assignment is the share of a load test generated by one agent
+type Assignment struct {
+	Agent       string // host:port
+	Concurrency int
+	MaxRPS      float64 // 0 = no limit
+	Burst       int
+}
+
+// Split divides the concurrency and rate of a load test across agents
+// Workers are spread evenly (the first agents take the remainder) and the rate follows the workers
+func Split(config runner.Config, agents []string) ([]Assignment, error) {
+	if len(agents) == 0 {
+		return nil, fmt.Errorf("at least one agent is required")
+	}
+	if config.Concurrency < len(agents) {
+		return nil, fmt.Errorf("concurrency %d is lower than the number of agents (%d)", config.Concurrency, len(agents))
+	}
+
+	assignments := make([]Assignment, len(agents))
+	for i, agent := range agents {
+		a := Assignment{
+			Agent:       normalizeAddr(agent),
+			Concurrency: config.Concurrency / len(agents),
+		}
+		if i < config.Concurrency%len(agents) {
+			a.Concurrency++
+		}
+		share := float64(a.Concurrency) / float64(config.Concurrency)
+		if config.MaxRPS > 0 {
+			a.MaxRPS = config.MaxRPS * share
+			a.Burst = int(math.Max(1, math.Round(float64(config.Burst)*share)))
+		}
+		assignments[i] = a
+	}
+	return assignments, nil
+}
+
+// agentConfig returns the configuration an agent runs for its assignment
+func agentConfig(config runner.Config, a Assignment) runner.Config {
+	config.Concurrency = a.Concurrency
+	config.MaxRPS = a.MaxRPS
+	config.Burst = a.Burst
+	return config
+}
+
+// normalizeAddr adds the default port to an agent address without one
+func normalizeAddr(agent string) string {
+	agent = strings.TrimSpace(agent)
+	agent = strings.TrimPrefix(agent, "http://")
+	if !strings.Contains(agent, ":") {
+		agent += ":" + DefaultPort
+	}
+	return agent
+}
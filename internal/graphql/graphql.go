@@ -0,0 +1,110 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Operation is a ready-to-send GraphQL request for one operation of a document
+type Operation struct {
+	Name string // Operation name, or the file name for an anonymous operation
+	Type string // query, mutation or subscription
+	Body string // JSON POST body with query, operationName and variables
+}
+
+// request is the JSON body of a GraphQL POST request
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// LoadOperations reads GraphQL documents and builds a request for every operation they define
+// The whole document is sent with each request so that fragments resolve
+func LoadOperations(files []string, variables map[string]interface{}) ([]Operation, error) {
+	var operations []Operation
+	seen := make(map[string]string) // Operation name -> file
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read GraphQL query: %w", err)
+		}
+		document := string(data)
+		defs, err := parseOperations(document)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+
+		for _, def := range defs {
+			name := def.name
+			if name == "" {
+				name = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+			}
+			if other, ok := seen[name]; ok {
+				return nil, fmt.Errorf("operation %s is defined in both %s and %s", name, other, file)
+			}
+			seen[name] = file
+
+			body, err := json.Marshal(request{Query: document, OperationName: def.name, Variables: variables})
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode GraphQL request: %w", err)
+			}
+			operations = append(operations, Operation{Name: name, Type: def.kind, Body: string(body)})
+		}
+	}
+	return operations, nil
+}
+
+// LoadVariables reads a JSON object of variables
+func LoadVariables(file string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read variables: %w", err)
+	}
+	var variables map[string]interface{}
+	if err := json.Unmarshal(data, &variables); err != nil {
+		return nil, fmt.Errorf("invalid variables in %s (expected a JSON object): %w", file, err)
+	}
+	return variables, nil
+}
+
+// Error reports the errors of a GraphQL response
+type Error struct {
+	Messages []string
+}
+
+// Error returns the first message and how many more there were
+func (e *Error) Error() string {
+	msg := "graphql: " + e.Messages[0]
+	if len(e.Messages) > 1 {
+		msg += fmt.Sprintf(" (and %d more)", len(e.Messages)-1)
+	}
+	return msg
+}
+
+// CheckResponse returns an *Error if a response body has a non-empty errors array or is not JSON
+func CheckResponse(body []byte) error {
+	var response struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return &Error{Messages: []string{"response is not JSON"}}
+	}
+	if len(response.Errors) == 0 {
+		return nil
+	}
+
+	messages := make([]string, len(response.Errors))
+	for i, e := range response.Errors {
+		messages[i] = e.Message
+		if messages[i] == "" {
+			messages[i] = "(no message)"
+		}
+	}
+	return &Error{Messages: messages}
+}
@@ -0,0 +1,149 @@
+package graphql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// operationDef is an operation found in a GraphQL document
+type operationDef struct {
+	kind string // query, mutation or subscription
+	name string // Empty for an anonymous operation
+}
+
+// parseOperations finds the operation definitions of an executable GraphQL document
+// Only the top level is inspected; the server validates the rest
+func parseOperations(document string) ([]operationDef, error) {
+	tokens, err := tokenize(document)
+	if err != nil {
+		return nil, err
+	}
+
+	var defs []operationDef
+	anonymous := 0
+	for i := 0; i < len(tokens); {
+		switch tok := tokens[i]; tok {
+		case "query", "mutation", "subscription":
+			def := operationDef{kind: tok}
+			if i+1 < len(tokens) && isName(tokens[i+1]) {
+				def.name = tokens[i+1]
+			} else {
+				anonymous++
+			}
+			defs = append(defs, def)
+		case "fragment":
+			// Sent along with the operations that spread it
+		case "{":
+			// Query shorthand: a bare selection set
+			defs = append(defs, operationDef{kind: "query"})
+			anonymous++
+		default:
+			return nil, fmt.Errorf("unexpected %q at the top level of the document (expected query, mutation, subscription or fragment)", tok)
+		}
+
+		if i, err = skipDefinition(tokens, i); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(defs) == 0 {
+		return nil, fmt.Errorf("no operations defined")
+	}
+	if anonymous > 0 && len(defs) > 1 {
+		return nil, fmt.Errorf("an anonymous operation must be the only operation in its document")
+	}
+	return defs, nil
+}
+
+// skipDefinition returns the index of the token after the definition starting at i,
+// which ends with the selection set closing at the top level
+func skipDefinition(tokens []string, i int) (int, error) {
+	depth := 0
+	for ; i < len(tokens); i++ {
+		switch tokens[i] {
+		case "{", "(", "[":
+			depth++
+		case ")", "]":
+			depth--
+		case "}":
+			depth--
+			if depth == 0 {
+				return i + 1, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unterminated definition at the end of the document")
+}
+
+// tokenize splits a GraphQL document into names and punctuators
+// Whitespace, commas, comments and string values are dropped
+func tokenize(document string) ([]string, error) {
+	document = strings.TrimPrefix(document, "\ufeff")
+	var tokens []string
+	for i := 0; i < len(document); {
+		c := document[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case c == '#':
+			for i < len(document) && document[i] != '\n' && document[i] != '\r' {
+				i++
+			}
+		case strings.HasPrefix(document[i:], `"""`):
+			i += 3
+			for !strings.HasPrefix(document[i:], `"""`) {
+				if i >= len(document) {
+					return nil, fmt.Errorf("unterminated block string")
+				}
+				if strings.HasPrefix(document[i:], `\"""`) {
+					i += 4
+				} else {
+					i++
+				}
+			}
+			i += 3
+		case c == '"':
+			i++
+			for i < len(document) && document[i] != '"' {
+				if document[i] == '\\' {
+					i++
+				} else if document[i] == '\n' {
+					return nil, fmt.Errorf("unterminated string")
+				}
+				i++
+			}
+			if i >= len(document) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			i++
+		case isNameStart(c):
+			start := i
+			for i < len(document) && (isNameStart(document[i]) || isDigit(document[i])) {
+				i++
+			}
+			tokens = append(tokens, document[start:i])
+		case strings.HasPrefix(document[i:], "..."):
+			tokens = append(tokens, "...")
+			i += 3
+		default:
+			tokens = append(tokens, document[i:i+1])
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+// isName reports whether a token is a name rather than a punctuator
+func isName(token string) bool {
+	return token != "" && isNameStart(token[0])
+}
+
+// isNameStart reports whether c may start a GraphQL name
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// isDigit reports whether c is an ASCII digit
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,191 @@
+package grpcload
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// DescriptorSource selects where the method descriptor comes from
+// With neither ProtoSet nor ProtoFiles set, server reflection is used
+type DescriptorSource struct {
+	ProtoSet    string   // FileDescriptorSet, e.g. from protoc --include_imports --descriptor_set_out
+	ProtoFiles  []string // .proto files compiled at startup
+	ImportPaths []string // Import paths for ProtoFiles
+}
+
+// descriptorResolver finds descriptors by full name
+type descriptorResolver interface {
+	FindDescriptorByName(protoreflect.FullName) (protoreflect.Descriptor, error)
+}
+
+// resolveMethod looks up a method given as "pkg.Service/Method" or "pkg.Service.Method"
+func resolveMethod(ctx context.Context, conn *grpc.ClientConn, source DescriptorSource, name string) (protoreflect.MethodDescriptor, error) {
+	service, method, err := splitMethodName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var resolver descriptorResolver
+	switch {
+	case source.ProtoSet != "":
+		resolver, err = loadProtoSet(source.ProtoSet)
+	case len(source.ProtoFiles) > 0:
+		resolver, err = compileProtoFiles(ctx, source.ProtoFiles, source.ImportPaths)
+	default:
+		resolver, err = reflectService(ctx, conn, service)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	descriptor, err := resolver.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, fmt.Errorf("service %s not found: %w", service, err)
+	}
+	serviceDescriptor, ok := descriptor.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a service", service)
+	}
+	methodDescriptor := serviceDescriptor.Methods().ByName(protoreflect.Name(method))
+	if methodDescriptor == nil {
+		return nil, fmt.Errorf("service %s has no method %s", service, method)
+	}
+	return methodDescriptor, nil
+}
+
+// splitMethodName splits "pkg.Service/Method" or "pkg.Service.Method" into service and method
+func splitMethodName(name string) (service, method string, err error) {
+	name = strings.TrimPrefix(strings.TrimSpace(name), "/")
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		service, method = name[:i], name[i+1:]
+	} else if i := strings.LastIndex(name, "."); i >= 0 {
+		service, method = name[:i], name[i+1:]
+	}
+	if service == "" || method == "" {
+		return "", "", fmt.Errorf("invalid method %q (expected package.Service/Method)", name)
+	}
+	return service, method, nil
+}
+
+// loadProtoSet reads a binary FileDescriptorSet
+func loadProtoSet(path string) (*protoregistry.Files, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read protoset: %w", err)
+	}
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse protoset %s: %w", path, err)
+	}
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, fmt.Errorf("invalid protoset %s: %w", path, err)
+	}
+	return files, nil
+}
+
+// compileProtoFiles compiles .proto sources; well-known imports such as google/protobuf/empty.proto are built in
+func compileProtoFiles(ctx context.Context, paths, importPaths []string) (descriptorResolver, error) {
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{ImportPaths: importPaths}),
+	}
+	files, err := compiler.Compile(ctx, paths...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile proto files: %w", err)
+	}
+	return files.AsResolver(), nil
+}
+
+// reflectService fetches the file defining service, and its dependencies, using server reflection
+func reflectService(ctx context.Context, conn *grpc.ClientConn, service string) (*protoregistry.Files, error) {
+	stream, err := rpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, reflectionError(err)
+	}
+	defer stream.CloseSend()
+
+	fetched := make(map[string]*descriptorpb.FileDescriptorProto)
+	var order []string // Fetch order, for a deterministic descriptor set
+
+	request := &rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: service},
+	}
+	var missing []string
+	for {
+		if err := stream.Send(request); err != nil {
+			return nil, reflectionError(err)
+		}
+		response, err := stream.Recv()
+		if err != nil {
+			return nil, reflectionError(err)
+		}
+		if errResponse := response.GetErrorResponse(); errResponse != nil {
+			return nil, fmt.Errorf("server reflection: %s", errResponse.GetErrorMessage())
+		}
+		for _, raw := range response.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			file := &descriptorpb.FileDescriptorProto{}
+			if err := proto.Unmarshal(raw, file); err != nil {
+				return nil, fmt.Errorf("server reflection: invalid file descriptor: %w", err)
+			}
+			if _, ok := fetched[file.GetName()]; !ok {
+				fetched[file.GetName()] = file
+				order = append(order, file.GetName())
+			}
+		}
+
+		// Request dependencies the server did not include
+		missing = missing[:0]
+		for i := 0; i < len(order); i++ { // order grows as well-known files are added
+			for _, dep := range fetched[order[i]].GetDependency() {
+				if _, ok := fetched[dep]; ok {
+					continue
+				}
+				// Well-known types linked into this binary need not be fetched
+				if known, err := protoregistry.GlobalFiles.FindFileByPath(dep); err == nil {
+					fetched[dep] = protodesc.ToFileDescriptorProto(known)
+					order = append(order, dep)
+					continue
+				}
+				missing = append(missing, dep)
+			}
+		}
+		if len(missing) == 0 {
+			break
+		}
+		request = &rpb.ServerReflectionRequest{
+			MessageRequest: &rpb.ServerReflectionRequest_FileByFilename{FileByFilename: missing[0]},
+		}
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, name := range order {
+		set.File = append(set.File, fetched[name])
+	}
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, fmt.Errorf("server reflection: %w", err)
+	}
+	return files, nil
+}
+
+// reflectionError explains a failed reflection call
+func reflectionError(err error) error {
+	if err == io.EOF || status.Code(err) == codes.Unimplemented {
+		return fmt.Errorf("server reflection is not available (use --protoset or --proto): %w", err)
+	}
+	return fmt.Errorf("server reflection: %w", err)
+}
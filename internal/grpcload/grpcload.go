@@ -0,0 +1,283 @@
+package grpcload
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/calummacc/g0/internal/runner"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// Default settings, used for zero-valued Config fields
+const (
+	DefaultCallTimeout = 30 * time.Second
+	resolveTimeout     = 10 * time.Second // Connecting and resolving the method descriptor
+)
+
+// Config holds the configuration for a gRPC load test
+type Config struct {
+	Target      string            // host:port
+	Method      string            // package.Service/Method
+	Data        string            // Request message as JSON (default "{}")
+	Metadata    map[string]string // Sent with every call
+	Descriptors DescriptorSource
+
+	Concurrency int
+	Duration    time.Duration
+	MaxRPS      float64       // Maximum calls per second (0 = no limit)
+	Connections int           // HTTP/2 connections shared by the workers (default 1)
+	CallTimeout time.Duration // Deadline of each call, including the whole stream
+
+	Plaintext bool // Connect without TLS
+	Insecure  bool // Skip TLS certificate verification
+}
+
+// StreamSummary describes the messages of server-streaming calls
+type StreamSummary struct {
+	Messages       int64
+	MessagesPerSec float64
+	PerCall        float64                    // Average messages per call
+	FirstMessage   runner.LatencyDistribution // From sending the request to the first message
+	MessageLatency runner.LatencyDistribution // Since the previous message (or the request, for the first)
+}
+
+// RunResult contains the summary of a gRPC load test
+type RunResult struct {
+	Summary   *runner.Summary // Status codes are gRPC codes (Summary.GRPC is set)
+	Stream    *StreamSummary  // Per-message statistics (nil for unary methods)
+	Method    string          // Full method name, e.g. /pkg.Service/Method
+	Streaming bool
+}
+
+// Run executes a gRPC load test and optionally sends the stats instance to statsChan when created
+func Run(config Config, statsChan chan<- *runner.Stats) (*RunResult, error) {
+	if config.Concurrency <= 0 {
+		return nil, fmt.Errorf("concurrency must be greater than 0")
+	}
+	if config.Connections <= 0 {
+		config.Connections = 1
+	}
+	if config.CallTimeout <= 0 {
+		config.CallTimeout = DefaultCallTimeout
+	}
+	if config.Data == "" {
+		config.Data = "{}"
+	}
+
+	creds := insecure.NewCredentials()
+	if !config.Plaintext {
+		creds = credentials.NewTLS(&tls.Config{InsecureSkipVerify: config.Insecure})
+	}
+	conns := make([]*grpc.ClientConn, config.Connections)
+	for i := range conns {
+		conn, err := grpc.NewClient(config.Target, grpc.WithTransportCredentials(creds))
+		if err != nil {
+			return nil, fmt.Errorf("invalid target %q: %w", config.Target, err)
+		}
+		defer conn.Close()
+		conns[i] = conn
+	}
+
+	resolveCtx, cancelResolve := context.WithTimeout(context.Background(), resolveTimeout)
+	method, err := resolveMethod(resolveCtx, conns[0], config.Descriptors, config.Method)
+	cancelResolve()
+	if err != nil {
+		return nil, err
+	}
+	if method.IsStreamingClient() {
+		return nil, fmt.Errorf("%s is a client or bidirectional streaming method; only unary and server-streaming methods are supported", method.FullName())
+	}
+
+	request := dynamicpb.NewMessage(method.Input())
+	if err := protojson.Unmarshal([]byte(config.Data), request); err != nil {
+		return nil, fmt.Errorf("invalid request data for %s: %w", method.Input().FullName(), err)
+	}
+
+	fullMethod := fmt.Sprintf("/%s/%s", method.Parent().FullName(), method.Name())
+	caller := &caller{
+		method:     method,
+		fullMethod: fullMethod,
+		request:    request,
+		timeout:    config.CallTimeout,
+		metadata:   metadata.New(config.Metadata),
+		stream:     &streamStats{},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.Duration)
+	defer cancel()
+
+	stats := runner.NewStats()
+	stats.GRPC = true
+	stats.TargetRate = config.MaxRPS
+	if statsChan != nil {
+		select {
+		case statsChan <- stats:
+		default:
+		}
+	}
+
+	var rateLimiter *runner.RateLimiter
+	if config.MaxRPS > 0 {
+		rateLimiter = runner.NewRateLimiter(config.MaxRPS, 1, runner.Arrival{})
+		defer rateLimiter.Stop()
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < config.Concurrency; i++ {
+		conn := conns[i%len(conns)]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			caller.work(ctx, conn, rateLimiter, stats)
+		}()
+	}
+
+	<-ctx.Done()
+	wg.Wait()
+
+	stats.Finalize()
+	summary := stats.GetSummary()
+
+	result := &RunResult{
+		Summary:   &summary,
+		Method:    fullMethod,
+		Streaming: method.IsStreamingServer(),
+	}
+	if result.Streaming {
+		result.Stream = caller.stream.summary(summary.Duration, summary.TotalRequests)
+	}
+	return result, nil
+}
+
+// caller sends calls to one method
+type caller struct {
+	method     protoreflect.MethodDescriptor
+	fullMethod string
+	request    *dynamicpb.Message // Shared by all workers; only read when marshalling
+	timeout    time.Duration
+	metadata   metadata.MD
+	stream     *streamStats
+}
+
+// work sends calls on conn in a loop until ctx is cancelled
+func (c *caller) work(ctx context.Context, conn *grpc.ClientConn, rateLimiter *runner.RateLimiter, stats *runner.Stats) {
+	stats.WorkerStarted()
+	defer stats.WorkerStopped()
+
+	for ctx.Err() == nil {
+		intended, ok := rateLimiter.Wait(ctx)
+		if !ok {
+			return
+		}
+
+		stats.RequestStarted()
+		sent := time.Now()
+		messages, err := c.call(ctx, conn, sent)
+		latency := time.Since(sent)
+		stats.RequestFinished()
+
+		// Calls cut short by the end of the run are not results
+		if ctx.Err() != nil {
+			return
+		}
+		if len(messages) > 0 {
+			c.stream.add(messages)
+		}
+
+		var responseTime time.Duration
+		if !intended.IsZero() {
+			responseTime = latency
+			if intended.Before(sent) {
+				responseTime += sent.Sub(intended)
+			}
+		}
+		stats.AddResult(runner.Result{
+			URL:          c.fullMethod,
+			Latency:      latency,
+			StatusCode:   int(status.Code(err)),
+			Error:        err,
+			ResponseTime: responseTime,
+			SentAt:       sent,
+		})
+	}
+}
+
+// call sends one unary call, or one server-streaming call read to the end
+// Returns the latency of each stream message, since the previous message or the request
+func (c *caller) call(ctx context.Context, conn *grpc.ClientConn, sent time.Time) ([]time.Duration, error) {
+	ctx, cancel := context.WithTimeout(metadata.NewOutgoingContext(ctx, c.metadata), c.timeout)
+	defer cancel()
+
+	if !c.method.IsStreamingServer() {
+		return nil, conn.Invoke(ctx, c.fullMethod, c.request, dynamicpb.NewMessage(c.method.Output()))
+	}
+
+	stream, err := conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, c.fullMethod)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.SendMsg(c.request); err != nil {
+		return nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+
+	var messages []time.Duration
+	previous := sent
+	for {
+		if err := stream.RecvMsg(dynamicpb.NewMessage(c.method.Output())); err != nil {
+			if err == io.EOF {
+				return messages, nil
+			}
+			return messages, err
+		}
+		now := time.Now()
+		messages = append(messages, now.Sub(previous))
+		previous = now
+	}
+}
+
+// streamStats collects per-message latencies of server-streaming calls
+type streamStats struct {
+	mu             sync.Mutex
+	firstMessage   []time.Duration
+	messageLatency []time.Duration
+}
+
+// add records the message latencies of one call
+func (s *streamStats) add(messages []time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.firstMessage = append(s.firstMessage, messages[0])
+	s.messageLatency = append(s.messageLatency, messages...)
+}
+
+// summary summarises the stream messages of a run
+func (s *streamStats) summary(duration time.Duration, calls int64) *StreamSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	summary := &StreamSummary{
+		Messages:       int64(len(s.messageLatency)),
+		FirstMessage:   runner.Distribution(s.firstMessage),
+		MessageLatency: runner.Distribution(s.messageLatency),
+	}
+	if duration > 0 {
+		summary.MessagesPerSec = float64(summary.Messages) / duration.Seconds()
+	}
+	if calls > 0 {
+		summary.PerCall = float64(summary.Messages) / float64(calls)
+	}
+	return summary
+}
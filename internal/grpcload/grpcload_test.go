@@ -0,0 +1,167 @@
+package grpcload
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/calummacc/g0/internal/runner"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// startHealthServer serves the gRPC health service, with reflection, on a loopback port
+// The service "ok" is serving; any other service is NOT_FOUND
+// The health server is returned to change the status reported by Health/Watch
+func startHealthServer(t *testing.T) (string, *health.Server) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("ok", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+	reflection.Register(server)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	return lis.Addr().String(), healthServer
+}
+
+// runHealthCheck calls Health/Check for service for a short while
+func runHealthCheck(t *testing.T, service string) *RunResult {
+	t.Helper()
+	target, _ := startHealthServer(t)
+	result, err := Run(Config{
+		Target:      target,
+		Method:      "grpc.health.v1.Health/Check",
+		Data:        `{"service": "` + service + `"}`,
+		Concurrency: 2,
+		Duration:    200 * time.Millisecond,
+		Plaintext:   true,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Summary.TotalRequests == 0 {
+		t.Fatal("no calls were made")
+	}
+	return result
+}
+
+func TestRunCountsOKAsSuccess(t *testing.T) {
+	result := runHealthCheck(t, "ok")
+	summary := result.Summary
+
+	if !summary.GRPC {
+		t.Error("summary is not marked as gRPC")
+	}
+	if summary.FailedRequests != 0 || summary.SuccessRequests != summary.TotalRequests {
+		t.Errorf("%d of %d calls failed, want none", summary.FailedRequests, summary.TotalRequests)
+	}
+	if got := summary.StatusCodeCounts[int(codes.OK)]; got != summary.TotalRequests {
+		t.Errorf("status OK counted %d times, want %d", got, summary.TotalRequests)
+	}
+	if len(summary.Endpoints) != 1 {
+		t.Fatalf("got %d endpoints, want 1", len(summary.Endpoints))
+	}
+	e := summary.Endpoints[0]
+	if e.URL != "/grpc.health.v1.Health/Check" {
+		t.Errorf("endpoint = %q, want the full method name", e.URL)
+	}
+	if e.FailedRequests != 0 || e.SuccessRequests != summary.TotalRequests {
+		t.Errorf("endpoint counts %d successful and %d failed calls, want %d and 0", e.SuccessRequests, e.FailedRequests, summary.TotalRequests)
+	}
+}
+
+func TestRunCountsErrorStatusAsFailure(t *testing.T) {
+	result := runHealthCheck(t, "missing")
+	summary := result.Summary
+
+	if summary.SuccessRequests != 0 || summary.FailedRequests != summary.TotalRequests {
+		t.Errorf("%d of %d calls succeeded, want none", summary.SuccessRequests, summary.TotalRequests)
+	}
+	if got := summary.StatusCodeCounts[int(codes.NotFound)]; got != summary.TotalRequests {
+		t.Errorf("status NOT_FOUND counted %d times, want %d", got, summary.TotalRequests)
+	}
+	if e := summary.Endpoints[0]; e.FailedRequests != summary.TotalRequests {
+		t.Errorf("endpoint counts %d failed calls, want %d", e.FailedRequests, summary.TotalRequests)
+	}
+	if len(summary.Errors) != 1 || summary.Errors[0].Class != runner.ErrorClassGRPC || summary.Errors[0].Count != summary.TotalRequests {
+		t.Errorf("errors = %+v, want every call in the %s class", summary.Errors, runner.ErrorClassGRPC)
+	}
+}
+
+func TestRunStreamsMessages(t *testing.T) {
+	target, healthServer := startHealthServer(t)
+
+	// Watch sends the current status, then one message per change until the call times out
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		serving := true
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				serving = !serving
+				status := healthpb.HealthCheckResponse_NOT_SERVING
+				if serving {
+					status = healthpb.HealthCheckResponse_SERVING
+				}
+				healthServer.SetServingStatus("ok", status)
+			}
+		}
+	}()
+
+	const callTimeout = 100 * time.Millisecond
+	result, err := Run(Config{
+		Target:      target,
+		Method:      "grpc.health.v1.Health/Watch",
+		Data:        `{"service": "ok"}`,
+		Concurrency: 2,
+		Duration:    500 * time.Millisecond,
+		CallTimeout: callTimeout,
+		Plaintext:   true,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary := result.Summary
+
+	if !result.Streaming || result.Stream == nil {
+		t.Fatalf("Watch was not run as a server-streaming method")
+	}
+	if summary.TotalRequests == 0 {
+		t.Fatal("no calls were made")
+	}
+	// Every call ends at its deadline, as the stream is never closed by the server
+	if got := summary.StatusCodeCounts[int(codes.DeadlineExceeded)]; got != summary.TotalRequests {
+		t.Errorf("DEADLINE_EXCEEDED counted %d times, want %d", got, summary.TotalRequests)
+	}
+	if len(summary.Errors) != 1 || summary.Errors[0].Class != runner.ErrorClassTimeout {
+		t.Errorf("errors = %+v, want the timeout class", summary.Errors)
+	}
+
+	s := result.Stream
+	if s.PerCall < 3 || s.Messages < 3*summary.TotalRequests {
+		t.Errorf("%d messages over %d calls (%.1f per call), want several per call", s.Messages, summary.TotalRequests, s.PerCall)
+	}
+	if s.MessagesPerSec <= 0 {
+		t.Errorf("MessagesPerSec = %f", s.MessagesPerSec)
+	}
+	if s.FirstMessage.Max <= 0 || s.FirstMessage.Max >= callTimeout {
+		t.Errorf("first message after at most %s, want within the %s call", s.FirstMessage.Max, callTimeout)
+	}
+	// Messages follow the status changes, so they are further apart than the first one
+	if s.MessageLatency.Max < 5*time.Millisecond || s.MessageLatency.Max >= callTimeout {
+		t.Errorf("message latencies up to %s, want about the 10ms between changes", s.MessageLatency.Max)
+	}
+}
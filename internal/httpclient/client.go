@@ -3,33 +3,245 @@ package httpclient
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
 // Client wraps http.Client with keep-alive enabled
 type Client struct {
-	httpClient *http.Client
+	httpClient      *http.Client
+	traceSampleRate float64
+	stream          bool
+}
+
+// Options configures optional client behaviour
+type Options struct {
+	TraceSampleRate float64 // Fraction of requests (0-1) that carry a W3C traceparent header (0 = disabled)
+	Transport       TransportOptions
+	Stream          bool // Read response bodies incrementally and time their events (Response.Stream)
+
+	// ConnClosed is called with the lifetime of every connection when it is closed (optional)
+	ConnClosed func(lifetime time.Duration)
+
+	// QUICHandshake is called for every completed QUIC handshake with HTTP/3 (optional)
+	QUICHandshake func(QUICHandshake)
+}
+
+// Default transport settings, used for zero-valued TransportOptions fields
+const (
+	DefaultTimeout             = 30 * time.Second
+	DefaultConnectTimeout      = 10 * time.Second
+	DefaultTLSHandshakeTimeout = 10 * time.Second
+	DefaultMaxIdleConnsPerHost = 10
+	DefaultMaxRedirects        = 10
+)
+
+// TransportOptions configures timeouts, connection pooling and redirects
+// Zero values select the defaults above
+type TransportOptions struct {
+	Timeout             time.Duration // Whole request, including redirects
+	ConnectTimeout      time.Duration // TCP connect
+	TLSHandshakeTimeout time.Duration
+	MaxIdleConnsPerHost int  // Idle connections kept for reuse per host
+	MaxConnsPerHost     int  // Total connections per host (0 = unlimited)
+	DisableKeepAlives   bool // Open a new connection for every request
+	NoRedirects         bool // Report 3xx responses instead of following them
+	MaxRedirects        int  // Redirect hops followed before failing
+	TLS                 TLSOptions
+
+	Protocol     string // HTTP version: ProtocolAuto, ProtocolHTTP1, ProtocolHTTP2, ProtocolH2C or ProtocolHTTP3
+	H2Conns      int    // HTTP/2 connections per host for ProtocolHTTP2 and ProtocolH2C (0 = 1)
+	H2MaxStreams int    // Concurrent streams per HTTP/2 connection (0 = server limit)
+}
+
+// WithDefaults returns the options with defaults filled in for zero values
+func (o TransportOptions) WithDefaults() TransportOptions {
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultTimeout
+	}
+	if o.ConnectTimeout <= 0 {
+		o.ConnectTimeout = DefaultConnectTimeout
+	}
+	if o.TLSHandshakeTimeout <= 0 {
+		o.TLSHandshakeTimeout = DefaultTLSHandshakeTimeout
+	}
+	if o.MaxIdleConnsPerHost <= 0 {
+		o.MaxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	}
+	if o.MaxRedirects <= 0 {
+		o.MaxRedirects = DefaultMaxRedirects
+	}
+	if o.H2Conns <= 0 {
+		o.H2Conns = 1
+	}
+	return o
+}
+
+// ParseRedirectPolicy parses "follow", "none" or a maximum number of hops into the
+// NoRedirects and MaxRedirects options
+func ParseRedirectPolicy(policy string) (noRedirects bool, maxRedirects int, err error) {
+	switch p := strings.ToLower(strings.TrimSpace(policy)); p {
+	case "", "follow":
+		return false, DefaultMaxRedirects, nil
+	case "none", "no-follow":
+		return true, 0, nil
+	default:
+		hops, err := strconv.Atoi(p)
+		if err != nil || hops < 0 {
+			return false, 0, fmt.Errorf("invalid redirect policy %q (expected follow, none or a number of hops)", policy)
+		}
+		if hops == 0 {
+			return true, 0, nil
+		}
+		return false, hops, nil
+	}
 }
 
 // New creates a new HTTP client with keep-alive enabled
-func New() *Client {
+// Returns an error if the TLS options cannot be loaded
+func New(opts Options) (*Client, error) {
+	t := opts.Transport.WithDefaults()
+
+	tlsConfig, err := t.TLS.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   t.ConnectTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+	dial := dialer.DialContext
+	if opts.ConnClosed != nil {
+		dial = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := dialer.DialContext(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			return &trackedConn{Conn: conn, opened: time.Now(), onClose: opts.ConnClosed}, nil
+		}
+	}
+
 	transport := &http.Transport{
-		MaxIdleConns:        100,
-		MaxIdleConnsPerHost: 10,
+		DialContext:         dial,
+		MaxIdleConns:        0, // No global limit; MaxIdleConnsPerHost bounds the pool
+		MaxIdleConnsPerHost: t.MaxIdleConnsPerHost,
+		MaxConnsPerHost:     t.MaxConnsPerHost,
 		IdleConnTimeout:     90 * time.Second,
-		DisableKeepAlives:   false,
+		TLSHandshakeTimeout: t.TLSHandshakeTimeout,
+		TLSClientConfig:     tlsConfig,
+		DisableKeepAlives:   t.DisableKeepAlives,
+	}
+
+	var roundTripper http.RoundTripper = transport
+	switch t.Protocol {
+	case ProtocolAuto:
+		// A custom dialer and TLS config otherwise turn off HTTP/2 negotiation
+		transport.ForceAttemptHTTP2 = true
+	case ProtocolHTTP1:
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	case ProtocolHTTP2:
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		}
+		roundTripper = newH2Pool(dial, tlsConfig, t.TLSHandshakeTimeout, t.H2Conns, t.H2MaxStreams)
+	case ProtocolH2C:
+		roundTripper = newH2Pool(dial, nil, t.TLSHandshakeTimeout, t.H2Conns, t.H2MaxStreams)
+	case ProtocolHTTP3:
+		roundTripper = newH3Transport(tlsConfig, t, opts.QUICHandshake, opts.ConnClosed)
+	default:
+		return nil, fmt.Errorf("unknown HTTP protocol %q", t.Protocol)
 	}
 
 	return &Client{
 		httpClient: &http.Client{
-			Transport: transport,
-			Timeout:   30 * time.Second,
+			Transport:     roundTripper,
+			Timeout:       t.Timeout,
+			CheckRedirect: redirectPolicy(t.NoRedirects, t.MaxRedirects),
+		},
+		traceSampleRate: opts.TraceSampleRate,
+		stream:          opts.Stream,
+	}, nil
+}
+
+// connTracker collects connection details from httptrace hooks
+// Hooks may run on the transport's dial goroutine, so access is synchronised
+type connTracker struct {
+	mu   sync.Mutex
+	info ConnInfo
+}
+
+// trace returns the client trace hooks that fill in the connection details
+func (t *connTracker) trace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if t.info.Acquired {
+				return
+			}
+			t.info.Acquired = true
+			t.info.Reused = info.Reused
+			if info.WasIdle {
+				t.info.IdleTime = info.IdleTime
+			}
+		},
+		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if err == nil && t.info.TLSVersion == 0 {
+				t.info.TLSVersion = state.Version
+				t.info.TLSCipherSuite = state.CipherSuite
+			}
 		},
 	}
 }
 
+// get returns the collected connection details
+func (t *connTracker) get() ConnInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.info
+}
+
+// trackedConn reports its lifetime when closed
+type trackedConn struct {
+	net.Conn
+	opened  time.Time
+	onClose func(lifetime time.Duration)
+	once    sync.Once
+}
+
+// Close closes the connection and reports its lifetime once
+func (c *trackedConn) Close() error {
+	c.once.Do(func() {
+		c.onClose(time.Since(c.opened))
+	})
+	return c.Conn.Close()
+}
+
+// redirectPolicy returns a CheckRedirect function that stops at the first 3xx response
+// (reporting it as the result) or fails after maxRedirects hops
+func redirectPolicy(noRedirects bool, maxRedirects int) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if noRedirects {
+			return http.ErrUseLastResponse
+		}
+		if len(via) >= maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		return nil
+	}
+}
+
 // Request represents an HTTP request configuration
 type Request struct {
 	Method  string
@@ -37,6 +249,11 @@ type Request struct {
 	Body    string
 	Headers map[string]string
 	Context context.Context // Context for request cancellation
+
+	// CheckBody inspects the body of 2xx responses; an error fails the request (optional)
+	CheckBody func(body []byte) error
+
+	KeepBody bool // Return the response body in Response.Body (ignored when streaming)
 }
 
 // Response represents the result of an HTTP request
@@ -44,6 +261,23 @@ type Response struct {
 	StatusCode int
 	Latency    time.Duration
 	Error      error
+	TraceID    string        // W3C trace ID if the request was sampled for tracing
+	RetryAfter time.Duration // Delay requested by a 429/503 Retry-After header (0 if absent)
+	Conn       ConnInfo      // Connection used for the request
+	Protocol   string        // Negotiated protocol, e.g. "HTTP/1.1" or "HTTP/2.0" (empty on error)
+	Stream     *StreamInfo   // Events of the response body (nil unless streaming)
+	Header     http.Header   // Response headers (nil if no response was received)
+	Body       []byte        // Response body (nil unless Request.KeepBody was set)
+}
+
+// ConnInfo describes the connection a request was sent on (the first hop if redirected)
+type ConnInfo struct {
+	Acquired bool          // False if the request failed before getting a connection
+	Reused   bool          // The connection had carried a previous request
+	IdleTime time.Duration // How long a reused connection was idle before this request
+
+	TLSVersion     uint16 // Negotiated in a TLS handshake for this request (0 if none)
+	TLSCipherSuite uint16
 }
 
 // Do performs an HTTP request and returns the response
@@ -76,6 +310,19 @@ func (c *Client) Do(req Request) Response {
 		httpReq.Header.Set(key, value)
 	}
 
+	// Inject trace context for sampled requests, unless the caller set its own
+	var traceID string
+	if shouldSample(c.traceSampleRate) && httpReq.Header.Get(traceparentHeader) == "" {
+		if traceparent, id, err := newTraceparent(); err == nil {
+			httpReq.Header.Set(traceparentHeader, traceparent)
+			traceID = id
+		}
+	}
+
+	// Record whether the request got a new or reused connection
+	conn := &connTracker{}
+	httpReq = httpReq.WithContext(httptrace.WithClientTrace(httpReq.Context(), conn.trace()))
+
 	// Perform the request
 	resp, err := c.httpClient.Do(httpReq)
 	latency := time.Since(start)
@@ -85,14 +332,94 @@ func (c *Client) Do(req Request) Response {
 			StatusCode: 0,
 			Latency:    latency,
 			Error:      err,
+			TraceID:    traceID,
+			Conn:       conn.get(),
 		}
 	}
 	defer resp.Body.Close()
 
+	// In streaming mode, time the events of the body; a body cut off mid-stream is a network error
+	// Otherwise drain the body so the connection can be reused for the next request
+	var stream *StreamInfo
+	var kept []byte
+	if c.stream {
+		info, err := readStream(resp.Body, isEventStream(resp.Header.Get("Content-Type")), start)
+		if err != nil {
+			return Response{
+				StatusCode: 0,
+				Latency:    latency,
+				Error:      err,
+				TraceID:    traceID,
+				Conn:       conn.get(),
+			}
+		}
+		stream = &info
+	} else if checkBody := req.CheckBody != nil && resp.StatusCode >= 200 && resp.StatusCode < 300; checkBody || req.KeepBody {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return Response{
+				StatusCode: 0,
+				Latency:    latency,
+				Error:      err,
+				TraceID:    traceID,
+				Conn:       conn.get(),
+			}
+		}
+		if req.KeepBody {
+			kept = body
+		}
+		// The response arrived, so it keeps its status code
+		if checkBody {
+			if err := req.CheckBody(body); err != nil {
+				return Response{
+					StatusCode: resp.StatusCode,
+					Latency:    latency,
+					Error:      err,
+					TraceID:    traceID,
+					Conn:       conn.get(),
+					Protocol:   resp.Proto,
+					Header:     resp.Header,
+					Body:       kept,
+				}
+			}
+		}
+	} else {
+		io.Copy(io.Discard, resp.Body)
+	}
+
+	var retryAfter time.Duration
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	}
+
 	return Response{
 		StatusCode: resp.StatusCode,
 		Latency:    latency,
 		Error:      nil,
+		TraceID:    traceID,
+		RetryAfter: retryAfter,
+		Conn:       conn.get(),
+		Protocol:   resp.Proto,
+		Stream:     stream,
+		Header:     resp.Header,
+		Body:       kept,
 	}
 }
 
+// parseRetryAfter parses a Retry-After value given in seconds or as an HTTP date
+// Returns 0 if the header is missing, invalid or in the past
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
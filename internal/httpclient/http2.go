@@ -0,0 +1,266 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+// Protocol selections for TransportOptions.Protocol
+const (
+	ProtocolAuto  = ""    // HTTP/2 over TLS when the server offers it, otherwise HTTP/1.1
+	ProtocolHTTP1 = "1.1" // HTTP/1.1 only
+	ProtocolHTTP2 = "2"   // HTTP/2 over TLS (h2), failing if the server does not negotiate it
+	ProtocolH2C   = "h2c" // HTTP/2 over cleartext TCP with prior knowledge
+	ProtocolHTTP3 = "3"   // HTTP/3 over QUIC
+)
+
+// ParseProtocol parses an --http value: auto, 1.1, 2, h2c or 3
+func ParseProtocol(protocol string) (string, error) {
+	switch p := strings.ToLower(strings.TrimSpace(protocol)); p {
+	case "", "auto":
+		return ProtocolAuto, nil
+	case "1.1", "http/1.1":
+		return ProtocolHTTP1, nil
+	case "2", "h2", "http/2":
+		return ProtocolHTTP2, nil
+	case "h2c":
+		return ProtocolH2C, nil
+	case "3", "h3", "http/3":
+		return ProtocolHTTP3, nil
+	default:
+		return "", fmt.Errorf("unknown HTTP protocol %q (supported: auto, 1.1, 2, h2c, 3)", protocol)
+	}
+}
+
+// dialFunc opens a network connection
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// h2Pool is an HTTP/2 round tripper that spreads requests over a fixed number of
+// connections per host, optionally capping the concurrent streams on each
+type h2Pool struct {
+	transport        *http2.Transport
+	dial             dialFunc
+	tlsConfig        *tls.Config // nil for h2c
+	handshakeTimeout time.Duration
+	conns            int // Connections per host
+	maxStreams       int // Concurrent streams per connection (0 = server limit)
+
+	mu    sync.Mutex
+	hosts map[string]*h2Host
+}
+
+// h2Host holds the connections to one host
+// Connections are dialed outside of mu, so a slow handshake does not hold up requests on
+// the open connections; dialing counts the dials in progress against the pool size
+type h2Host struct {
+	mu      sync.Mutex
+	dialed  *sync.Cond // Signalled when a dial finishes
+	conns   []*h2Conn
+	dialing int
+	next    int
+}
+
+// h2Conn is one HTTP/2 connection and its stream slots
+type h2Conn struct {
+	cc      *http2.ClientConn
+	netConn net.Conn
+	streams chan struct{} // nil if only limited by the server
+	used    bool          // Guarded by h2Host.mu
+}
+
+// newH2Pool creates an HTTP/2 round tripper; h2c is used if tlsConfig is nil
+func newH2Pool(dial dialFunc, tlsConfig *tls.Config, handshakeTimeout time.Duration, conns, maxStreams int) *h2Pool {
+	if conns < 1 {
+		conns = 1
+	}
+	return &h2Pool{
+		transport:        &http2.Transport{AllowHTTP: tlsConfig == nil},
+		dial:             dial,
+		tlsConfig:        tlsConfig,
+		handshakeTimeout: handshakeTimeout,
+		conns:            conns,
+		maxStreams:       maxStreams,
+		hosts:            make(map[string]*h2Host),
+	}
+}
+
+// RoundTrip sends the request on the next connection to its host
+func (p *h2Pool) RoundTrip(req *http.Request) (*http.Response, error) {
+	if p.tlsConfig == nil && req.URL.Scheme != "http" {
+		return nil, fmt.Errorf("h2c requires http:// URLs")
+	}
+	if p.tlsConfig != nil && req.URL.Scheme != "https" {
+		return nil, fmt.Errorf("HTTP/2 over TLS requires https:// URLs (use h2c for http://)")
+	}
+
+	conn, reused, err := p.getConn(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if conn.streams != nil {
+		select {
+		case conn.streams <- struct{}{}:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+	release := func() {
+		if conn.streams != nil {
+			<-conn.streams
+		}
+	}
+
+	if trace := httptrace.ContextClientTrace(req.Context()); trace != nil && trace.GotConn != nil {
+		trace.GotConn(httptrace.GotConnInfo{Conn: conn.netConn, Reused: reused})
+	}
+
+	resp, err := conn.cc.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	// The stream stays open until the body is closed
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// getConn returns a connection to the request's host, dialing until the pool is full
+// and then rotating through the open connections
+func (p *h2Pool) getConn(req *http.Request) (conn *h2Conn, reused bool, err error) {
+	addr := hostPort(req)
+
+	p.mu.Lock()
+	host, ok := p.hosts[addr]
+	if !ok {
+		host = &h2Host{}
+		host.dialed = sync.NewCond(&host.mu)
+		p.hosts[addr] = host
+	}
+	p.mu.Unlock()
+
+	host.mu.Lock()
+	defer host.mu.Unlock()
+
+	for {
+		// Drop connections that were closed or are shutting down (e.g. after GOAWAY)
+		open := host.conns[:0]
+		for _, c := range host.conns {
+			if state := c.cc.State(); !state.Closed && !state.Closing {
+				open = append(open, c)
+			}
+		}
+		host.conns = open
+
+		if len(host.conns)+host.dialing < p.conns {
+			host.dialing++
+			host.mu.Unlock()
+			conn, err := p.dialConn(req, addr)
+			host.mu.Lock()
+			host.dialing--
+			host.dialed.Broadcast()
+			if err != nil {
+				return nil, false, err
+			}
+			conn.used = true
+			host.conns = append(host.conns, conn)
+			return conn, false, nil
+		}
+
+		if len(host.conns) > 0 {
+			conn = host.conns[host.next%len(host.conns)]
+			host.next++
+			reused = conn.used
+			conn.used = true
+			return conn, reused, nil
+		}
+
+		// Every connection is still being dialed (bounded by the connect and handshake timeouts)
+		host.dialed.Wait()
+	}
+}
+
+// dialConn opens a new HTTP/2 connection, negotiating h2 over TLS unless using h2c
+func (p *h2Pool) dialConn(req *http.Request, addr string) (*h2Conn, error) {
+	ctx := req.Context()
+	netConn, err := p.dial(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.tlsConfig != nil {
+		config := p.tlsConfig.Clone()
+		config.NextProtos = []string{http2.NextProtoTLS}
+		if config.ServerName == "" {
+			config.ServerName = req.URL.Hostname()
+		}
+
+		trace := httptrace.ContextClientTrace(ctx)
+		if trace != nil && trace.TLSHandshakeStart != nil {
+			trace.TLSHandshakeStart()
+		}
+		handshakeCtx, cancel := context.WithTimeout(ctx, p.handshakeTimeout)
+		tlsConn := tls.Client(netConn, config)
+		err := tlsConn.HandshakeContext(handshakeCtx)
+		cancel()
+		if trace != nil && trace.TLSHandshakeDone != nil {
+			trace.TLSHandshakeDone(tlsConn.ConnectionState(), err)
+		}
+		if err != nil {
+			netConn.Close()
+			return nil, err
+		}
+		if proto := tlsConn.ConnectionState().NegotiatedProtocol; proto != http2.NextProtoTLS {
+			tlsConn.Close()
+			return nil, fmt.Errorf("server did not negotiate HTTP/2 (ALPN %q)", proto)
+		}
+		netConn = tlsConn
+	}
+
+	cc, err := p.transport.NewClientConn(netConn)
+	if err != nil {
+		netConn.Close()
+		return nil, err
+	}
+
+	conn := &h2Conn{cc: cc, netConn: netConn}
+	if p.maxStreams > 0 {
+		conn.streams = make(chan struct{}, p.maxStreams)
+	}
+	return conn, nil
+}
+
+// hostPort returns the host:port a request is sent to
+func hostPort(req *http.Request) string {
+	if port := req.URL.Port(); port != "" {
+		return req.URL.Host
+	}
+	if req.URL.Scheme == "https" {
+		return net.JoinHostPort(req.URL.Hostname(), "443")
+	}
+	return net.JoinHostPort(req.URL.Hostname(), "80")
+}
+
+// releaseBody frees a stream slot when the response body is closed
+type releaseBody struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+// Close closes the body and releases the stream slot once
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
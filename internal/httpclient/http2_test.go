@@ -0,0 +1,103 @@
+package httpclient
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// newH2CServer starts a cleartext HTTP/2 server
+func newH2CServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}), &http2.Server{}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestH2PoolSpreadsRequests(t *testing.T) {
+	server := newH2CServer(t)
+	var dials atomic.Int32
+	dialer := &net.Dialer{}
+	pool := newH2Pool(func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dials.Add(1)
+		return dialer.DialContext(ctx, network, addr)
+	}, nil, time.Second, 3, 0)
+	client := &http.Client{Transport: pool}
+
+	for i := 0; i < 9; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.ProtoMajor != 2 {
+			t.Fatalf("response protocol %s, want HTTP/2", resp.Proto)
+		}
+	}
+	if got := dials.Load(); got != 3 {
+		t.Errorf("dialed %d connections for 9 requests, want 3", got)
+	}
+}
+
+func TestH2PoolDialsOutsideTheLock(t *testing.T) {
+	server := newH2CServer(t)
+	stalled := make(chan struct{})
+	var dials atomic.Int32
+	dialer := &net.Dialer{}
+	pool := newH2Pool(func(ctx context.Context, network, addr string) (net.Conn, error) {
+		// The first connection hangs, like a slow handshake
+		if dials.Add(1) == 1 {
+			select {
+			case <-stalled:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		return dialer.DialContext(ctx, network, addr)
+	}, nil, time.Second, 2, 0)
+	client := &http.Client{Transport: pool}
+
+	first := make(chan error, 1)
+	go func() {
+		resp, err := client.Get(server.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		first <- err
+	}()
+	for dials.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// The second connection is dialed and used while the first is still stalled
+	done := make(chan error, 1)
+	go func() {
+		resp, err := client.Get(server.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("request waited for another connection's dial")
+	}
+
+	close(stalled)
+	if err := <-first; err != nil {
+		t.Fatal(err)
+	}
+}
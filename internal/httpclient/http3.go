@@ -0,0 +1,116 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+)
+
+// QUICHandshake describes a completed QUIC handshake
+type QUICHandshake struct {
+	Duration time.Duration // From dialing until the handshake completed
+	Resumed  bool          // The TLS session was resumed from an earlier connection
+	Used0RTT bool          // Early data was accepted by the server
+}
+
+// h3Transport sends requests over HTTP/3, either on one shared QUIC connection per host
+// or, with keep-alive disabled, on a new connection per request
+type h3Transport struct {
+	shared     *http3.RoundTripper
+	tlsConfig  *tls.Config
+	quicConfig *quic.Config
+	perRequest bool
+
+	handshake func(QUICHandshake)          // Optional
+	closed    func(lifetime time.Duration) // Optional
+}
+
+// newH3Transport creates an HTTP/3 round tripper
+// TLS sessions are cached so that new connections can resume them and send GET requests as 0-RTT early data
+func newH3Transport(tlsConfig *tls.Config, t TransportOptions, handshake func(QUICHandshake), closed func(time.Duration)) *h3Transport {
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	} else {
+		tlsConfig = tlsConfig.Clone()
+	}
+	tlsConfig.ClientSessionCache = tls.NewLRUClientSessionCache(0)
+
+	h := &h3Transport{
+		tlsConfig: tlsConfig,
+		quicConfig: &quic.Config{
+			// QUIC combines the transport and TLS handshakes
+			HandshakeIdleTimeout: t.ConnectTimeout + t.TLSHandshakeTimeout,
+			Allow0RTT:            true,
+		},
+		perRequest: t.DisableKeepAlives,
+		handshake:  handshake,
+		closed:     closed,
+	}
+	h.shared = h.newRoundTripper()
+	return h
+}
+
+// newRoundTripper creates an http3.RoundTripper that dials through h
+func (h *h3Transport) newRoundTripper() *http3.RoundTripper {
+	return &http3.RoundTripper{
+		TLSClientConfig: h.tlsConfig,
+		QuicConfig:      h.quicConfig,
+		Dial:            h.dial,
+	}
+}
+
+// RoundTrip sends the request, as 0-RTT early data if it is a GET on a resumed session
+func (h *h3Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method == http.MethodGet {
+		req = req.Clone(req.Context())
+		req.Method = http3.MethodGet0RTT
+	}
+
+	if !h.perRequest {
+		return h.shared.RoundTrip(req)
+	}
+
+	rt := h.newRoundTripper()
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		rt.Close()
+		return nil, err
+	}
+	// Close the connection once the response has been read
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: func() { rt.Close() }}
+	return resp, nil
+}
+
+// dial opens a QUIC connection and reports its handshake and lifetime in the background
+func (h *h3Transport) dial(ctx context.Context, addr string, tlsConfig *tls.Config, quicConfig *quic.Config) (quic.EarlyConnection, error) {
+	start := time.Now()
+	conn, err := quic.DialAddrEarly(ctx, addr, tlsConfig, quicConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		select {
+		case <-conn.HandshakeComplete():
+			if h.handshake != nil {
+				state := conn.ConnectionState()
+				h.handshake(QUICHandshake{
+					Duration: time.Since(start),
+					Resumed:  state.TLS.DidResume,
+					Used0RTT: state.Used0RTT,
+				})
+			}
+		case <-conn.Context().Done():
+			return
+		}
+		<-conn.Context().Done()
+		if h.closed != nil {
+			h.closed(time.Since(start))
+		}
+	}()
+	return conn, nil
+}
@@ -0,0 +1,84 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+)
+
+// startH3Server serves HTTP/3 on a loopback UDP port and returns its URL
+func startH3Server(t *testing.T, p *testPKI) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &http3.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}),
+		TLSConfig:  http3.ConfigureTLSConfig(&tls.Config{Certificates: []tls.Certificate{p.server}}),
+		QuicConfig: &quic.Config{Allow0RTT: true},
+	}
+	go server.Serve(conn)
+	t.Cleanup(func() {
+		server.Close()
+		conn.Close()
+	})
+	return fmt.Sprintf("https://%s/", conn.LocalAddr())
+}
+
+func TestHTTP3Handshakes(t *testing.T) {
+	p := newTestPKI(t)
+	url := startH3Server(t, p)
+
+	handshakes := make(chan QUICHandshake, 10)
+	client, err := New(Options{
+		Transport: TransportOptions{
+			Protocol:          ProtocolHTTP3,
+			DisableKeepAlives: true, // A new connection per request, resuming the first session
+			Timeout:           5 * time.Second,
+			TLS:               TLSOptions{CACert: p.caFile, ServerName: serverName},
+		},
+		QUICHandshake: func(h QUICHandshake) { handshakes <- h },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const requests = 3
+	for i := 0; i < requests; i++ {
+		resp := client.Do(Request{Method: http.MethodGet, URL: url})
+		if resp.Error != nil || resp.StatusCode != http.StatusNoContent {
+			t.Fatalf("request %d: status %d, error %v", i, resp.StatusCode, resp.Error)
+		}
+		if resp.Protocol != "HTTP/3.0" {
+			t.Errorf("request %d: protocol %q, want HTTP/3.0", i, resp.Protocol)
+		}
+	}
+
+	// Handshakes are reported in the background once complete
+	var resumed int
+	for i := 0; i < requests; i++ {
+		select {
+		case h := <-handshakes:
+			if h.Duration <= 0 {
+				t.Errorf("handshake %d took %s", i, h.Duration)
+			}
+			if h.Resumed {
+				resumed++
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("got %d of %d handshakes", i, requests)
+		}
+	}
+	if resumed != requests-1 {
+		t.Errorf("%d handshakes resumed a session, want %d (all but the first)", resumed, requests-1)
+	}
+}
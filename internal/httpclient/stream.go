@@ -0,0 +1,95 @@
+package httpclient
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"mime"
+	"time"
+)
+
+// StreamInfo describes a response body read incrementally in streaming mode
+type StreamInfo struct {
+	SSE        bool            // Events are Server-Sent Events; otherwise each chunk read from the body is an event
+	Events     int             // Events received
+	FirstEvent time.Duration   // From sending the request to the first event (0 without events)
+	Gaps       []time.Duration // Between consecutive events
+	Duration   time.Duration   // From sending the request to the end of the body
+}
+
+// isEventStream reports whether a Content-Type header is text/event-stream
+func isEventStream(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "text/event-stream"
+}
+
+// readStream reads body to the end, timing each event relative to start
+// With sse, an event is dispatched by a blank line after at least one data field
+// Otherwise each read that returns data counts as one event (a chunk as it arrived)
+func readStream(body io.Reader, sse bool, start time.Time) (StreamInfo, error) {
+	info := StreamInfo{SSE: sse}
+	var last time.Time
+	event := func() {
+		now := time.Now()
+		if info.Events == 0 {
+			info.FirstEvent = now.Sub(start)
+		} else {
+			info.Gaps = append(info.Gaps, now.Sub(last))
+		}
+		info.Events++
+		last = now
+	}
+
+	var err error
+	if sse {
+		err = readEvents(body, event)
+	} else {
+		buf := make([]byte, 32*1024)
+		for {
+			var n int
+			n, err = body.Read(buf)
+			if n > 0 {
+				event()
+			}
+			if err != nil {
+				break
+			}
+		}
+	}
+	info.Duration = time.Since(start)
+
+	if err == io.EOF {
+		err = nil
+	}
+	return info, err
+}
+
+// readEvents parses a text/event-stream body and calls event for every dispatched event
+// Comments and events without data are skipped; an incomplete event at the end is discarded
+func readEvents(body io.Reader, event func()) error {
+	reader := bufio.NewReader(body)
+	hasData := false
+	partial := false // Inside a line longer than the reader's buffer
+	for {
+		line, err := reader.ReadSlice('\n')
+		if err != nil && err != bufio.ErrBufferFull && err != io.EOF {
+			return err
+		}
+		if !partial {
+			field := bytes.TrimRight(line, "\r\n")
+			switch {
+			case len(field) == 0 && err == nil:
+				if hasData {
+					event()
+				}
+				hasData = false
+			case bytes.Equal(field, []byte("data")) || bytes.HasPrefix(field, []byte("data:")):
+				hasData = true
+			}
+		}
+		partial = err == bufio.ErrBufferFull
+		if err == io.EOF {
+			return err
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package httpclient
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+func TestReadEvents(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"two events", "data: a\n\ndata: b\n\n", 2},
+		{"multi-line data", "data: a\ndata: b\n\n", 1},
+		{"CRLF line endings", "data: a\r\n\r\ndata: b\r\n\r\n", 2},
+		{"data without a value", "data\n\n", 1},
+		{"other fields", "id: 1\nevent: update\nretry: 100\ndata: a\n\n", 1},
+		{"comments and events without data", ": keep-alive\n\nevent: ping\n\ndata: a\n\n", 1},
+		{"repeated blank lines", "data: a\n\n\n\ndata: b\n\n", 2},
+		{"field named like data", "dataset: a\n\n", 0},
+		{"incomplete last event", "data: a\n\ndata: b\n", 1},
+		{"line longer than the read buffer", "data: " + strings.Repeat("x", 10000) + "\n\ndata: b\n\n", 2},
+	}
+	for _, tt := range tests {
+		for _, split := range []struct {
+			name string
+			wrap func(io.Reader) io.Reader
+		}{
+			{"whole", func(r io.Reader) io.Reader { return r }},
+			{"one byte per read", iotest.OneByteReader},
+			{"half reads", iotest.HalfReader},
+		} {
+			events := 0
+			err := readEvents(split.wrap(strings.NewReader(tt.body)), func() { events++ })
+			if err != io.EOF {
+				t.Errorf("%s (%s): error = %v, want io.EOF", tt.name, split.name, err)
+			}
+			if events != tt.want {
+				t.Errorf("%s (%s): got %d events, want %d", tt.name, split.name, events, tt.want)
+			}
+		}
+	}
+}
+
+func TestReadStreamTimesEventsWhenComplete(t *testing.T) {
+	r, w := io.Pipe()
+	go func() {
+		// The first event is split across writes; it only counts once its blank line arrives
+		io.WriteString(w, "data: a\n")
+		time.Sleep(50 * time.Millisecond)
+		io.WriteString(w, "\n")
+		time.Sleep(50 * time.Millisecond)
+		io.WriteString(w, "data: b\n\n")
+		w.Close()
+	}()
+
+	info, err := readStream(r, true, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.SSE || info.Events != 2 {
+		t.Fatalf("got %+v, want 2 SSE events", info)
+	}
+	if info.FirstEvent < 50*time.Millisecond {
+		t.Errorf("first event after %s, want it timed when the event completed (>= 50ms)", info.FirstEvent)
+	}
+	if len(info.Gaps) != 1 || info.Gaps[0] < 50*time.Millisecond {
+		t.Errorf("gaps = %v, want one of at least 50ms", info.Gaps)
+	}
+	if info.Duration < info.FirstEvent+info.Gaps[0] {
+		t.Errorf("duration %s is shorter than the events", info.Duration)
+	}
+}
+
+func TestReadStreamCountsChunks(t *testing.T) {
+	r, w := io.Pipe()
+	go func() {
+		for _, chunk := range []string{"first", "second", "third"} {
+			io.WriteString(w, chunk)
+			time.Sleep(10 * time.Millisecond)
+		}
+		w.Close()
+	}()
+
+	info, err := readStream(r, false, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.SSE || info.Events != 3 || len(info.Gaps) != 2 {
+		t.Errorf("got %+v, want 3 chunk events with 2 gaps", info)
+	}
+}
+
+func TestReadStreamReportsCutOffBodies(t *testing.T) {
+	body := io.MultiReader(strings.NewReader("data: a\n\n"), iotest.ErrReader(io.ErrUnexpectedEOF))
+	info, err := readStream(body, true, time.Now())
+	if err != io.ErrUnexpectedEOF {
+		t.Errorf("error = %v, want io.ErrUnexpectedEOF", err)
+	}
+	if info.Events != 1 {
+		t.Errorf("got %d events before the error, want 1", info.Events)
+	}
+}
+
+func TestClientStreamsEvents(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+		flusher := w.(http.Flusher)
+		for i := 0; i < 3; i++ {
+			io.WriteString(w, "data: tick\n")
+			flusher.Flush()
+			io.WriteString(w, "\n")
+			flusher.Flush()
+		}
+	}))
+	defer server.Close()
+
+	client, err := New(Options{Stream: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := client.Do(Request{Method: http.MethodGet, URL: server.URL})
+	if resp.Error != nil {
+		t.Fatal(resp.Error)
+	}
+	if resp.Stream == nil || !resp.Stream.SSE || resp.Stream.Events != 3 {
+		t.Errorf("stream = %+v, want 3 SSE events", resp.Stream)
+	}
+}
+
+func TestIsEventStream(t *testing.T) {
+	for contentType, want := range map[string]bool{
+		"text/event-stream":                true,
+		"Text/Event-Stream; charset=utf-8": true,
+		"text/plain":                       false,
+		"":                                 false,
+	} {
+		if got := isEventStream(contentType); got != want {
+			t.Errorf("isEventStream(%q) = %v, want %v", contentType, got, want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// TLSOptions configures certificate verification, client certificates and protocol versions
+type TLSOptions struct {
+	CACert       string   // PEM file with CA certificates trusted in addition to the system roots
+	Cert         string   // PEM client certificate for mTLS
+	Key          string   // PEM private key of the client certificate
+	Insecure     bool     // Skip server certificate verification
+	ServerName   string   // SNI and verification name (default: the URL host)
+	MinVersion   uint16   // Lowest accepted version (0 = Go default)
+	MaxVersion   uint16   // Highest accepted version (0 = Go default)
+	CipherSuites []uint16 // Allowed TLS 1.0-1.2 cipher suites (nil = Go default; TLS 1.3 suites are not configurable)
+}
+
+// tlsVersions maps version names accepted on the command line to protocol versions
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// ParseTLSVersion parses a TLS version such as "1.2" (empty = 0, the Go default)
+func ParseTLSVersion(version string) (uint16, error) {
+	version = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(version)), "tls")
+	if version == "" {
+		return 0, nil
+	}
+	v, ok := tlsVersions[strings.TrimSpace(version)]
+	if !ok {
+		return 0, fmt.Errorf("unknown TLS version %q (supported: 1.0, 1.1, 1.2, 1.3)", version)
+	}
+	return v, nil
+}
+
+// ParseCipherSuites parses a comma-separated list of cipher suite names
+// such as "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256" (empty = nil, the Go default)
+func ParseCipherSuites(list string) ([]uint16, error) {
+	if strings.TrimSpace(list) == "" {
+		return nil, nil
+	}
+
+	ids := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		ids[suite.Name] = suite.ID
+	}
+	for _, suite := range tls.InsecureCipherSuites() {
+		ids[suite.Name] = suite.ID
+	}
+
+	var suites []uint16
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToUpper(strings.TrimSpace(name))
+		id, ok := ids[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown cipher suite %q", name)
+		}
+		suites = append(suites, id)
+	}
+	return suites, nil
+}
+
+// tlsConfig builds the client TLS configuration, or returns nil if all options are defaults
+func (o TLSOptions) tlsConfig() (*tls.Config, error) {
+	if o.CACert == "" && o.Cert == "" && o.Key == "" && !o.Insecure && o.ServerName == "" &&
+		o.MinVersion == 0 && o.MaxVersion == 0 && o.CipherSuites == nil {
+		return nil, nil
+	}
+	if (o.Cert == "") != (o.Key == "") {
+		return nil, fmt.Errorf("client certificate and key must be given together")
+	}
+
+	config := &tls.Config{
+		InsecureSkipVerify: o.Insecure,
+		ServerName:         o.ServerName,
+		MinVersion:         o.MinVersion,
+		MaxVersion:         o.MaxVersion,
+		CipherSuites:       o.CipherSuites,
+	}
+	if o.MinVersion != 0 && o.MaxVersion != 0 && o.MinVersion > o.MaxVersion {
+		return nil, fmt.Errorf("minimum TLS version is above the maximum")
+	}
+
+	if o.CACert != "" {
+		pem, err := os.ReadFile(o.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", o.CACert)
+		}
+		config.RootCAs = pool
+	}
+
+	if o.Cert != "" {
+		cert, err := tls.LoadX509KeyPair(o.Cert, o.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
@@ -0,0 +1,241 @@
+package httpclient
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testPKI is a CA with a server certificate for serverName and a client certificate
+type testPKI struct {
+	caFile, certFile, keyFile string // PEM files for the client: CA, client certificate and key
+	pool                      *x509.CertPool
+	server                    tls.Certificate
+}
+
+const serverName = "g0.test"
+
+// newTestPKI creates the certificates and writes the client's files to a temporary directory
+func newTestPKI(t *testing.T) *testPKI {
+	t.Helper()
+	dir := t.TempDir()
+
+	caKey := newKey(t)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "g0 test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issue := func(serial int64, usage x509.ExtKeyUsage, dnsNames []string) ([]byte, *ecdsa.PrivateKey) {
+		key := newKey(t)
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: "g0 test"},
+			DNSNames:     dnsNames,
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return der, key
+	}
+
+	serverDER, serverKey := issue(2, x509.ExtKeyUsageServerAuth, []string{serverName})
+	clientDER, clientKey := issue(3, x509.ExtKeyUsageClientAuth, nil)
+
+	p := &testPKI{
+		caFile:   filepath.Join(dir, "ca.pem"),
+		certFile: filepath.Join(dir, "client.pem"),
+		keyFile:  filepath.Join(dir, "client-key.pem"),
+		pool:     x509.NewCertPool(),
+		server:   tls.Certificate{Certificate: [][]byte{serverDER}, PrivateKey: serverKey},
+	}
+	p.pool.AddCert(ca)
+	writePEM(t, p.caFile, "CERTIFICATE", caDER)
+	writePEM(t, p.certFile, "CERTIFICATE", clientDER)
+	keyDER, err := x509.MarshalECPrivateKey(clientKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, p.keyFile, "EC PRIVATE KEY", keyDER)
+	return p
+}
+
+func newKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// startTLSServer starts an HTTPS server with the PKI's server certificate, applying configure
+// to its TLS config, and returns it with the SNI names it received
+func startTLSServer(t *testing.T, p *testPKI, configure func(*tls.Config)) (*httptest.Server, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var names []string
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{p.server},
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			names = append(names, hello.ServerName)
+			return nil, nil
+		},
+	}
+	if configure != nil {
+		configure(server.TLS)
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), names...)
+	}
+}
+
+// get sends one GET request with the given TLS options
+func get(t *testing.T, url string, opts TLSOptions) Response {
+	t.Helper()
+	client, err := New(Options{Transport: TransportOptions{TLS: opts, Timeout: 5 * time.Second}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client.Do(Request{Method: http.MethodGet, URL: url})
+}
+
+func TestMutualTLS(t *testing.T) {
+	p := newTestPKI(t)
+	server, _ := startTLSServer(t, p, func(c *tls.Config) {
+		c.ClientAuth = tls.RequireAndVerifyClientCert
+		c.ClientCAs = p.pool
+	})
+
+	resp := get(t, server.URL, TLSOptions{CACert: p.caFile, Cert: p.certFile, Key: p.keyFile, ServerName: serverName})
+	if resp.Error != nil || resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("with a client certificate: status %d, error %v", resp.StatusCode, resp.Error)
+	}
+	if resp.Conn.TLSVersion == 0 {
+		t.Error("TLS version was not recorded")
+	}
+
+	if resp := get(t, server.URL, TLSOptions{CACert: p.caFile, ServerName: serverName}); resp.Error == nil {
+		t.Errorf("without a client certificate: status %d, want a handshake error", resp.StatusCode)
+	}
+}
+
+func TestServerName(t *testing.T) {
+	p := newTestPKI(t)
+	server, names := startTLSServer(t, p, nil)
+
+	// The certificate is for g0.test, not the 127.0.0.1 of the URL
+	if resp := get(t, server.URL, TLSOptions{CACert: p.caFile}); resp.Error == nil {
+		t.Errorf("without a server name: status %d, want a verification error", resp.StatusCode)
+	}
+
+	resp := get(t, server.URL, TLSOptions{CACert: p.caFile, ServerName: serverName})
+	if resp.Error != nil {
+		t.Fatalf("with a server name: %v", resp.Error)
+	}
+	if got := names(); len(got) == 0 || got[len(got)-1] != serverName {
+		t.Errorf("server received SNI %q, want %q", got, serverName)
+	}
+}
+
+func TestTLSVersions(t *testing.T) {
+	p := newTestPKI(t)
+	server, _ := startTLSServer(t, p, func(c *tls.Config) {
+		c.MaxVersion = tls.VersionTLS12
+	})
+
+	if resp := get(t, server.URL, TLSOptions{CACert: p.caFile, ServerName: serverName, MinVersion: tls.VersionTLS13}); resp.Error == nil {
+		t.Errorf("with minimum TLS 1.3 against a TLS 1.2 server: status %d, want a handshake error", resp.StatusCode)
+	}
+
+	resp := get(t, server.URL, TLSOptions{CACert: p.caFile, ServerName: serverName, MinVersion: tls.VersionTLS12})
+	if resp.Error != nil {
+		t.Fatalf("with minimum TLS 1.2: %v", resp.Error)
+	}
+	if resp.Conn.TLSVersion != tls.VersionTLS12 {
+		t.Errorf("negotiated %s, want TLS 1.2", tls.VersionName(resp.Conn.TLSVersion))
+	}
+
+	suite := uint16(tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384)
+	resp = get(t, server.URL, TLSOptions{CACert: p.caFile, ServerName: serverName, CipherSuites: []uint16{suite}})
+	if resp.Error != nil {
+		t.Fatalf("with a cipher suite: %v", resp.Error)
+	}
+	if resp.Conn.TLSCipherSuite != suite {
+		t.Errorf("negotiated %s, want %s", tls.CipherSuiteName(resp.Conn.TLSCipherSuite), tls.CipherSuiteName(suite))
+	}
+}
+
+func TestTLSOptionsErrors(t *testing.T) {
+	p := newTestPKI(t)
+	for name, opts := range map[string]TLSOptions{
+		"certificate without key": {Cert: p.certFile},
+		"min above max":           {MinVersion: tls.VersionTLS13, MaxVersion: tls.VersionTLS12},
+		"missing CA file":         {CACert: filepath.Join(t.TempDir(), "missing.pem")},
+		"CA file without PEM":     {CACert: p.keyFile},
+	} {
+		if _, err := New(Options{Transport: TransportOptions{TLS: opts}}); err == nil {
+			t.Errorf("%s: New succeeded, want an error", name)
+		}
+	}
+}
+
+func TestParseTLSVersion(t *testing.T) {
+	for input, want := range map[string]uint16{"": 0, "1.2": tls.VersionTLS12, "TLS1.3": tls.VersionTLS13, "tls 1.0": tls.VersionTLS10} {
+		got, err := ParseTLSVersion(input)
+		if err != nil || got != want {
+			t.Errorf("ParseTLSVersion(%q) = %d, %v, want %d", input, got, err, want)
+		}
+	}
+	if _, err := ParseTLSVersion("1.4"); err == nil {
+		t.Error("ParseTLSVersion(1.4) succeeded, want an error")
+	}
+}
@@ -0,0 +1,33 @@
+package httpclient
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	mathrand "math/rand"
+)
+
+// traceparentHeader is the W3C Trace Context propagation header
+const traceparentHeader = "traceparent"
+
+// shouldSample decides whether a request carries trace context
+func shouldSample(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	if rate >= 1 {
+		return true
+	}
+	return mathrand.Float64() < rate
+}
+
+// newTraceparent generates a sampled W3C traceparent value and returns it with its trace ID
+// Format: 00-<32 hex trace id>-<16 hex parent span id>-01
+func newTraceparent() (header string, traceID string, err error) {
+	var ids [24]byte
+	if _, err := rand.Read(ids[:]); err != nil {
+		return "", "", err
+	}
+	traceID = hex.EncodeToString(ids[:16])
+	spanID := hex.EncodeToString(ids[16:])
+	return "00-" + traceID + "-" + spanID + "-01", traceID, nil
+}
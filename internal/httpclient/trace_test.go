@@ -0,0 +1,108 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// traceparentFormat is a sampled W3C traceparent of version 00
+var traceparentFormat = regexp.MustCompile(`^00-([0-9a-f]{32})-([0-9a-f]{16})-01$`)
+
+// startTraceServer serves 204s, sending the traceparent header of each request to headers
+func startTraceServer(t *testing.T, headers chan<- string) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header.Get(traceparentHeader)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestClientInjectsTraceparent(t *testing.T) {
+	headers := make(chan string, 1)
+	url := startTraceServer(t, headers)
+	client, err := New(Options{TraceSampleRate: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[string]bool)
+	for i := 0; i < 20; i++ {
+		resp := client.Do(Request{Method: http.MethodGet, URL: url})
+		if resp.Error != nil {
+			t.Fatal(resp.Error)
+		}
+		header := <-headers
+		match := traceparentFormat.FindStringSubmatch(header)
+		if match == nil {
+			t.Fatalf("traceparent = %q, want 00-<32 hex>-<16 hex>-01", header)
+		}
+		// All-zero IDs are invalid
+		if strings.Trim(match[1], "0") == "" || strings.Trim(match[2], "0") == "" {
+			t.Errorf("traceparent %q has an all-zero ID", header)
+		}
+		if resp.TraceID != match[1] {
+			t.Errorf("response trace ID = %q, want %q from the header", resp.TraceID, match[1])
+		}
+		if seen[match[1]] {
+			t.Errorf("trace ID %s sent twice", match[1])
+		}
+		seen[match[1]] = true
+	}
+}
+
+func TestClientKeepsCallerTraceparent(t *testing.T) {
+	headers := make(chan string, 1)
+	url := startTraceServer(t, headers)
+	client, err := New(Options{TraceSampleRate: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	own := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	resp := client.Do(Request{Method: http.MethodGet, URL: url, Headers: map[string]string{"Traceparent": own}})
+	if resp.Error != nil {
+		t.Fatal(resp.Error)
+	}
+	if header := <-headers; header != own {
+		t.Errorf("traceparent = %q, want the caller's %q", header, own)
+	}
+	if resp.TraceID != "" {
+		t.Errorf("trace ID = %q for a request not sampled by the client", resp.TraceID)
+	}
+}
+
+func TestClientWithoutTracing(t *testing.T) {
+	headers := make(chan string, 1)
+	url := startTraceServer(t, headers)
+	client, err := New(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := client.Do(Request{Method: http.MethodGet, URL: url})
+	if resp.Error != nil {
+		t.Fatal(resp.Error)
+	}
+	if header := <-headers; header != "" || resp.TraceID != "" {
+		t.Errorf("traceparent %q with trace ID %q, want neither", header, resp.TraceID)
+	}
+}
+
+func TestShouldSample(t *testing.T) {
+	for rate, want := range map[float64][2]int{-1: {0, 0}, 0: {0, 0}, 0.25: {2250, 2750}, 1: {10000, 10000}, 2: {10000, 10000}} {
+		sampled := 0
+		for i := 0; i < 10000; i++ {
+			if shouldSample(rate) {
+				sampled++
+			}
+		}
+		if sampled < want[0] || sampled > want[1] {
+			t.Errorf("shouldSample(%v) sampled %d of 10000, want %d to %d", rate, sampled, want[0], want[1])
+		}
+	}
+}
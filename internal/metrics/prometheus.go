@@ -0,0 +1,175 @@
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/calummacc/g0/internal/runner"
+)
+
+// PrometheusServer serves live run statistics on /metrics in the Prometheus text format
+type PrometheusServer struct {
+	server   *http.Server
+	listener net.Listener
+	stats    atomic.Pointer[runner.Stats]
+}
+
+// NewPrometheusServer creates a metrics server that will listen on addr (e.g. ":9091")
+func NewPrometheusServer(addr string) *PrometheusServer {
+	p := &PrometheusServer{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", p.handleMetrics)
+
+	p.server = &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	return p
+}
+
+// Start binds the listen address and serves metrics in the background
+func (p *PrometheusServer) Start() error {
+	listener, err := net.Listen("tcp", p.server.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", p.server.Addr, err)
+	}
+	p.listener = listener
+
+	go p.server.Serve(listener)
+	return nil
+}
+
+// Addr returns the address the server is listening on
+func (p *PrometheusServer) Addr() string {
+	if p.listener == nil {
+		return p.server.Addr
+	}
+	return p.listener.Addr().String()
+}
+
+// SetStats sets the stats instance to expose
+// Until it is set, /metrics only reports that no run is in progress
+func (p *PrometheusServer) SetStats(stats *runner.Stats) {
+	p.stats.Store(stats)
+}
+
+// Stop shuts the server down, waiting briefly for in-progress scrapes
+func (p *PrometheusServer) Stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	p.server.Shutdown(ctx)
+}
+
+// handleMetrics writes the current snapshot in the Prometheus text exposition format
+func (p *PrometheusServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	bw := bufio.NewWriter(w)
+	defer bw.Flush()
+
+	stats := p.stats.Load()
+	if stats == nil {
+		writeHeader(bw, "g0_up", "gauge", "Whether a load test is currently running.")
+		fmt.Fprintln(bw, "g0_up 0")
+		return
+	}
+
+	writeMetrics(bw, stats.GetMetricsSnapshot())
+}
+
+// writeMetrics writes a metrics snapshot in the Prometheus text exposition format
+func writeMetrics(w io.Writer, snapshot runner.MetricsSnapshot) {
+	writeHeader(w, "g0_up", "gauge", "Whether a load test is currently running.")
+	fmt.Fprintln(w, "g0_up 1")
+
+	// Requests by URL and status code
+	writeHeader(w, "g0_requests_total", "counter", "Total number of completed requests by URL and status code.")
+	urls := make([]string, 0, len(snapshot.URLStatusCounts))
+	for url := range snapshot.URLStatusCounts {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+	for _, url := range urls {
+		counts := snapshot.URLStatusCounts[url]
+		codes := make([]int, 0, len(counts))
+		for code := range counts {
+			codes = append(codes, code)
+		}
+		sort.Ints(codes)
+		for _, code := range codes {
+			fmt.Fprintf(w, "g0_requests_total{url=\"%s\",status=\"%s\"} %d\n",
+				escapeLabel(url), statusLabel(code, snapshot.GRPC), counts[code])
+		}
+	}
+
+	writeHeader(w, "g0_requests_failed_total", "counter", "Total number of failed requests (network errors and HTTP status >= 400).")
+	fmt.Fprintf(w, "g0_requests_failed_total %d\n", snapshot.FailedRequests)
+
+	// Errors by class
+	writeHeader(w, "g0_errors_total", "counter", "Total number of network errors by class.")
+	classes := make([]string, 0, len(snapshot.ErrorClassCounts))
+	for class := range snapshot.ErrorClassCounts {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	for _, class := range classes {
+		fmt.Fprintf(w, "g0_errors_total{class=\"%s\"} %d\n", escapeLabel(class), snapshot.ErrorClassCounts[class])
+	}
+
+	// Latency histogram (Prometheus buckets are cumulative)
+	writeHeader(w, "g0_request_duration_seconds", "histogram", "Request latency in seconds.")
+	var cumulative int64
+	for i, bound := range runner.LatencyBuckets {
+		cumulative += snapshot.BucketCounts[i]
+		fmt.Fprintf(w, "g0_request_duration_seconds_bucket{le=\"%s\"} %d\n", formatFloat(bound.Seconds()), cumulative)
+	}
+	cumulative += snapshot.BucketCounts[len(runner.LatencyBuckets)]
+	fmt.Fprintf(w, "g0_request_duration_seconds_bucket{le=\"+Inf\"} %d\n", cumulative)
+	fmt.Fprintf(w, "g0_request_duration_seconds_sum %s\n", formatFloat(snapshot.LatencySum.Seconds()))
+	fmt.Fprintf(w, "g0_request_duration_seconds_count %d\n", cumulative)
+
+	writeHeader(w, "g0_requests_in_flight", "gauge", "Number of requests currently in flight.")
+	fmt.Fprintf(w, "g0_requests_in_flight %d\n", snapshot.InFlight)
+
+	writeHeader(w, "g0_active_workers", "gauge", "Number of workers currently running.")
+	fmt.Fprintf(w, "g0_active_workers %d\n", snapshot.ActiveWorkers)
+}
+
+// writeHeader writes the HELP and TYPE lines for a metric family
+func writeHeader(w io.Writer, name, metricType, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, metricType)
+}
+
+// statusLabel converts a status code to a label value, using "error" for network errors (code 0)
+// gRPC codes are used as they are, since 0 is OK
+func statusLabel(code int, grpc bool) string {
+	if code == 0 && !grpc {
+		return "error"
+	}
+	return strconv.Itoa(code)
+}
+
+// labelEscaper escapes label values as required by the text exposition format
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabel escapes a label value
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+// formatFloat formats a float without trailing zeros (e.g. 0.0025, 1, 2.5)
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
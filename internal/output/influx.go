@@ -0,0 +1,122 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// InfluxSink writes metrics in InfluxDB line protocol over HTTP
+// The target is the full write URL, e.g. http://localhost:8086/write?db=g0 (v1)
+// or http://localhost:8086/api/v2/write?org=acme&bucket=g0 (v2, token from G0_INFLUX_TOKEN)
+type InfluxSink struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewInfluxSink creates an InfluxDB sink for the given write URL
+func NewInfluxSink(target string) (*InfluxSink, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid influx URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid influx URL %q (expected http:// or https://)", target)
+	}
+
+	return &InfluxSink{
+		url:    target,
+		token:  os.Getenv("G0_INFLUX_TOKEN"),
+		client: &http.Client{Timeout: 5 * time.Second},
+	}, nil
+}
+
+// Name returns the sink name used in error messages
+func (s *InfluxSink) Name() string {
+	return "influx"
+}
+
+// Write sends one batch as line protocol
+func (s *InfluxSink) Write(batch *Batch) error {
+	body := encodeLineProtocol(batch)
+	if len(body) == 0 {
+		return nil
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Token "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("influx returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// Close releases idle connections
+func (s *InfluxSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+// encodeLineProtocol renders a batch as InfluxDB line protocol with nanosecond timestamps
+func encodeLineProtocol(batch *Batch) []byte {
+	var buf bytes.Buffer
+	ts := strconv.FormatInt(batch.Time.UnixNano(), 10)
+	runTag := "run_id=" + escapeTag(batch.RunID)
+
+	for _, rc := range batch.Requests {
+		fmt.Fprintf(&buf, "g0_requests,%s,url=%s,status=%s count=%di %s\n",
+			runTag, escapeTag(rc.URL), statusTag(rc.StatusCode, batch.GRPC), rc.Count, ts)
+	}
+
+	for _, class := range sortedKeys(batch.Errors) {
+		fmt.Fprintf(&buf, "g0_errors,%s,class=%s count=%di %s\n",
+			runTag, escapeTag(class), batch.Errors[class], ts)
+	}
+
+	if lat := batch.Latency; lat.Count > 0 {
+		fmt.Fprintf(&buf, "g0_latency,%s count=%di,min=%s,max=%s,avg=%s,p50=%s,p90=%s,p95=%s,p99=%s %s\n",
+			runTag, lat.Count,
+			formatMs(lat.Min), formatMs(lat.Max), formatMs(lat.Avg),
+			formatMs(lat.P50), formatMs(lat.P90), formatMs(lat.P95), formatMs(lat.P99), ts)
+	}
+
+	fmt.Fprintf(&buf, "g0_load,%s in_flight=%di,active_workers=%di %s\n",
+		runTag, batch.InFlight, batch.ActiveWorkers, ts)
+
+	return buf.Bytes()
+}
+
+// tagEscaper escapes tag keys and values as required by line protocol
+var tagEscaper = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `, "\n", "")
+
+// escapeTag escapes a tag value
+func escapeTag(value string) string {
+	return tagEscaper.Replace(value)
+}
+
+// formatMs formats a duration as fractional milliseconds
+func formatMs(d time.Duration) string {
+	return strconv.FormatFloat(durationMs(d), 'f', 3, 64)
+}
@@ -0,0 +1,260 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/calummacc/g0/internal/runner"
+)
+
+// OTLP aggregation temporality (opentelemetry.proto.metrics.v1.AggregationTemporality)
+const otlpTemporalityDelta = 1
+
+// OTLPSink exports metrics to an OpenTelemetry collector using OTLP/HTTP with JSON encoding
+// The target is the collector base URL (e.g. http://localhost:4318) or the full /v1/metrics URL
+type OTLPSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewOTLPSink creates an OTLP/HTTP metrics sink
+func NewOTLPSink(target string) (*OTLPSink, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid otlp URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid otlp URL %q (expected http:// or https://)", target)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = "/v1/metrics"
+	}
+
+	return &OTLPSink{
+		url:    u.String(),
+		client: &http.Client{Timeout: 5 * time.Second},
+	}, nil
+}
+
+// Name returns the sink name used in error messages
+func (s *OTLPSink) Name() string {
+	return "otlp"
+}
+
+// Write exports one batch as an ExportMetricsServiceRequest
+func (s *OTLPSink) Write(batch *Batch) error {
+	body, err := json.Marshal(encodeOTLP(batch))
+	if err != nil {
+		return fmt.Errorf("failed to marshal OTLP request: %w", err)
+	}
+
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("collector returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// Close releases idle connections
+func (s *OTLPSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+// OTLP JSON message types (subset of opentelemetry.proto.collector.metrics.v1)
+
+type otlpRequest struct {
+	ResourceMetrics []otlpResourceMetrics `json:"resourceMetrics"`
+}
+
+type otlpResourceMetrics struct {
+	Resource     otlpResource       `json:"resource"`
+	ScopeMetrics []otlpScopeMetrics `json:"scopeMetrics"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeMetrics struct {
+	Scope   otlpScope    `json:"scope"`
+	Metrics []otlpMetric `json:"metrics"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpMetric struct {
+	Name      string         `json:"name"`
+	Unit      string         `json:"unit,omitempty"`
+	Sum       *otlpSum       `json:"sum,omitempty"`
+	Gauge     *otlpGauge     `json:"gauge,omitempty"`
+	Histogram *otlpHistogram `json:"histogram,omitempty"`
+}
+
+type otlpSum struct {
+	AggregationTemporality int                   `json:"aggregationTemporality"`
+	IsMonotonic            bool                  `json:"isMonotonic"`
+	DataPoints             []otlpNumberDataPoint `json:"dataPoints"`
+}
+
+type otlpGauge struct {
+	DataPoints []otlpNumberDataPoint `json:"dataPoints"`
+}
+
+type otlpNumberDataPoint struct {
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano,omitempty"`
+	TimeUnixNano      string          `json:"timeUnixNano"`
+	AsInt             string          `json:"asInt"`
+}
+
+type otlpHistogram struct {
+	AggregationTemporality int                      `json:"aggregationTemporality"`
+	DataPoints             []otlpHistogramDataPoint `json:"dataPoints"`
+}
+
+type otlpHistogramDataPoint struct {
+	StartTimeUnixNano string    `json:"startTimeUnixNano"`
+	TimeUnixNano      string    `json:"timeUnixNano"`
+	Count             string    `json:"count"`
+	Sum               float64   `json:"sum"`
+	BucketCounts      []string  `json:"bucketCounts"`
+	ExplicitBounds    []float64 `json:"explicitBounds"`
+	Min               *float64  `json:"min,omitempty"`
+	Max               *float64  `json:"max,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+// encodeOTLP converts a batch into an OTLP export request
+// Counters and the latency histogram use delta temporality; latencies are in seconds
+func encodeOTLP(batch *Batch) otlpRequest {
+	start := unixNano(batch.StartTime)
+	now := unixNano(batch.Time)
+
+	var metrics []otlpMetric
+
+	if len(batch.Requests) > 0 {
+		points := make([]otlpNumberDataPoint, 0, len(batch.Requests))
+		for _, rc := range batch.Requests {
+			points = append(points, otlpNumberDataPoint{
+				Attributes:        []otlpAttribute{stringAttr("url", rc.URL), stringAttr("status", statusTag(rc.StatusCode, batch.GRPC))},
+				StartTimeUnixNano: start,
+				TimeUnixNano:      now,
+				AsInt:             strconv.FormatInt(rc.Count, 10),
+			})
+		}
+		metrics = append(metrics, otlpMetric{
+			Name: "g0.requests",
+			Unit: "{request}",
+			Sum:  &otlpSum{AggregationTemporality: otlpTemporalityDelta, IsMonotonic: true, DataPoints: points},
+		})
+	}
+
+	if len(batch.Errors) > 0 {
+		points := make([]otlpNumberDataPoint, 0, len(batch.Errors))
+		for _, class := range sortedKeys(batch.Errors) {
+			points = append(points, otlpNumberDataPoint{
+				Attributes:        []otlpAttribute{stringAttr("class", class)},
+				StartTimeUnixNano: start,
+				TimeUnixNano:      now,
+				AsInt:             strconv.FormatInt(batch.Errors[class], 10),
+			})
+		}
+		metrics = append(metrics, otlpMetric{
+			Name: "g0.errors",
+			Unit: "{error}",
+			Sum:  &otlpSum{AggregationTemporality: otlpTemporalityDelta, IsMonotonic: true, DataPoints: points},
+		})
+	}
+
+	if batch.Latency.Count > 0 {
+		bounds := make([]float64, len(runner.LatencyBuckets))
+		for i, bound := range runner.LatencyBuckets {
+			bounds[i] = bound.Seconds()
+		}
+		var count int64
+		bucketCounts := make([]string, len(batch.LatencyBuckets))
+		for i, c := range batch.LatencyBuckets {
+			bucketCounts[i] = strconv.FormatInt(c, 10)
+			count += c
+		}
+		min := batch.Latency.Min.Seconds()
+		max := batch.Latency.Max.Seconds()
+		metrics = append(metrics, otlpMetric{
+			Name: "g0.request.duration",
+			Unit: "s",
+			Histogram: &otlpHistogram{
+				AggregationTemporality: otlpTemporalityDelta,
+				DataPoints: []otlpHistogramDataPoint{{
+					StartTimeUnixNano: start,
+					TimeUnixNano:      now,
+					Count:             strconv.FormatInt(count, 10),
+					Sum:               batch.LatencySum.Seconds(),
+					BucketCounts:      bucketCounts,
+					ExplicitBounds:    bounds,
+					Min:               &min,
+					Max:               &max,
+				}},
+			},
+		})
+	}
+
+	metrics = append(metrics,
+		otlpMetric{
+			Name:  "g0.requests.in_flight",
+			Unit:  "{request}",
+			Gauge: &otlpGauge{DataPoints: []otlpNumberDataPoint{{TimeUnixNano: now, AsInt: strconv.FormatInt(batch.InFlight, 10)}}},
+		},
+		otlpMetric{
+			Name:  "g0.workers.active",
+			Unit:  "{worker}",
+			Gauge: &otlpGauge{DataPoints: []otlpNumberDataPoint{{TimeUnixNano: now, AsInt: strconv.FormatInt(batch.ActiveWorkers, 10)}}},
+		},
+	)
+
+	return otlpRequest{
+		ResourceMetrics: []otlpResourceMetrics{{
+			Resource: otlpResource{
+				Attributes: []otlpAttribute{stringAttr("service.name", "g0"), stringAttr("g0.run_id", batch.RunID)},
+			},
+			ScopeMetrics: []otlpScopeMetrics{{
+				Scope:   otlpScope{Name: "github.com/calummacc/g0"},
+				Metrics: metrics,
+			}},
+		}},
+	}
+}
+
+// stringAttr creates a string-valued OTLP attribute
+func stringAttr(key, value string) otlpAttribute {
+	return otlpAttribute{Key: key, Value: otlpAnyValue{StringValue: value}}
+}
+
+// unixNano formats a timestamp as OTLP fixed64 nanoseconds (encoded as a JSON string)
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
@@ -0,0 +1,128 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/calummacc/g0/internal/runner"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// checkGolden compares got, indented, with testdata/name, or rewrites the file with -update
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, got, "", "  "); err != nil {
+		t.Fatalf("invalid JSON %s: %v", got, err)
+	}
+	indented.WriteByte('\n')
+
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, indented.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(indented.Bytes(), want) {
+		t.Errorf("payload differs from %s (rerun with -update to accept it):\n%s", path, indented.Bytes())
+	}
+}
+
+func TestOTLPSinkExportsMetrics(t *testing.T) {
+	bodies := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/metrics" || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("got %s %s (%s), want a JSON POST to /v1/metrics", r.Method, r.URL.Path, r.Header.Get("Content-Type"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		bodies <- body
+	}))
+	defer server.Close()
+
+	sink, err := NewOTLPSink(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	start := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	buckets := make([]int64, len(runner.LatencyBuckets)+1)
+	buckets[3], buckets[4], buckets[5] = 2, 1, 1 // 10ms, 25ms and 50ms bounds
+	batch := &Batch{
+		StartTime: start,
+		Time:      start.Add(10 * time.Second),
+		RunID:     "run1",
+		Requests: []RequestCount{
+			{URL: "http://example.test/a", StatusCode: 200, Count: 2},
+			{URL: "http://example.test/a", StatusCode: 500, Count: 1},
+			{URL: "http://example.test/a", StatusCode: 0, Count: 1},
+		},
+		Errors:         map[string]int64{"timeout": 1, "connection_reset": 2},
+		Latency:        LatencyStats{Count: 4, Min: 10 * time.Millisecond, Max: 40 * time.Millisecond, Avg: 25 * time.Millisecond},
+		LatencyBuckets: buckets,
+		LatencySum:     100 * time.Millisecond,
+		InFlight:       3,
+		ActiveWorkers:  8,
+	}
+	if err := sink.Write(batch); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "otlp.json", <-bodies)
+
+	// Without requests in the interval only the gauges are exported
+	idle := &Batch{StartTime: start, Time: start.Add(10 * time.Second), RunID: "run1", LatencyBuckets: make([]int64, len(runner.LatencyBuckets)+1)}
+	if err := sink.Write(idle); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "otlp_idle.json", <-bodies)
+}
+
+func TestOTLPSinkReportsRejectedExports(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "metrics pipeline disabled", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	sink, err := NewOTLPSink(server.URL + "/custom/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = sink.Write(&Batch{})
+	if err == nil || err.Error() != "collector returned 503 Service Unavailable: metrics pipeline disabled" {
+		t.Errorf("error = %v, want the collector's status and message", err)
+	}
+}
+
+func TestNewOTLPSink(t *testing.T) {
+	for target, want := range map[string]string{
+		"http://localhost:4318":             "http://localhost:4318/v1/metrics",
+		"http://localhost:4318/":            "http://localhost:4318/v1/metrics",
+		"https://collector/otlp/v1/metrics": "https://collector/otlp/v1/metrics",
+		"localhost:4318":                    "",
+		"udp://localhost:4318":              "",
+	} {
+		sink, err := NewOTLPSink(target)
+		switch {
+		case want == "" && err == nil:
+			t.Errorf("NewOTLPSink(%q) succeeded, want an error", target)
+		case want != "" && err != nil:
+			t.Errorf("NewOTLPSink(%q): %v", target, err)
+		case want != "" && sink.url != want:
+			t.Errorf("NewOTLPSink(%q) exports to %q, want %q", target, sink.url, want)
+		}
+	}
+}
@@ -0,0 +1,309 @@
+package output
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/calummacc/g0/internal/runner"
+)
+
+// Sink is a metrics backend that receives aggregated metrics on every flush
+type Sink interface {
+	Name() string
+	Write(batch *Batch) error
+	Close() error
+}
+
+// Batch holds the metrics aggregated over one flush interval
+type Batch struct {
+	StartTime      time.Time // Start of the interval
+	Time           time.Time // End of the interval
+	RunID          string
+	GRPC           bool             // Status codes are gRPC codes (0 = OK)
+	Requests       []RequestCount   // Requests completed during the interval, per URL and status
+	Errors         map[string]int64 // Network errors during the interval, per error class
+	Latency        LatencyStats     // Latency of requests completed during the interval
+	LatencyBuckets []int64          // Interval histogram counts per runner.LatencyBuckets bound plus overflow
+	LatencySum     time.Duration
+	InFlight       int64
+	ActiveWorkers  int64
+}
+
+// RequestCount is the number of requests for one URL/status pair
+type RequestCount struct {
+	URL        string
+	StatusCode int // 0 = network error (OK for gRPC)
+	Count      int64
+}
+
+// LatencyStats summarises latencies observed during one interval
+// Count is 0 if no request completed during the interval
+type LatencyStats struct {
+	Count int
+	Min   time.Duration
+	Max   time.Duration
+	Avg   time.Duration
+	P50   time.Duration
+	P90   time.Duration
+	P95   time.Duration
+	P99   time.Duration
+}
+
+// ParseSink creates a sink from a --out specification such as
+// "influx=http://localhost:8086/write?db=g0", "statsd=127.0.0.1:8125" or "otlp=http://localhost:4318"
+func ParseSink(spec string) (Sink, error) {
+	kind, target, ok := strings.Cut(spec, "=")
+	if !ok || target == "" {
+		return nil, fmt.Errorf("invalid output %q (expected 'type=target', e.g. statsd=127.0.0.1:8125)", spec)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(kind)) {
+	case "influx", "influxdb":
+		return NewInfluxSink(target)
+	case "statsd":
+		return NewStatsDSink(target)
+	case "otlp":
+		return NewOTLPSink(target)
+	default:
+		return nil, fmt.Errorf("unknown output type %q (supported: influx, statsd, otlp)", kind)
+	}
+}
+
+// NewRunID generates a short random identifier used to tag metrics of one run
+func NewRunID() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
+
+// Manager periodically flushes metrics from the live Stats to all sinks
+type Manager struct {
+	sinks    []Sink
+	interval time.Duration
+	runID    string
+	stats    atomic.Pointer[runner.Stats]
+
+	// Previous flush state, only touched by the flush loop
+	prev         runner.MetricsSnapshot
+	prevTime     time.Time
+	latencyIndex int
+
+	failures map[string]*sinkFailure
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// sinkFailure tracks failed writes to a sink so they can be reported once at the end
+type sinkFailure struct {
+	count   int
+	lastErr error
+}
+
+// NewManager creates a manager that flushes to sinks every interval, tagging metrics with runID
+func NewManager(sinks []Sink, interval time.Duration, runID string) *Manager {
+	return &Manager{
+		sinks:    sinks,
+		interval: interval,
+		runID:    runID,
+		failures: make(map[string]*sinkFailure),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// SetStats sets the stats instance to read metrics from
+func (m *Manager) SetStats(stats *runner.Stats) {
+	m.stats.Store(stats)
+}
+
+// Start begins flushing in the background
+func (m *Manager) Start() {
+	m.prevTime = time.Now()
+	go func() {
+		defer close(m.done)
+
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-m.stop:
+				// Final flush so the last partial interval is not lost
+				m.flush()
+				return
+			case <-ticker.C:
+				m.flush()
+			}
+		}
+	}()
+}
+
+// Stop flushes any remaining metrics, closes all sinks and returns one error per sink
+// that failed during the run (nil if all writes succeeded)
+func (m *Manager) Stop() []error {
+	var errs []error
+	m.stopOnce.Do(func() {
+		close(m.stop)
+		<-m.done
+
+		for _, sink := range m.sinks {
+			if err := sink.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("%s output: %w", sink.Name(), err))
+			}
+		}
+		for _, sink := range m.sinks {
+			if f, ok := m.failures[sink.Name()]; ok {
+				errs = append(errs, fmt.Errorf("%s output: %d flush(es) failed, last error: %w", sink.Name(), f.count, f.lastErr))
+			}
+		}
+	})
+	return errs
+}
+
+// flush builds a batch from the change since the previous flush and writes it to every sink
+func (m *Manager) flush() {
+	stats := m.stats.Load()
+	if stats == nil {
+		return
+	}
+
+	snapshot := stats.GetMetricsSnapshot()
+	latencies, nextIndex := stats.GetLatenciesSince(m.latencyIndex)
+
+	batch := &Batch{
+		StartTime:      m.prevTime,
+		Time:           time.Now(),
+		RunID:          m.runID,
+		GRPC:           snapshot.GRPC,
+		Requests:       requestDeltas(m.prev.URLStatusCounts, snapshot.URLStatusCounts),
+		Errors:         errorDeltas(m.prev.ErrorClassCounts, snapshot.ErrorClassCounts),
+		Latency:        summarizeLatencies(latencies),
+		LatencyBuckets: bucketDeltas(m.prev.BucketCounts, snapshot.BucketCounts),
+		LatencySum:     snapshot.LatencySum - m.prev.LatencySum,
+		InFlight:       snapshot.InFlight,
+		ActiveWorkers:  snapshot.ActiveWorkers,
+	}
+
+	m.prev = snapshot
+	m.prevTime = batch.Time
+	m.latencyIndex = nextIndex
+
+	for _, sink := range m.sinks {
+		if err := sink.Write(batch); err != nil {
+			f, ok := m.failures[sink.Name()]
+			if !ok {
+				f = &sinkFailure{}
+				m.failures[sink.Name()] = f
+			}
+			f.count++
+			f.lastErr = err
+		}
+	}
+}
+
+// requestDeltas returns the non-zero per URL/status count changes, sorted by URL then status
+func requestDeltas(prev, cur map[string]map[int]int64) []RequestCount {
+	var deltas []RequestCount
+	for url, counts := range cur {
+		for code, count := range counts {
+			if delta := count - prev[url][code]; delta > 0 {
+				deltas = append(deltas, RequestCount{URL: url, StatusCode: code, Count: delta})
+			}
+		}
+	}
+	sort.Slice(deltas, func(i, j int) bool {
+		if deltas[i].URL != deltas[j].URL {
+			return deltas[i].URL < deltas[j].URL
+		}
+		return deltas[i].StatusCode < deltas[j].StatusCode
+	})
+	return deltas
+}
+
+// errorDeltas returns the non-zero per error class count changes
+func errorDeltas(prev, cur map[string]int64) map[string]int64 {
+	deltas := make(map[string]int64)
+	for class, count := range cur {
+		if delta := count - prev[class]; delta > 0 {
+			deltas[class] = delta
+		}
+	}
+	return deltas
+}
+
+// bucketDeltas returns the per-bucket histogram count changes
+func bucketDeltas(prev, cur []int64) []int64 {
+	deltas := make([]int64, len(cur))
+	for i, count := range cur {
+		deltas[i] = count
+		if i < len(prev) {
+			deltas[i] -= prev[i]
+		}
+	}
+	return deltas
+}
+
+// summarizeLatencies computes min/max/avg and percentiles for one interval
+func summarizeLatencies(latencies []time.Duration) LatencyStats {
+	if len(latencies) == 0 {
+		return LatencyStats{}
+	}
+
+	min, max := latencies[0], latencies[0]
+	var sum time.Duration
+	for _, lat := range latencies {
+		if lat < min {
+			min = lat
+		}
+		if lat > max {
+			max = lat
+		}
+		sum += lat
+	}
+
+	return LatencyStats{
+		Count: len(latencies),
+		Min:   min,
+		Max:   max,
+		Avg:   sum / time.Duration(len(latencies)),
+		P50:   runner.Percentile(latencies, 50),
+		P90:   runner.Percentile(latencies, 90),
+		P95:   runner.Percentile(latencies, 95),
+		P99:   runner.Percentile(latencies, 99),
+	}
+}
+
+// statusTag converts a status code to a tag value, using "error" for network errors (code 0)
+// gRPC codes are used as they are, since 0 is OK
+func statusTag(code int, grpc bool) string {
+	if code == 0 && !grpc {
+		return "error"
+	}
+	return strconv.Itoa(code)
+}
+
+// sortedKeys returns the keys of a count map in sorted order
+func sortedKeys(m map[string]int64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// durationMs converts a duration to fractional milliseconds
+func durationMs(d time.Duration) float64 {
+	return float64(d.Nanoseconds()) / 1000000.0
+}
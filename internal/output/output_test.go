@@ -0,0 +1,242 @@
+package output
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/calummacc/g0/internal/runner"
+)
+
+// newTestStats returns stats holding two successes, a 500 and a timeout for one URL
+func newTestStats() *runner.Stats {
+	stats := runner.NewStats()
+	url := "http://example.test/a"
+	stats.AddResult(runner.Result{URL: url, StatusCode: 200, Latency: 10 * time.Millisecond})
+	stats.AddResult(runner.Result{URL: url, StatusCode: 200, Latency: 20 * time.Millisecond})
+	stats.AddResult(runner.Result{URL: url, StatusCode: 500, Latency: 30 * time.Millisecond})
+	stats.AddResult(runner.Result{URL: url, Error: timeoutError{}, Latency: 40 * time.Millisecond})
+	return stats
+}
+
+// timeoutError is a network error that reports a timeout
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// runManager flushes stats to sink once through a manager and reports flush errors
+func runManager(t *testing.T, sink Sink, stats *runner.Stats) {
+	t.Helper()
+	m := NewManager([]Sink{sink}, time.Hour, "run1")
+	m.SetStats(stats)
+	m.Start()
+	if errs := m.Stop(); len(errs) > 0 {
+		t.Fatalf("Stop() errors = %v", errors.Join(errs...))
+	}
+}
+
+func TestInfluxSinkWritesLineProtocol(t *testing.T) {
+	bodies := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("db") != "g0" {
+			t.Errorf("query = %q, want db=g0", r.URL.RawQuery)
+		}
+		body, _ := io.ReadAll(r.Body)
+		bodies <- string(body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sink, err := NewInfluxSink(server.URL + "/write?db=g0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	runManager(t, sink, newTestStats())
+
+	var body string
+	select {
+	case body = <-bodies:
+	default:
+		t.Fatal("no write received")
+	}
+	for _, want := range []string{
+		`g0_requests,run_id=run1,url=http://example.test/a,status=200 count=2i `,
+		`g0_requests,run_id=run1,url=http://example.test/a,status=500 count=1i `,
+		`g0_requests,run_id=run1,url=http://example.test/a,status=error count=1i `,
+		`g0_errors,run_id=run1,class=timeout count=1i `,
+		`g0_latency,run_id=run1 count=4i,min=10.000,max=40.000,`,
+		`g0_load,run_id=run1 in_flight=0i,active_workers=0i `,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body is missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestInfluxSinkReportsRejectedWrites(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "database not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	sink, err := NewInfluxSink(server.URL + "/write?db=missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = sink.Write(&Batch{Time: time.Now(), RunID: "run1"})
+	if err == nil || !strings.Contains(err.Error(), "database not found") {
+		t.Fatalf("Write() error = %v, want the server's message", err)
+	}
+}
+
+func TestStatsDSinkSendsPackets(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	sink, err := NewStatsDSink(conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	runManager(t, sink, newTestStats())
+
+	var lines []string
+	buf := make([]byte, 65536)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			break
+		}
+		if n > maxStatsDPacket {
+			t.Errorf("packet of %d bytes exceeds %d", n, maxStatsDPacket)
+		}
+		lines = append(lines, strings.Split(string(buf[:n]), "\n")...)
+		// The load gauges come last in a batch
+		if strings.HasPrefix(lines[len(lines)-1], "g0.active_workers:") {
+			break
+		}
+	}
+
+	got := strings.Join(lines, "\n")
+	for _, want := range []string{
+		"g0.requests:2|c|#run_id:run1,url:http://example.test/a,status:200",
+		"g0.requests:1|c|#run_id:run1,url:http://example.test/a,status:500",
+		"g0.requests:1|c|#run_id:run1,url:http://example.test/a,status:error",
+		"g0.errors:1|c|#run_id:run1,class:timeout",
+		"g0.latency.min:10.000|g|#run_id:run1",
+		"g0.latency.max:40.000|g|#run_id:run1",
+		"g0.requests_in_flight:0|g|#run_id:run1",
+		"g0.active_workers:0|g|#run_id:run1",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("packets are missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestStatsDSinkSplitsLargeBatches(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	sink, err := NewStatsDSink(conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	batch := &Batch{Time: time.Now(), RunID: "run1"}
+	for i := 0; i < 100; i++ {
+		batch.Requests = append(batch.Requests, RequestCount{URL: "http://example.test/" + strings.Repeat("x", i), StatusCode: 200, Count: 1})
+	}
+	if err := sink.Write(batch); err != nil {
+		t.Fatal(err)
+	}
+
+	var packets, lines int
+	buf := make([]byte, 65536)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for lines < len(batch.Requests)+2 {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("received %d of %d lines: %v", lines, len(batch.Requests)+2, err)
+		}
+		if n > maxStatsDPacket {
+			t.Errorf("packet of %d bytes exceeds %d", n, maxStatsDPacket)
+		}
+		packets++
+		lines += strings.Count(string(buf[:n]), "\n") + 1
+	}
+	if packets < 2 {
+		t.Errorf("got %d packet(s), want the batch split across several", packets)
+	}
+}
+
+func TestManagerSendsDeltas(t *testing.T) {
+	stats := newTestStats()
+	m := NewManager(nil, time.Hour, "run1")
+	m.SetStats(stats)
+
+	recorder := &recordingSink{}
+	m.sinks = []Sink{recorder}
+	m.flush()
+	stats.AddResult(runner.Result{URL: "http://example.test/a", StatusCode: 200, Latency: 5 * time.Millisecond})
+	m.flush()
+
+	if len(recorder.batches) != 2 {
+		t.Fatalf("got %d batches, want 2", len(recorder.batches))
+	}
+	second := recorder.batches[1]
+	if len(second.Requests) != 1 || second.Requests[0].StatusCode != 200 || second.Requests[0].Count != 1 {
+		t.Errorf("second batch requests = %+v, want one 200", second.Requests)
+	}
+	if len(second.Errors) != 0 {
+		t.Errorf("second batch errors = %v, want none", second.Errors)
+	}
+	if second.Latency.Count != 1 {
+		t.Errorf("second batch latency count = %d, want 1", second.Latency.Count)
+	}
+}
+
+// recordingSink keeps every batch written to it
+type recordingSink struct {
+	batches []*Batch
+}
+
+func (s *recordingSink) Name() string { return "recording" }
+
+func (s *recordingSink) Write(batch *Batch) error {
+	s.batches = append(s.batches, batch)
+	return nil
+}
+
+func (s *recordingSink) Close() error { return nil }
+
+func TestStatusTag(t *testing.T) {
+	for _, tt := range []struct {
+		code int
+		grpc bool
+		want string
+	}{
+		{0, false, "error"},
+		{200, false, "200"},
+		{0, true, "0"}, // gRPC OK
+		{14, true, "14"},
+	} {
+		if got := statusTag(tt.code, tt.grpc); got != tt.want {
+			t.Errorf("statusTag(%d, %v) = %q, want %q", tt.code, tt.grpc, got, tt.want)
+		}
+	}
+}
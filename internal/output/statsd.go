@@ -0,0 +1,116 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// maxStatsDPacket keeps UDP packets below a typical Ethernet MTU
+const maxStatsDPacket = 1432
+
+// StatsDSink sends metrics to a StatsD server over UDP, using DogStatsD-style tags
+type StatsDSink struct {
+	conn net.Conn
+}
+
+// NewStatsDSink creates a StatsD sink for the given host:port
+func NewStatsDSink(target string) (*StatsDSink, error) {
+	if _, _, err := net.SplitHostPort(target); err != nil {
+		return nil, fmt.Errorf("invalid statsd address %q (expected host:port): %w", target, err)
+	}
+
+	conn, err := net.Dial("udp", target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to statsd at %s: %w", target, err)
+	}
+
+	return &StatsDSink{
+		conn: conn,
+	}, nil
+}
+
+// Name returns the sink name used in error messages
+func (s *StatsDSink) Name() string {
+	return "statsd"
+}
+
+// Write sends one batch, splitting it into as many packets as needed
+func (s *StatsDSink) Write(batch *Batch) error {
+	var packet bytes.Buffer
+	for _, line := range encodeStatsD(batch) {
+		if packet.Len() > 0 && packet.Len()+1+len(line) > maxStatsDPacket {
+			if _, err := s.conn.Write(packet.Bytes()); err != nil {
+				return err
+			}
+			packet.Reset()
+		}
+		if packet.Len() > 0 {
+			packet.WriteByte('\n')
+		}
+		packet.WriteString(line)
+	}
+
+	if packet.Len() > 0 {
+		if _, err := s.conn.Write(packet.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the UDP socket
+func (s *StatsDSink) Close() error {
+	return s.conn.Close()
+}
+
+// encodeStatsD renders a batch as StatsD lines
+// Request and error counts are counters (deltas), everything else is a gauge; latencies are in ms
+func encodeStatsD(batch *Batch) []string {
+	var lines []string
+	runTag := "run_id:" + sanitizeStatsDTag(batch.RunID)
+
+	for _, rc := range batch.Requests {
+		lines = append(lines, fmt.Sprintf("g0.requests:%d|c|#%s,url:%s,status:%s",
+			rc.Count, runTag, sanitizeStatsDTag(rc.URL), statusTag(rc.StatusCode, batch.GRPC)))
+	}
+
+	for _, class := range sortedKeys(batch.Errors) {
+		lines = append(lines, fmt.Sprintf("g0.errors:%d|c|#%s,class:%s",
+			batch.Errors[class], runTag, sanitizeStatsDTag(class)))
+	}
+
+	if lat := batch.Latency; lat.Count > 0 {
+		gauges := []struct {
+			name  string
+			value float64
+		}{
+			{"min", durationMs(lat.Min)},
+			{"max", durationMs(lat.Max)},
+			{"avg", durationMs(lat.Avg)},
+			{"p50", durationMs(lat.P50)},
+			{"p90", durationMs(lat.P90)},
+			{"p95", durationMs(lat.P95)},
+			{"p99", durationMs(lat.P99)},
+		}
+		for _, g := range gauges {
+			lines = append(lines, fmt.Sprintf("g0.latency.%s:%.3f|g|#%s", g.name, g.value, runTag))
+		}
+	}
+
+	lines = append(lines,
+		fmt.Sprintf("g0.requests_in_flight:%d|g|#%s", batch.InFlight, runTag),
+		fmt.Sprintf("g0.active_workers:%d|g|#%s", batch.ActiveWorkers, runTag),
+	)
+
+	return lines
+}
+
+// statsDTagSanitizer replaces characters that delimit tags or fields in the StatsD line format
+var statsDTagSanitizer = strings.NewReplacer(",", "_", "|", "_", "#", "_", "\n", "")
+
+// sanitizeStatsDTag makes a value safe to use as a tag value
+func sanitizeStatsDTag(value string) string {
+	return statsDTagSanitizer.Replace(value)
+}
@@ -0,0 +1,65 @@
+package printer
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/calummacc/g0/internal/capacity"
+	"github.com/calummacc/g0/internal/threshold"
+)
+
+// PrintCapacityStart prints the search configuration and the step table header
+func PrintCapacityStart(urls []string, concurrency int, stepDuration time.Duration, slo []threshold.Threshold, search string) {
+	if len(urls) == 1 {
+		fmt.Printf("Target: %s\n", urls[0])
+	} else {
+		fmt.Printf("Targets: %d URLs (round-robin)\n", len(urls))
+	}
+	exprs := make([]string, len(slo))
+	for i, t := range slo {
+		exprs[i] = t.String()
+	}
+	fmt.Printf("SLO: %s\n", strings.Join(exprs, ", "))
+	fmt.Printf("Search: %s, %s per step, %d workers\n", search, formatDurationShort(stepDuration), concurrency)
+	fmt.Println()
+	fmt.Printf("%-5s %12s %12s %10s %10s %10s %8s  %s\n", "Step", "Target RPS", "Achieved", "Requests", "p95", "p99", "Errors", "Result")
+}
+
+// PrintCapacityStepRunning shows which step is running on stderr (cleared by PrintCapacityStep)
+func PrintCapacityStepRunning(number int, rps float64, stepDuration time.Duration) {
+	fmt.Fprintf(os.Stderr, "\033[2K\rRunning step %d at %.1f RPS for %s...", number, rps, formatDurationShort(stepDuration))
+	os.Stderr.Sync()
+}
+
+// PrintCapacityStep prints one row of the step table
+func PrintCapacityStep(step capacity.Step) {
+	fmt.Fprint(os.Stderr, "\033[2K\r")
+
+	s := step.Summary
+	var errorRate float64
+	if s.TotalRequests > 0 {
+		errorRate = float64(s.FailedRequests) / float64(s.TotalRequests) * 100
+	}
+	result := "✓ pass"
+	if !step.Passed {
+		result = "✗ " + step.Reason
+	}
+	fmt.Printf("%-5d %12.1f %12.1f %10d %10s %10s %7.2f%%  %s\n", step.Number, step.TargetRPS, s.RPS,
+		s.TotalRequests, formatDuration(s.P95Latency), formatDuration(s.P99Latency), errorRate, result)
+}
+
+// PrintCapacityResult prints the highest rate that met the SLO
+func PrintCapacityResult(result *capacity.Result, maxRPS float64) {
+	fmt.Println()
+	switch {
+	case result.Best == nil:
+		fmt.Println("Capacity: SLO not met even at the start rate (try a lower --start-rps)")
+	case result.HitMaxRPS:
+		fmt.Printf("Capacity: ≥%.1f RPS (SLO still met at --max-rps %.1f, capacity may be higher)\n", result.Best.TargetRPS, maxRPS)
+	default:
+		fmt.Printf("Capacity: %.1f RPS (highest rate that met the SLO, achieved %.1f RPS, p99 %s)\n",
+			result.Best.TargetRPS, result.Best.Summary.RPS, formatDuration(result.Best.Summary.P99Latency))
+	}
+}
@@ -0,0 +1,270 @@
+package printer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/calummacc/g0/internal/runner"
+	"github.com/calummacc/g0/internal/threshold"
+)
+
+// junitTestSuites is the root element of a JUnit XML report
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite groups related test cases
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is a single pass/fail criterion
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+// junitFailure describes why a test case failed
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes a JUnit XML report with one test case per threshold, check and endpoint
+// A check test case fails if any response failed the check; endpoint test cases evaluate
+// the latency and error rate thresholds against that endpoint only
+// Returns the number of failed test cases
+func WriteJUnit(path string, summary *runner.Summary, thresholds []threshold.Threshold) (int, error) {
+	duration := fmt.Sprintf("%.3f", summary.Duration.Seconds())
+	timestamp := time.Now().Add(-summary.Duration).Format("2006-01-02T15:04:05")
+
+	thresholdSuite := junitTestSuite{Name: "thresholds", Time: duration, Timestamp: timestamp}
+	for _, r := range threshold.EvaluateAll(thresholds, threshold.SummaryValues(summary)) {
+		tc := junitTestCase{
+			ClassName: "g0.thresholds",
+			Name:      r.Threshold.String(),
+			Time:      duration,
+			SystemOut: r.Message(),
+		}
+		if !r.Passed {
+			tc.Failure = &junitFailure{Message: r.Message(), Type: "threshold", Text: r.Message()}
+		}
+		thresholdSuite.add(tc)
+	}
+
+	checkSuite := junitTestSuite{Name: "checks", Time: duration, Timestamp: timestamp}
+	for _, c := range summary.Checks {
+		tc := junitTestCase{
+			ClassName: "g0.checks",
+			Name:      c.Name,
+			Time:      duration,
+			SystemOut: checkMessage(c),
+		}
+		if c.Fails > 0 {
+			tc.Failure = &junitFailure{Message: checkMessage(c), Type: "check", Text: checkMessage(c)}
+		}
+		checkSuite.add(tc)
+	}
+
+	endpointSuite := junitTestSuite{Name: "endpoints", Time: duration, Timestamp: timestamp}
+	endpointThresholds := threshold.ForEndpoints(thresholds)
+	for i := range summary.Endpoints {
+		e := &summary.Endpoints[i]
+		results := threshold.EvaluateAll(endpointThresholds, threshold.EndpointValues(e))
+
+		var messages, failures []string
+		for _, r := range results {
+			messages = append(messages, r.Message())
+			if !r.Passed {
+				failures = append(failures, r.Message())
+			}
+		}
+
+		tc := junitTestCase{
+			ClassName: "g0.endpoints",
+			Name:      e.URL,
+			Time:      duration,
+			SystemOut: fmt.Sprintf("requests = %d, failed = %d, rps = %.1f, p95 = %s, p99 = %s\n%s",
+				e.TotalRequests, e.FailedRequests, e.RPS, formatDuration(e.P95Latency), formatDuration(e.P99Latency),
+				strings.Join(messages, "\n")),
+		}
+		if len(failures) > 0 {
+			tc.Failure = &junitFailure{Message: strings.Join(failures, "; "), Type: "endpoint", Text: strings.Join(failures, "\n")}
+		}
+		endpointSuite.add(tc)
+	}
+
+	report := junitTestSuites{Name: "g0", Time: duration}
+	for _, suite := range []junitTestSuite{thresholdSuite, checkSuite, endpointSuite} {
+		if suite.Tests == 0 {
+			continue
+		}
+		report.Suites = append(report.Suites, suite)
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+	}
+
+	data, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal JUnit XML: %w", err)
+	}
+	data = append([]byte(xml.Header), data...)
+	data = append(data, '\n')
+
+	if err := writeReportFile(path, data); err != nil {
+		return 0, err
+	}
+	return report.Failures, nil
+}
+
+// checkMessage describes the outcome of a check, with observed vs expected pass rate
+func checkMessage(c runner.CheckSummary) string {
+	return fmt.Sprintf("%d of %d responses passed (%.2f%%), expected 100%%",
+		c.Passes, c.Passes+c.Fails, c.PassRate()*100)
+}
+
+// add appends a test case and updates the suite counters
+func (s *junitTestSuite) add(tc junitTestCase) {
+	s.Cases = append(s.Cases, tc)
+	s.Tests++
+	if tc.Failure != nil {
+		s.Failures++
+	}
+}
+
+// WriteMarkdownSummary writes a Markdown summary suitable for CI job summaries
+// (e.g. appended to $GITHUB_STEP_SUMMARY)
+func WriteMarkdownSummary(path string, summary *runner.Summary, thresholds []threshold.Threshold) error {
+	var b strings.Builder
+
+	results := threshold.EvaluateAll(thresholds, threshold.SummaryValues(summary))
+	status := "✅ Passed"
+	if !threshold.AllPassed(results) {
+		status = "❌ Failed"
+	}
+
+	fmt.Fprintf(&b, "## g0 load test: %s\n\n", status)
+	b.WriteString("| Requests | Success | Failed | RPS | Avg | p90 | p95 | p99 | Max |\n")
+	b.WriteString("|---:|---:|---:|---:|---:|---:|---:|---:|---:|\n")
+	fmt.Fprintf(&b, "| %d | %d | %d | %.1f | %s | %s | %s | %s | %s |\n\n",
+		summary.TotalRequests, summary.SuccessRequests, summary.FailedRequests, summary.RPS,
+		formatDuration(summary.AvgLatency), formatDuration(summary.P90Latency), formatDuration(summary.P95Latency),
+		formatDuration(summary.P99Latency), formatDuration(summary.MaxLatency))
+
+	if len(results) > 0 {
+		b.WriteString("### Thresholds\n\n")
+		b.WriteString("| | Threshold | Observed |\n")
+		b.WriteString("|---|---|---:|\n")
+		for _, r := range results {
+			mark := "✅"
+			if !r.Passed {
+				mark = "❌"
+			}
+			fmt.Fprintf(&b, "| %s | `%s` | %s |\n", mark, r.Threshold, r.Threshold.FormatValue(r.Observed))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(summary.Checks) > 0 {
+		b.WriteString("### Checks\n\n")
+		b.WriteString("| | Check | Passed | Failed |\n")
+		b.WriteString("|---|---|---:|---:|\n")
+		for _, c := range summary.Checks {
+			mark := "✅"
+			if c.Fails > 0 {
+				mark = "❌"
+			}
+			fmt.Fprintf(&b, "| %s | `%s` | %d | %d |\n", mark, c.Name, c.Passes, c.Fails)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(summary.Endpoints) > 1 {
+		b.WriteString("### Endpoints\n\n")
+		b.WriteString("| Endpoint | Requests | Failed | RPS | Avg | p95 | p99 |\n")
+		b.WriteString("|---|---:|---:|---:|---:|---:|---:|\n")
+		for _, e := range summary.Endpoints {
+			fmt.Fprintf(&b, "| `%s` | %d | %d | %.1f | %s | %s | %s |\n",
+				e.URL, e.TotalRequests, e.FailedRequests, e.RPS,
+				formatDuration(e.AvgLatency), formatDuration(e.P95Latency), formatDuration(e.P99Latency))
+		}
+		b.WriteString("\n")
+	}
+
+	return writeReportFile(path, []byte(b.String()))
+}
+
+// PrintGitHubAnnotations prints GitHub Actions workflow commands for failed thresholds, checks and endpoints
+func PrintGitHubAnnotations(summary *runner.Summary, thresholds []threshold.Threshold) {
+	for _, r := range threshold.EvaluateAll(thresholds, threshold.SummaryValues(summary)) {
+		if !r.Passed {
+			fmt.Printf("::error title=g0 threshold failed%s::%s\n", escapeProperty(": "+r.Threshold.String()), escapeData(r.Message()))
+		}
+	}
+	for _, c := range summary.Checks {
+		if c.Fails > 0 {
+			fmt.Printf("::warning title=g0 check failed%s::%s\n", escapeProperty(": "+c.Name), escapeData(checkMessage(c)))
+		}
+	}
+
+	if len(thresholds) == 0 {
+		return
+	}
+	endpointThresholds := threshold.ForEndpoints(thresholds)
+	for i := range summary.Endpoints {
+		e := &summary.Endpoints[i]
+		for _, r := range threshold.EvaluateAll(endpointThresholds, threshold.EndpointValues(e)) {
+			if !r.Passed {
+				fmt.Printf("::warning title=%s::%s\n", escapeProperty("g0 endpoint "+e.URL), escapeData(r.Message()))
+			}
+		}
+	}
+}
+
+// Escapers for GitHub workflow command messages and properties
+var (
+	dataEscaper     = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	propertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
+
+// escapeData escapes the message part of a workflow command
+func escapeData(s string) string {
+	return dataEscaper.Replace(s)
+}
+
+// escapeProperty escapes a workflow command property value (e.g. title)
+func escapeProperty(s string) string {
+	return propertyEscaper.Replace(s)
+}
+
+// writeReportFile writes a report file, creating its directory if needed
+func writeReportFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if dir != "." && dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package printer
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/calummacc/g0/internal/runner"
+	"github.com/calummacc/g0/internal/threshold"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	summary := &runner.Summary{
+		TotalRequests:   100,
+		SuccessRequests: 98,
+		FailedRequests:  2,
+		P99Latency:      250 * time.Millisecond,
+		Duration:        10 * time.Second,
+		Checks: []runner.CheckSummary{
+			{Name: "status==200", Passes: 98, Fails: 2},
+			{Name: "header Content-Type contains json", Passes: 100},
+		},
+		Endpoints: []runner.EndpointSummary{
+			{URL: "http://example.test/a", TotalRequests: 50, SuccessRequests: 50, P99Latency: 100 * time.Millisecond},
+			{URL: "http://example.test/b", TotalRequests: 50, SuccessRequests: 48, FailedRequests: 2, P99Latency: 250 * time.Millisecond},
+		},
+	}
+	thresholds, err := threshold.ParseList("p99<300ms,errors<1%")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "reports", "g0.xml")
+	failures, err := WriteJUnit(path, summary, thresholds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// errors<1% fails, status==200 fails and endpoint b fails errors<1%
+	if failures != 3 {
+		t.Errorf("failures = %d, want 3", failures)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report junitTestSuites
+	if err := xml.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Tests != 6 || report.Failures != 3 {
+		t.Errorf("report has %d tests and %d failures, want 6 and 3", report.Tests, report.Failures)
+	}
+
+	cases := make(map[string]junitTestCase)
+	for _, suite := range report.Suites {
+		for _, tc := range suite.Cases {
+			cases[tc.ClassName+" "+tc.Name] = tc
+		}
+	}
+	for name, wantFailure := range map[string]string{
+		"g0.thresholds p99 < 300ms":                   "",
+		"g0.thresholds errors < 1%":                   "errors = 2%, expected < 1%",
+		"g0.checks status==200":                       "98 of 100 responses passed (98.00%), expected 100%",
+		"g0.checks header Content-Type contains json": "",
+		"g0.endpoints http://example.test/a":          "",
+		"g0.endpoints http://example.test/b":          "errors = 4%, expected < 1%",
+	} {
+		tc, ok := cases[name]
+		switch {
+		case !ok:
+			t.Errorf("missing testcase %q", name)
+		case wantFailure == "" && tc.Failure != nil:
+			t.Errorf("testcase %q failed: %s", name, tc.Failure.Message)
+		case wantFailure != "" && (tc.Failure == nil || !strings.Contains(tc.Failure.Message, wantFailure)):
+			t.Errorf("testcase %q failure = %+v, want %q", name, tc.Failure, wantFailure)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package printer
+
+import (
+	"fmt"
+
+	"github.com/calummacc/g0/internal/grpcload"
+	"github.com/calummacc/g0/internal/httpclient"
+	"github.com/calummacc/g0/internal/threshold"
+)
+
+// JSONGRPCSettings contains the connection settings of a gRPC test
+type JSONGRPCSettings struct {
+	Method      string `json:"method"` // Full method name, e.g. /pkg.Service/Method
+	Connections int    `json:"connections"`
+	CallTimeout string `json:"call_timeout"`
+	Plaintext   bool   `json:"plaintext,omitempty"`
+	Insecure    bool   `json:"insecure,omitempty"`
+}
+
+// JSONGRPCStream describes the messages of server-streaming gRPC calls
+type JSONGRPCStream struct {
+	Messages       int64        `json:"messages"`
+	MessagesPerSec float64      `json:"messages_per_sec"`
+	PerCall        float64      `json:"messages_per_call"`
+	FirstMessage   *JSONLatency `json:"time_to_first_message"`
+	MessageLatency *JSONLatency `json:"message_latency"` // Since the previous message
+}
+
+// PrintGRPCStart prints the gRPC test configuration
+func PrintGRPCStart(config grpcload.Config) {
+	fmt.Println("gRPC Load Test Started")
+	fmt.Printf("Target: %s\n", config.Target)
+	fmt.Printf("Method: %s\n", config.Method)
+	fmt.Printf("Concurrency: %d (over %d connection(s))\n", config.Concurrency, max(config.Connections, 1))
+	fmt.Printf("Duration: %s\n", config.Duration)
+	fmt.Println()
+}
+
+// PrintGRPCStream prints the per-message statistics of a server-streaming method
+func PrintGRPCStream(stream *grpcload.StreamSummary) {
+	fmt.Println()
+	fmt.Printf("Stream Messages: %d (%.1f/s, %.1f per call)\n", stream.Messages, stream.MessagesPerSec, stream.PerCall)
+	if stream.Messages == 0 {
+		return
+	}
+	f := stream.FirstMessage
+	fmt.Printf("  First message: avg %s, p99 %s, max %s\n", formatDuration(f.Avg), formatDuration(f.P99), formatDuration(f.Max))
+	m := stream.MessageLatency
+	fmt.Println("  Per-message latency (since the previous message):")
+	fmt.Printf("    Min: %s | Avg: %s | Max: %s\n", formatDuration(m.Min), formatDuration(m.Avg), formatDuration(m.Max))
+	fmt.Printf("    p90: %s | p95: %s | p99: %s\n", formatDuration(m.P90), formatDuration(m.P95), formatDuration(m.P99))
+}
+
+// BuildGRPCResultsJSON builds the JSON report of a gRPC test
+// Status codes are gRPC codes, so "0" counts successful calls
+func BuildGRPCResultsJSON(result *grpcload.RunResult, config grpcload.Config, thresholds []threshold.Result) JSONOutput {
+	output := BuildResultsJSON(result.Summary, []string{config.Target}, config.Concurrency, config.Duration, "gRPC", config.Metadata, httpclient.TransportOptions{}, thresholds)
+	output.Metadata.Transport = nil
+	output.Metadata.GRPC = &JSONGRPCSettings{
+		Method:      result.Method,
+		Connections: max(config.Connections, 1),
+		CallTimeout: config.CallTimeout.String(),
+		Plaintext:   config.Plaintext,
+		Insecure:    config.Insecure,
+	}
+	if s := result.Stream; s != nil {
+		output.Metrics.GRPCStream = &JSONGRPCStream{
+			Messages:       s.Messages,
+			MessagesPerSec: s.MessagesPerSec,
+			PerCall:        s.PerCall,
+			FirstMessage:   distributionToJSON(s.FirstMessage),
+			MessageLatency: distributionToJSON(s.MessageLatency),
+		}
+	}
+	return output
+}
+
+// PrintGRPCResultsJSON saves the JSON report of a gRPC test like PrintResultsJSON
+// Returns the file path where JSON was saved
+func PrintGRPCResultsJSON(result *grpcload.RunResult, config grpcload.Config, thresholds []threshold.Result, outputFile string) (string, error) {
+	return writeResultsJSON(BuildGRPCResultsJSON(result, config, thresholds), outputFile)
+}
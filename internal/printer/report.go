@@ -1,14 +1,21 @@
 package printer
 
 import (
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/calummacc/g0/internal/distributed"
+	"github.com/calummacc/g0/internal/graphql"
+	"github.com/calummacc/g0/internal/httpclient"
 	"github.com/calummacc/g0/internal/runner"
+	"github.com/calummacc/g0/internal/threshold"
+	"google.golang.org/grpc/codes"
 )
 
 // PrintLogo prints the g0 logo
@@ -25,7 +32,7 @@ func PrintLogo() {
 }
 
 // PrintTestStart prints the test configuration
-func PrintTestStart(urls []string, concurrency int, duration time.Duration) {
+func PrintTestStart(urls []string, concurrency int, duration time.Duration, warmup time.Duration) {
 	fmt.Println("Load Test Started")
 	if len(urls) == 1 {
 		fmt.Printf("URL: %s\n", urls[0])
@@ -37,6 +44,31 @@ func PrintTestStart(urls []string, concurrency int, duration time.Duration) {
 	}
 	fmt.Printf("Concurrency: %d\n", concurrency)
 	fmt.Printf("Duration: %s\n", duration)
+	if warmup > 0 {
+		fmt.Printf("Warm-up: %s (excluded from results)\n", warmup)
+	}
+	fmt.Println()
+}
+
+// PrintGraphQLOperations prints the GraphQL operations sent in turn
+func PrintGraphQLOperations(operations []graphql.Operation) {
+	fmt.Printf("GraphQL Operations (%d):\n", len(operations))
+	for _, op := range operations {
+		fmt.Printf("  %s (%s)\n", op.Name, op.Type)
+	}
+	fmt.Println()
+}
+
+// PrintAgents prints the share of the load generated by each agent
+func PrintAgents(assignments []distributed.Assignment) {
+	fmt.Printf("Agents (%d):\n", len(assignments))
+	for _, a := range assignments {
+		if a.MaxRPS > 0 {
+			fmt.Printf("  %s: %d workers, %.1f req/s\n", a.Agent, a.Concurrency, a.MaxRPS)
+		} else {
+			fmt.Printf("  %s: %d workers\n", a.Agent, a.Concurrency)
+		}
+	}
 	fmt.Println()
 }
 
@@ -49,7 +81,11 @@ func PrintResults(summary *runner.Summary) {
 	fmt.Printf("RPS: %.1f\n", summary.RPS)
 	fmt.Println()
 
-	fmt.Println("Latency:")
+	if summary.ResponseTime == nil {
+		fmt.Println("Latency:")
+	} else {
+		fmt.Println("Latency (service time):")
+	}
 	fmt.Printf("  Min: %s\n", formatDuration(summary.MinLatency))
 	fmt.Printf("  Avg: %s\n", formatDuration(summary.AvgLatency))
 	fmt.Printf("  Max: %s\n", formatDuration(summary.MaxLatency))
@@ -57,14 +93,252 @@ func PrintResults(summary *runner.Summary) {
 	fmt.Printf("  p95: %s\n", formatDuration(summary.P95Latency))
 	fmt.Printf("  p99: %s\n", formatDuration(summary.P99Latency))
 
+	// With a rate limit, also show latency measured from the intended send time
+	if rt := summary.ResponseTime; rt != nil {
+		fmt.Println()
+		fmt.Println("Response Time (from intended send time, corrected for coordinated omission):")
+		fmt.Printf("  Min: %s\n", formatDuration(rt.Min))
+		fmt.Printf("  Avg: %s\n", formatDuration(rt.Avg))
+		fmt.Printf("  Max: %s\n", formatDuration(rt.Max))
+		fmt.Printf("  p90: %s\n", formatDuration(rt.P90))
+		fmt.Printf("  p95: %s\n", formatDuration(rt.P95))
+		fmt.Printf("  p99: %s\n", formatDuration(rt.P99))
+	}
+
+	// In streaming mode, show event timings of the response bodies
+	if st := summary.Stream; st != nil {
+		fmt.Println()
+		switch st.SSE {
+		case st.Responses:
+			fmt.Printf("Streaming (%d responses, Server-Sent Events):\n", st.Responses)
+		case 0:
+			fmt.Printf("Streaming (%d responses, body chunks as events):\n", st.Responses)
+		default:
+			fmt.Printf("Streaming (%d responses, %d Server-Sent Events, %d chunked):\n", st.Responses, st.SSE, st.Responses-st.SSE)
+		}
+		e := st.EventsPerResponse
+		fmt.Printf("  Events: %d (per response: min %d, avg %.1f, p50 %.0f, p90 %.0f, p99 %.0f, max %d)\n", st.Events, e.Min, e.Avg, e.P50, e.P90, e.P99, e.Max)
+		printStreamDistribution("Time to first event", st.FirstEvent)
+		printStreamDistribution("Inter-event gap", st.Gap)
+		printStreamDistribution("Stream duration", st.Duration)
+	}
+
+	// Show the excluded warm-up results separately
+	if w := summary.Warmup; w != nil {
+		fmt.Println()
+		fmt.Printf("Warm-up (%s, excluded from results):\n", formatDurationShort(w.Duration))
+		fmt.Printf("  Requests: %d (✓ %d, ✗ %d) | RPS: %.1f\n", w.TotalRequests, w.SuccessRequests, w.FailedRequests, w.RPS)
+		fmt.Printf("  Min: %s | Avg: %s | Max: %s | p99: %s\n",
+			formatDuration(w.Latency.Min), formatDuration(w.Latency.Avg), formatDuration(w.Latency.Max), formatDuration(w.Latency.P99))
+	}
+
+	// Show connection reuse, warning about churn
+	if c := summary.Connections; c != nil {
+		fmt.Println()
+		fmt.Println("Connections:")
+		fmt.Printf("  New: %d | Reused: %d (%.1f%% reuse) | Requests/conn: %.1f\n", c.New, c.Reused, c.ReuseRate*100, c.RequestsPerConn)
+		if c.Reused > 0 {
+			fmt.Printf("  Idle before reuse: avg %s, p99 %s, max %s\n",
+				formatDuration(c.IdleBeforeReuse.Avg), formatDuration(c.IdleBeforeReuse.P99), formatDuration(c.IdleBeforeReuse.Max))
+		}
+		if c.Closed > 0 {
+			fmt.Printf("  Closed during run: %d (lifetime avg %s, min %s, max %s)\n",
+				c.Closed, formatDuration(c.Lifetime.Avg), formatDuration(c.Lifetime.Min), formatDuration(c.Lifetime.Max))
+		}
+		if c.LowReuse {
+			fmt.Printf("  ⚠ Low connection reuse: %d new connections opened; keep-alive connections are being closed (check server keep-alive limits and timeouts)\n", c.New)
+		}
+	}
+
+	// Show negotiated TLS versions and cipher suites
+	if t := summary.TLS; t != nil {
+		fmt.Println()
+		fmt.Printf("TLS (%d handshakes):\n", t.Handshakes)
+		fmt.Println("  Versions:")
+		for _, name := range sortedByCount(t.Versions) {
+			fmt.Printf("    %s: %d (%.1f%%)\n", name, t.Versions[name], float64(t.Versions[name])/float64(t.Handshakes)*100)
+		}
+		fmt.Println("  Cipher Suites:")
+		for _, name := range sortedByCount(t.CipherSuites) {
+			fmt.Printf("    %s: %d (%.1f%%)\n", name, t.CipherSuites[name], float64(t.CipherSuites[name])/float64(t.Handshakes)*100)
+		}
+	}
+
+	// Show how many responses used each HTTP protocol
+	if len(summary.Protocols) > 0 {
+		var responses int64
+		for _, count := range summary.Protocols {
+			responses += count
+		}
+		fmt.Println()
+		fmt.Println("Protocols:")
+		for _, name := range sortedByCount(summary.Protocols) {
+			fmt.Printf("  %s: %d (%.1f%%)\n", name, summary.Protocols[name], float64(summary.Protocols[name])/float64(responses)*100)
+		}
+	}
+
+	// With HTTP/3, show QUIC handshake times and session resumption
+	if q := summary.QUIC; q != nil {
+		fmt.Println()
+		fmt.Printf("QUIC (%d handshakes):\n", q.Handshakes)
+		fmt.Printf("  Handshake time: avg %s, p99 %s, max %s\n",
+			formatDuration(q.HandshakeTime.Avg), formatDuration(q.HandshakeTime.P99), formatDuration(q.HandshakeTime.Max))
+		fmt.Printf("  Resumed: %d (%.1f%%) | 0-RTT accepted: %d (%.1f%%)\n",
+			q.Resumed, float64(q.Resumed)/float64(q.Handshakes)*100, q.ZeroRTT, float64(q.ZeroRTT)/float64(q.Handshakes)*100)
+	}
+
+	// With a rate limit, show how requests were actually spaced
+	if p := summary.Pacing; p != nil {
+		fmt.Println()
+		fmt.Printf("Pacing (%s arrivals):\n", p.Arrival)
+		fmt.Printf("  Target Rate: %.1f/s\n", p.TargetRate)
+		fmt.Printf("  Achieved Rate: %.1f/s\n", p.AchievedRate)
+		fmt.Printf("  Inter-arrival: mean %s, std dev %s (CV %.2f)\n", formatDuration(p.GapMean), formatDuration(p.GapStdDev), p.GapCV)
+		if p.Missed > 0 {
+			fmt.Printf("  Missed Slots: %d (the schedule fell behind; counted in the response time)\n", p.Missed)
+		}
+	}
+
+	// With adaptive rate control, show the rate the target sustained
+	if a := summary.Adaptive; a != nil {
+		fmt.Println()
+		fmt.Println("Adaptive Rate Control:")
+		if a.Backoffs > 0 {
+			fmt.Printf("  Sustainable Rate: %.1f/s (held before each of %d backoff(s))\n", a.SustainableRate, a.Backoffs)
+		} else {
+			fmt.Printf("  Sustainable Rate: ≥%.1f/s (never backed off)\n", a.MaxRate)
+		}
+		fmt.Printf("  Final Rate: %.1f/s (max %.1f/s, lowest %.1f/s)\n", a.FinalRate, a.MaxRate, a.LowestRate)
+		if a.Pauses > 0 {
+			fmt.Printf("  Retry-After Pauses: %d (%s total)\n", a.Pauses, formatDuration(a.PausedFor))
+		}
+	}
+
 	// Print status code distribution if there are any
 	if len(summary.StatusCodeCounts) > 0 {
 		fmt.Println()
 		fmt.Println("Status Codes:")
 		for code, count := range summary.StatusCodeCounts {
-			fmt.Printf("  %d: %d\n", code, count)
+			if summary.GRPC {
+				fmt.Printf("  %d %s: %d\n", code, codes.Code(code), count)
+			} else {
+				fmt.Printf("  %d: %d\n", code, count)
+			}
+		}
+	}
+
+	// Print network errors by class with their most frequent messages
+	if len(summary.Errors) > 0 {
+		fmt.Println()
+		fmt.Println("Errors:")
+		for _, e := range summary.Errors {
+			fmt.Printf("  %s: %d\n", e.Class, e.Count)
+			for _, m := range e.Messages {
+				fmt.Printf("    %d × %s (first seen %s)\n", m.Count, m.Message, m.FirstSeen.Format("15:04:05.000"))
+			}
+		}
+	}
+
+	// Print per-endpoint breakdown when testing multiple URLs
+	if len(summary.Endpoints) > 1 {
+		fmt.Println()
+		fmt.Println("Endpoints:")
+		for _, e := range summary.Endpoints {
+			fmt.Printf("  %s\n", e.URL)
+			fmt.Printf("    Requests: %d (✓ %d, ✗ %d) | RPS: %.1f | Avg: %s | p95: %s | p99: %s\n",
+				e.TotalRequests, e.SuccessRequests, e.FailedRequests, e.RPS,
+				formatDuration(e.AvgLatency), formatDuration(e.P95Latency), formatDuration(e.P99Latency))
 		}
 	}
+
+	// Print per-operation breakdown for GraphQL
+	if len(summary.Operations) > 0 {
+		fmt.Println()
+		fmt.Println("Operations:")
+		for _, o := range summary.Operations {
+			fmt.Printf("  %s\n", o.Name)
+			fmt.Printf("    Requests: %d (✓ %d, ✗ %d, %d with GraphQL errors) | RPS: %.1f | Avg: %s | p95: %s | p99: %s\n",
+				o.TotalRequests, o.SuccessRequests, o.FailedRequests, o.GraphQLErrors, o.RPS,
+				formatDuration(o.Latency.Avg), formatDuration(o.Latency.P95), formatDuration(o.Latency.P99))
+		}
+	}
+
+	// Print how many responses passed each check
+	if len(summary.Checks) > 0 {
+		fmt.Println()
+		fmt.Println("Checks:")
+		for _, c := range summary.Checks {
+			mark := "✓"
+			if c.Fails > 0 {
+				mark = "✗"
+			}
+			fmt.Printf("  %s %s: %d passed, %d failed (%.2f%%)\n", mark, c.Name, c.Passes, c.Fails, c.PassRate()*100)
+		}
+	}
+
+	// Print trace IDs of sampled slow/failed requests if tracing was enabled
+	if len(summary.SlowTraces) > 0 || len(summary.FailedTraces) > 0 {
+		fmt.Println()
+		fmt.Println("Traces (sampled):")
+		if len(summary.SlowTraces) > 0 {
+			fmt.Println("  Slowest:")
+			for _, t := range summary.SlowTraces {
+				fmt.Printf("    %s  %s  %s  trace_id=%s\n", formatDuration(t.Latency), statusText(t.StatusCode, false), t.URL, t.TraceID)
+			}
+		}
+		if len(summary.FailedTraces) > 0 {
+			fmt.Println("  Failed:")
+			for _, t := range summary.FailedTraces {
+				fmt.Printf("    %s  %s  %s  trace_id=%s\n", formatDuration(t.Latency), statusText(t.StatusCode, false), t.URL, t.TraceID)
+			}
+		}
+	}
+
+	// Show the load generator's own resource usage, warning if it likely limited the results
+	if h := summary.Health; h != nil {
+		printHealth(h)
+	}
+}
+
+// PrintThresholds prints the outcome of each threshold
+func PrintThresholds(results []threshold.Result) {
+	if len(results) == 0 {
+		return
+	}
+
+	fmt.Println()
+	fmt.Println("Thresholds:")
+	for _, r := range results {
+		mark := "✓"
+		if !r.Passed {
+			mark = "✗"
+		}
+		fmt.Printf("  %s %s (%s)\n", mark, r.Threshold, r.Message())
+	}
+}
+
+// sortedByCount returns the keys of a count map, most frequent first
+func sortedByCount(counts map[string]int64) []string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// statusText formats a status code for display, using "error" for network errors (code 0)
+// gRPC codes are shown as they are, since 0 is OK
+func statusText(code int, grpc bool) string {
+	if code == 0 && !grpc {
+		return "error"
+	}
+	return fmt.Sprintf("%d", code)
 }
 
 // PrintProgress displays a progress bar with current test statistics
@@ -83,10 +357,14 @@ func PrintProgress(elapsed time.Duration, totalDuration time.Duration, stats *ru
 	filled := int(progress * float64(barWidth))
 	bar := strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
 
-	// Calculate current RPS
+	// Calculate current RPS over the current period (warm-up or measurement)
+	rpsElapsed := elapsed
+	if stats.Elapsed > 0 {
+		rpsElapsed = stats.Elapsed
+	}
 	var rps float64
-	if elapsed > 0 {
-		rps = float64(stats.TotalRequests) / elapsed.Seconds()
+	if rpsElapsed > 0 {
+		rps = float64(stats.TotalRequests) / rpsElapsed.Seconds()
 	}
 
 	// Format elapsed time
@@ -107,8 +385,12 @@ func PrintProgress(elapsed time.Duration, totalDuration time.Duration, stats *ru
 	} else {
 		// Print progress on the same line (using clearLine to clear and return to start)
 		// Add spaces at the end to clear any remaining characters from previous updates
-		fmt.Fprintf(os.Stderr, "%s[%s] %.1f%% | %s/%s | Req: %d | ✓: %d | ✗: %d | RPS: %.1f   ",
-			clearLine, bar, progress*100, elapsedStr, totalStr,
+		phase := ""
+		if stats.WarmingUp {
+			phase = " | Warm-up"
+		}
+		fmt.Fprintf(os.Stderr, "%s[%s] %.1f%% | %s/%s%s | Req: %d | ✓: %d | ✗: %d | RPS: %.1f   ",
+			clearLine, bar, progress*100, elapsedStr, totalStr, phase,
 			stats.TotalRequests, stats.SuccessRequests, stats.FailedRequests, rps)
 	}
 
@@ -159,28 +441,302 @@ func formatDurationShort(d time.Duration) string {
 
 // JSONOutput represents the JSON structure for test results
 type JSONOutput struct {
-	Metadata JSONMetadata `json:"metadata"`
-	Metrics  JSONMetrics  `json:"metrics"`
+	Metadata   JSONMetadata    `json:"metadata"`
+	Metrics    JSONMetrics     `json:"metrics"`
+	Endpoints  []JSONEndpoint  `json:"endpoints,omitempty"`
+	Operations []JSONOperation `json:"operations,omitempty"` // Per GraphQL operation
+	Checks     []JSONCheck     `json:"checks,omitempty"`
+	Thresholds []JSONThreshold `json:"thresholds,omitempty"`
+}
+
+// JSONEndpoint contains the metrics of a single URL
+type JSONEndpoint struct {
+	URL         string           `json:"url"`
+	Requests    JSONRequests     `json:"requests"`
+	Latency     JSONLatency      `json:"latency"`
+	StatusCodes map[string]int64 `json:"status_codes"`
+}
+
+// JSONOperation contains the metrics of a single GraphQL operation
+type JSONOperation struct {
+	Name          string       `json:"name"`
+	Requests      JSONRequests `json:"requests"`
+	GraphQLErrors int64        `json:"graphql_errors"` // Failed requests whose 2xx response carried GraphQL errors
+	Latency       JSONLatency  `json:"latency"`
+}
+
+// printStreamDistribution prints one event timing of the streaming section
+func printStreamDistribution(name string, d runner.LatencyDistribution) {
+	fmt.Printf("  %s:\n", name)
+	fmt.Printf("    Min: %s | Avg: %s | Max: %s\n", formatDuration(d.Min), formatDuration(d.Avg), formatDuration(d.Max))
+	fmt.Printf("    p90: %s | p95: %s | p99: %s\n", formatDuration(d.P90), formatDuration(d.P95), formatDuration(d.P99))
+}
+
+// printHealth prints the generator health section
+func printHealth(h *runner.HealthSummary) {
+	fmt.Println()
+	fmt.Println("Generator Health:")
+	if h.CPUSamples > 0 {
+		fmt.Printf("  CPU: avg %.1f%%, max %.1f%% of %d core(s) | Goroutines: max %d\n", h.CPUAvg*100, h.CPUMax*100, h.Cores, h.MaxGoroutines)
+	} else {
+		fmt.Printf("  Cores: %d | Goroutines: max %d\n", h.Cores, h.MaxGoroutines)
+	}
+	fmt.Printf("  GC: %d cycles, %s paused (%.1f%% of the run, max %s)\n",
+		h.GCCycles, formatDuration(h.GCPauseTotal), h.GCPauseShare*100, formatDuration(h.GCPauseMax))
+	if h.MaxOpenFiles > 0 {
+		if h.FileLimit > 0 {
+			fmt.Printf("  Open files: max %d (limit %d)\n", h.MaxOpenFiles, h.FileLimit)
+		} else {
+			fmt.Printf("  Open files: max %d\n", h.MaxOpenFiles)
+		}
+	}
+	fmt.Printf("  Scheduler lag: avg %s, p99 %s, max %s\n",
+		formatDuration(h.SchedulerLag.Avg), formatDuration(h.SchedulerLag.P99), formatDuration(h.SchedulerLag.Max))
+	if h.TokenLag != nil {
+		fmt.Printf("  Rate limiter lag: avg %s, p99 %s, max %s\n",
+			formatDuration(h.TokenLag.Avg), formatDuration(h.TokenLag.P99), formatDuration(h.TokenLag.Max))
+	}
+	fmt.Printf("  Worker loop lag: avg %s, p99 %s, max %s\n",
+		formatDuration(h.LoopLag.Avg), formatDuration(h.LoopLag.P99), formatDuration(h.LoopLag.Max))
+
+	warnings := healthWarnings(h)
+	for _, w := range warnings {
+		fmt.Printf("  ⚠ %s\n", w)
+	}
+	if len(warnings) == 0 {
+		fmt.Println("  ✓ No sign of the load generator limiting the results")
+	}
+}
+
+// healthWarnings explains each way the load generator likely limited the results
+func healthWarnings(h *runner.HealthSummary) []string {
+	var warnings []string
+	if h.CPUBound {
+		warnings = append(warnings, fmt.Sprintf("g0 used %.0f%% of its %d CPU core(s) on average; throughput and latency are likely limited by the load generator (use fewer workers, a bigger machine or --agents)", h.CPUAvg*100, h.Cores))
+	}
+	if h.OutOfFiles {
+		warnings = append(warnings, "Requests failed with \"too many open files\"; raise the open file limit (ulimit -n) or lower concurrency")
+	} else if h.FilesNearLimit {
+		warnings = append(warnings, fmt.Sprintf("Open files reached %d of the %d limit; raise it (ulimit -n) or lower concurrency", h.MaxOpenFiles, h.FileLimit))
+	}
+	if h.GCHeavy {
+		warnings = append(warnings, fmt.Sprintf("Garbage collection paused g0 for %.1f%% of the run; measured latencies include these pauses", h.GCPauseShare*100))
+	}
+	if h.SchedulerBusy {
+		warnings = append(warnings, fmt.Sprintf("Goroutines were woken up late (p99 %s); g0 is short of CPU time, so latencies include time it spent waiting to run", formatDuration(h.SchedulerLag.P99)))
+	}
+	if h.TokenLagHigh {
+		warnings = append(warnings, fmt.Sprintf("Rate limiter slots were released late (p99 %s); the achieved rate and response times are skewed by the load generator", formatDuration(h.TokenLag.P99)))
+	}
+	if h.LoopLagHigh {
+		warnings = append(warnings, fmt.Sprintf("Workers were held up between requests (p99 %s); the load generator cannot keep up with the results", formatDuration(h.LoopLag.P99)))
+	}
+	return warnings
+}
+
+// JSONCheck counts the responses that passed and failed one check
+type JSONCheck struct {
+	Check    string  `json:"check"`
+	Passes   int64   `json:"passes"`
+	Fails    int64   `json:"fails"`
+	PassRate float64 `json:"pass_rate"`
+}
+
+// JSONThreshold contains the outcome of one threshold
+type JSONThreshold struct {
+	Threshold string  `json:"threshold"`
+	Metric    string  `json:"metric"`
+	Observed  float64 `json:"observed"`
+	Expected  float64 `json:"expected"`
+	Passed    bool    `json:"passed"`
 }
 
 // JSONMetadata contains test configuration and timing information
 type JSONMetadata struct {
-	URL         string            `json:"url,omitempty"`         // Single URL (if only one)
-	URLs        []string          `json:"urls,omitempty"`        // Multiple URLs (if more than one)
+	URL         string            `json:"url,omitempty"`  // Single URL (if only one)
+	URLs        []string          `json:"urls,omitempty"` // Multiple URLs (if more than one)
 	Method      string            `json:"method"`
 	Concurrency int               `json:"concurrency"`
 	Duration    string            `json:"duration"`
+	Warmup      string            `json:"warmup,omitempty"`    // Excluded warm-up period before the duration
+	Transport   *JSONTransport    `json:"transport,omitempty"` // HTTP transport settings (not for gRPC)
+	GRPC        *JSONGRPCSettings `json:"grpc,omitempty"`      // Connection settings of a gRPC test
 	DurationMs  int64             `json:"duration_ms"`
 	Headers     map[string]string `json:"headers,omitempty"`
 	StartTime   string            `json:"start_time,omitempty"`
 	EndTime     string            `json:"end_time,omitempty"`
 }
 
+// JSONTransport contains the HTTP transport settings used for the run
+type JSONTransport struct {
+	Timeout             string           `json:"timeout"`
+	ConnectTimeout      string           `json:"connect_timeout"`
+	TLSHandshakeTimeout string           `json:"tls_handshake_timeout"`
+	MaxIdleConnsPerHost int              `json:"max_idle_conns_per_host"`
+	MaxConnsPerHost     int              `json:"max_conns_per_host"` // 0 = unlimited
+	KeepAlive           bool             `json:"keep_alive"`
+	Redirects           string           `json:"redirects"`                // "none" or "follow (max N)"
+	Protocol            string           `json:"protocol"`                 // "auto", "1.1", "2" or "h2c"
+	H2Conns             int              `json:"h2_conns,omitempty"`       // HTTP/2 connections per host (only with protocol 2 or h2c)
+	H2MaxStreams        int              `json:"h2_max_streams,omitempty"` // Streams per HTTP/2 connection (0 = server limit)
+	TLS                 *JSONTLSSettings `json:"tls,omitempty"`
+}
+
+// JSONTLSSettings contains the TLS options used for the run (only non-default ones are set)
+type JSONTLSSettings struct {
+	CACert       string   `json:"ca_cert,omitempty"`
+	ClientCert   string   `json:"client_cert,omitempty"`
+	Insecure     bool     `json:"insecure,omitempty"`
+	ServerName   string   `json:"server_name,omitempty"`
+	MinVersion   string   `json:"min_version,omitempty"`
+	MaxVersion   string   `json:"max_version,omitempty"`
+	CipherSuites []string `json:"cipher_suites,omitempty"`
+}
+
 // JSONMetrics contains all test metrics
 type JSONMetrics struct {
-	Requests    JSONRequests     `json:"requests"`
-	Latency     JSONLatency      `json:"latency"`
-	StatusCodes map[string]int64 `json:"status_codes"`
+	Requests     JSONRequests     `json:"requests"`
+	Latency      JSONLatency      `json:"latency"`
+	ResponseTime *JSONLatency     `json:"response_time,omitempty"` // From the intended send time (only with --max-rps)
+	Pacing       *JSONPacing      `json:"pacing,omitempty"`        // Achieved rate and inter-arrival gaps (only with --max-rps)
+	Warmup       *JSONWarmup      `json:"warmup,omitempty"`        // Results excluded as warm-up (only with --warmup)
+	Connections  *JSONConnections `json:"connections,omitempty"`
+	TLS          *JSONTLS         `json:"tls,omitempty"`
+	Protocols    map[string]int64 `json:"protocols,omitempty"`   // Responses per negotiated HTTP protocol
+	QUIC         *JSONQUIC        `json:"quic,omitempty"`        // QUIC handshakes (only with --http 3)
+	Stream       *JSONStream      `json:"stream,omitempty"`      // Event timings of streamed responses (only with --stream)
+	GRPCStream   *JSONGRPCStream  `json:"grpc_stream,omitempty"` // Messages of server-streaming gRPC calls
+	Adaptive     *JSONAdaptive    `json:"adaptive,omitempty"`    // Adaptive rate control outcome (only with --adaptive)
+	Health       *JSONHealth      `json:"health,omitempty"`      // Resource usage of the load generator
+	StatusCodes  map[string]int64 `json:"status_codes"`
+	Errors       []JSONErrorClass `json:"errors,omitempty"`
+	Traces       *JSONTraces      `json:"traces,omitempty"`
+}
+
+// JSONTLS counts successful TLS handshakes by negotiated version and cipher suite
+type JSONTLS struct {
+	Handshakes   int64            `json:"handshakes"`
+	Versions     map[string]int64 `json:"versions"`
+	CipherSuites map[string]int64 `json:"cipher_suites"`
+}
+
+// JSONQUIC describes the QUIC handshakes of an HTTP/3 run
+type JSONQUIC struct {
+	Handshakes    int64        `json:"handshakes"`
+	HandshakeTime *JSONLatency `json:"handshake_time"`
+	Resumed       int64        `json:"resumed"`
+	ZeroRTT       int64        `json:"zero_rtt"` // Handshakes whose 0-RTT early data was accepted
+}
+
+// JSONStream describes the events of successful responses read in streaming mode
+type JSONStream struct {
+	Responses         int64        `json:"responses"`
+	SSEResponses      int64        `json:"sse_responses"` // The rest count body chunks as events
+	Events            int64        `json:"events"`
+	EventsPerResponse JSONCounts   `json:"events_per_response"`
+	FirstEvent        *JSONLatency `json:"time_to_first_event"`
+	Gap               *JSONLatency `json:"inter_event_gap"`
+	Duration          *JSONLatency `json:"duration"`
+}
+
+// JSONCounts summarises a set of counts
+type JSONCounts struct {
+	Min int64   `json:"min"`
+	Max int64   `json:"max"`
+	Avg float64 `json:"avg"`
+	P50 float64 `json:"p50"`
+	P90 float64 `json:"p90"`
+	P99 float64 `json:"p99"`
+}
+
+// JSONConnections describes connection reuse during the run
+type JSONConnections struct {
+	New             int64        `json:"new"`
+	Reused          int64        `json:"reused"`
+	ReuseRate       float64      `json:"reuse_rate"`
+	RequestsPerConn float64      `json:"requests_per_connection"`
+	IdleBeforeReuse *JSONLatency `json:"idle_before_reuse,omitempty"`
+	Closed          int64        `json:"closed"`
+	Lifetime        *JSONLatency `json:"lifetime,omitempty"` // Of connections closed during the run
+	LowReuse        bool         `json:"low_reuse"`
+}
+
+// JSONWarmup contains the results excluded as warm-up
+type JSONWarmup struct {
+	Duration string       `json:"duration"`
+	Requests JSONRequests `json:"requests"`
+	Latency  JSONLatency  `json:"latency"`
+}
+
+// JSONPacing describes how requests were actually spaced under a rate limit
+type JSONPacing struct {
+	Arrival     string       `json:"arrival"`
+	TargetRPS   float64      `json:"target_rps"`
+	AchievedRPS float64      `json:"achieved_rps"`
+	GapMean     JSONDuration `json:"interarrival_mean"`
+	GapStdDev   JSONDuration `json:"interarrival_stddev"`
+	GapVariance float64      `json:"interarrival_variance_ms2"`
+	GapCV       float64      `json:"interarrival_cv"`
+	Missed      int64        `json:"missed_slots"` // Slots dropped because the schedule fell behind
+}
+
+// JSONAdaptive describes how adaptive rate control behaved
+type JSONAdaptive struct {
+	SustainableRPS float64      `json:"sustainable_rps"` // 0 if the rate never backed off
+	MaxRPS         float64      `json:"max_rps"`
+	FinalRPS       float64      `json:"final_rps"`
+	LowestRPS      float64      `json:"lowest_rps"`
+	Backoffs       int          `json:"backoffs"`
+	Pauses         int          `json:"retry_after_pauses"`
+	PausedFor      JSONDuration `json:"retry_after_paused"`
+}
+
+// JSONHealth describes the resource usage of the load generator
+type JSONHealth struct {
+	Cores          int          `json:"cores"`
+	CPUAvg         *float64     `json:"cpu_avg,omitempty"` // Fraction of the cores used (omitted if unavailable)
+	CPUMax         *float64     `json:"cpu_max,omitempty"`
+	MaxGoroutines  int64        `json:"max_goroutines"`
+	GCCycles       int64        `json:"gc_cycles"`
+	GCPauseTotal   JSONDuration `json:"gc_pause_total"`
+	GCPauseMax     JSONDuration `json:"gc_pause_max"`
+	GCPauseShare   float64      `json:"gc_pause_share"`
+	MaxOpenFiles   int64        `json:"max_open_files,omitempty"` // Omitted if unavailable
+	FileLimit      int64        `json:"file_limit,omitempty"`
+	SchedulerLag   *JSONLatency `json:"scheduler_lag"`
+	RateLimiterLag *JSONLatency `json:"rate_limiter_lag,omitempty"` // Only with --max-rps
+	WorkerLoopLag  *JSONLatency `json:"worker_loop_lag"`
+	Warnings       []string     `json:"warnings"` // Empty if the load generator did not limit the results
+}
+
+// JSONErrorClass contains the count and most frequent messages of one error class
+type JSONErrorClass struct {
+	Class    string             `json:"class"`
+	Count    int64              `json:"count"`
+	Messages []JSONErrorMessage `json:"messages"`
+}
+
+// JSONErrorMessage counts one distinct error message
+type JSONErrorMessage struct {
+	Message   string `json:"message"`
+	Count     int64  `json:"count"`
+	FirstSeen string `json:"first_seen"`
+}
+
+// JSONTraces contains trace IDs of sampled slow and failed requests
+type JSONTraces struct {
+	Slow   []JSONTraceSample `json:"slow,omitempty"`
+	Failed []JSONTraceSample `json:"failed,omitempty"`
+}
+
+// JSONTraceSample links one request to its server-side trace
+type JSONTraceSample struct {
+	TraceID string       `json:"trace_id"`
+	URL     string       `json:"url"`
+	Status  string       `json:"status"`
+	Latency JSONDuration `json:"latency"`
+	Error   string       `json:"error,omitempty"`
+	Time    string       `json:"time"`
 }
 
 // JSONRequests contains request statistics
@@ -209,19 +765,58 @@ type JSONDuration struct {
 
 // PrintResultsJSON prints the test results in JSON format and saves to file
 // Returns the file path where JSON was saved
-func PrintResultsJSON(summary *runner.Summary, urls []string, concurrency int, duration time.Duration, method string, headers map[string]string, outputFile string) (string, error) {
-	// Convert status codes map from int keys to string keys for JSON
-	// Status code 0 represents network/connection errors
-	statusCodes := make(map[string]int64)
-	for code, count := range summary.StatusCodeCounts {
-		if code == 0 {
-			// Use "error" or "0" for network errors to make it clearer
-			statusCodes["error"] = count
-		} else {
-			statusCodes[fmt.Sprintf("%d", code)] = count
+func PrintResultsJSON(summary *runner.Summary, urls []string, concurrency int, duration time.Duration, method string, headers map[string]string, transport httpclient.TransportOptions, thresholds []threshold.Result, outputFile string) (string, error) {
+	return writeResultsJSON(BuildResultsJSON(summary, urls, concurrency, duration, method, headers, transport, thresholds), outputFile)
+}
+
+// writeResultsJSON saves a JSON report to outputFile, or to a timestamped file in results/ if
+// outputFile is empty, and returns the file path
+func writeResultsJSON(output JSONOutput, outputFile string) (string, error) {
+	// Marshal to JSON with indentation for readability
+	jsonBytes, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	// Determine output file path
+	var filePath string
+	if outputFile != "" {
+		// Use user-specified file path
+		filePath = outputFile
+		// Create directory if it doesn't exist
+		dir := filepath.Dir(filePath)
+		if dir != "." && dir != "" {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return "", fmt.Errorf("failed to create output directory: %w", err)
+			}
+		}
+	} else {
+		// Generate default file path in results/ directory
+		resultsDir := "results"
+		if err := os.MkdirAll(resultsDir, 0755); err != nil {
+			return "", fmt.Errorf("failed to create results directory: %w", err)
 		}
+
+		// Generate filename with timestamp: g0-result-YYYYMMDD-HHMMSS.json
+		timestamp := time.Now().Format("20060102-150405")
+		filePath = filepath.Join(resultsDir, fmt.Sprintf("g0-result-%s.json", timestamp))
+	}
+
+	// Write JSON to file
+	if err := os.WriteFile(filePath, jsonBytes, 0644); err != nil {
+		return "", fmt.Errorf("failed to write JSON file: %w", err)
 	}
 
+	// Don't print JSON to stdout - results are already shown in text format
+	// JSON is only saved to file
+
+	return filePath, nil
+}
+
+// BuildResultsJSON builds the JSON report of the test results
+func BuildResultsJSON(summary *runner.Summary, urls []string, concurrency int, duration time.Duration, method string, headers map[string]string, transport httpclient.TransportOptions, thresholds []threshold.Result) JSONOutput {
+	statusCodes := statusCodesToJSON(summary.StatusCodeCounts, summary.GRPC)
+
 	// Build JSON output structure
 	metadata := JSONMetadata{
 		Method:      method,
@@ -229,15 +824,20 @@ func PrintResultsJSON(summary *runner.Summary, urls []string, concurrency int, d
 		Duration:    duration.String(),
 		DurationMs:  duration.Milliseconds(),
 		Headers:     headers,
+		Transport:   transportToJSON(transport),
+	}
+
+	if summary.Warmup != nil {
+		metadata.Warmup = summary.Warmup.Duration.String()
 	}
-	
+
 	// Set URL or URLs based on count
 	if len(urls) == 1 {
 		metadata.URL = urls[0]
 	} else {
 		metadata.URLs = urls
 	}
-	
+
 	output := JSONOutput{
 		Metadata: metadata,
 		Metrics: JSONMetrics{
@@ -259,45 +859,274 @@ func PrintResultsJSON(summary *runner.Summary, urls []string, concurrency int, d
 		},
 	}
 
-	// Marshal to JSON with indentation for readability
-	jsonBytes, err := json.MarshalIndent(output, "", "  ")
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	if rt := summary.ResponseTime; rt != nil {
+		output.Metrics.ResponseTime = distributionToJSON(*rt)
 	}
 
-	// Determine output file path
-	var filePath string
-	if outputFile != "" {
-		// Use user-specified file path
-		filePath = outputFile
-		// Create directory if it doesn't exist
-		dir := filepath.Dir(filePath)
-		if dir != "." && dir != "" {
-			if err := os.MkdirAll(dir, 0755); err != nil {
-				return "", fmt.Errorf("failed to create output directory: %w", err)
-			}
+	if w := summary.Warmup; w != nil {
+		output.Metrics.Warmup = &JSONWarmup{
+			Duration: w.Duration.String(),
+			Requests: JSONRequests{
+				Total:   w.TotalRequests,
+				Success: w.SuccessRequests,
+				Failed:  w.FailedRequests,
+				RPS:     w.RPS,
+			},
+			Latency: *distributionToJSON(w.Latency),
 		}
-	} else {
-		// Generate default file path in results/ directory
-		resultsDir := "results"
-		if err := os.MkdirAll(resultsDir, 0755); err != nil {
-			return "", fmt.Errorf("failed to create results directory: %w", err)
+	}
+
+	if c := summary.Connections; c != nil {
+		connections := &JSONConnections{
+			New:             c.New,
+			Reused:          c.Reused,
+			ReuseRate:       c.ReuseRate,
+			RequestsPerConn: c.RequestsPerConn,
+			Closed:          c.Closed,
+			LowReuse:        c.LowReuse,
+		}
+		if c.Reused > 0 {
+			connections.IdleBeforeReuse = distributionToJSON(c.IdleBeforeReuse)
+		}
+		if c.Closed > 0 {
+			connections.Lifetime = distributionToJSON(c.Lifetime)
 		}
+		output.Metrics.Connections = connections
+	}
 
-		// Generate filename with timestamp: g0-result-YYYYMMDD-HHMMSS.json
-		timestamp := time.Now().Format("20060102-150405")
-		filePath = filepath.Join(resultsDir, fmt.Sprintf("g0-result-%s.json", timestamp))
+	if t := summary.TLS; t != nil {
+		output.Metrics.TLS = &JSONTLS{
+			Handshakes:   t.Handshakes,
+			Versions:     t.Versions,
+			CipherSuites: t.CipherSuites,
+		}
 	}
 
-	// Write JSON to file
-	if err := os.WriteFile(filePath, jsonBytes, 0644); err != nil {
-		return "", fmt.Errorf("failed to write JSON file: %w", err)
+	output.Metrics.Protocols = summary.Protocols
+	if q := summary.QUIC; q != nil {
+		output.Metrics.QUIC = &JSONQUIC{
+			Handshakes:    q.Handshakes,
+			HandshakeTime: distributionToJSON(q.HandshakeTime),
+			Resumed:       q.Resumed,
+			ZeroRTT:       q.ZeroRTT,
+		}
 	}
 
-	// Don't print JSON to stdout - results are already shown in text format
-	// JSON is only saved to file
+	if st := summary.Stream; st != nil {
+		e := st.EventsPerResponse
+		output.Metrics.Stream = &JSONStream{
+			Responses:         st.Responses,
+			SSEResponses:      st.SSE,
+			Events:            st.Events,
+			EventsPerResponse: JSONCounts{Min: e.Min, Max: e.Max, Avg: e.Avg, P50: e.P50, P90: e.P90, P99: e.P99},
+			FirstEvent:        distributionToJSON(st.FirstEvent),
+			Gap:               distributionToJSON(st.Gap),
+			Duration:          distributionToJSON(st.Duration),
+		}
+	}
 
-	return filePath, nil
+	if p := summary.Pacing; p != nil {
+		stdDevMs := float64(p.GapStdDev.Nanoseconds()) / 1000000.0
+		output.Metrics.Pacing = &JSONPacing{
+			Arrival:     p.Arrival,
+			TargetRPS:   p.TargetRate,
+			AchievedRPS: p.AchievedRate,
+			GapMean:     durationToJSON(p.GapMean),
+			GapStdDev:   durationToJSON(p.GapStdDev),
+			GapVariance: stdDevMs * stdDevMs,
+			GapCV:       p.GapCV,
+			Missed:      p.Missed,
+		}
+	}
+
+	if a := summary.Adaptive; a != nil {
+		output.Metrics.Adaptive = &JSONAdaptive{
+			SustainableRPS: a.SustainableRate,
+			MaxRPS:         a.MaxRate,
+			FinalRPS:       a.FinalRate,
+			LowestRPS:      a.LowestRate,
+			Backoffs:       a.Backoffs,
+			Pauses:         a.Pauses,
+			PausedFor:      durationToJSON(a.PausedFor),
+		}
+	}
+
+	if h := summary.Health; h != nil {
+		health := &JSONHealth{
+			Cores:         h.Cores,
+			MaxGoroutines: h.MaxGoroutines,
+			GCCycles:      h.GCCycles,
+			GCPauseTotal:  durationToJSON(h.GCPauseTotal),
+			GCPauseMax:    durationToJSON(h.GCPauseMax),
+			GCPauseShare:  h.GCPauseShare,
+			MaxOpenFiles:  h.MaxOpenFiles,
+			FileLimit:     h.FileLimit,
+			SchedulerLag:  distributionToJSON(h.SchedulerLag),
+			WorkerLoopLag: distributionToJSON(h.LoopLag),
+			Warnings:      healthWarnings(h),
+		}
+		if h.CPUSamples > 0 {
+			health.CPUAvg, health.CPUMax = &h.CPUAvg, &h.CPUMax
+		}
+		if h.TokenLag != nil {
+			health.RateLimiterLag = distributionToJSON(*h.TokenLag)
+		}
+		if health.Warnings == nil {
+			health.Warnings = []string{}
+		}
+		output.Metrics.Health = health
+	}
+
+	for _, e := range summary.Errors {
+		class := JSONErrorClass{Class: e.Class, Count: e.Count, Messages: []JSONErrorMessage{}}
+		for _, m := range e.Messages {
+			class.Messages = append(class.Messages, JSONErrorMessage{
+				Message:   m.Message,
+				Count:     m.Count,
+				FirstSeen: m.FirstSeen.Format(time.RFC3339Nano),
+			})
+		}
+		output.Metrics.Errors = append(output.Metrics.Errors, class)
+	}
+
+	// Per-endpoint breakdown when testing multiple URLs
+	if len(summary.Endpoints) > 1 {
+		for _, e := range summary.Endpoints {
+			output.Endpoints = append(output.Endpoints, JSONEndpoint{
+				URL: e.URL,
+				Requests: JSONRequests{
+					Total:   e.TotalRequests,
+					Success: e.SuccessRequests,
+					Failed:  e.FailedRequests,
+					RPS:     e.RPS,
+				},
+				Latency: JSONLatency{
+					Min: durationToJSON(e.MinLatency),
+					Max: durationToJSON(e.MaxLatency),
+					Avg: durationToJSON(e.AvgLatency),
+					P90: durationToJSON(e.P90Latency),
+					P95: durationToJSON(e.P95Latency),
+					P99: durationToJSON(e.P99Latency),
+				},
+				StatusCodes: statusCodesToJSON(e.StatusCodeCounts, summary.GRPC),
+			})
+		}
+	}
+
+	for _, o := range summary.Operations {
+		output.Operations = append(output.Operations, JSONOperation{
+			Name: o.Name,
+			Requests: JSONRequests{
+				Total:   o.TotalRequests,
+				Success: o.SuccessRequests,
+				Failed:  o.FailedRequests,
+				RPS:     o.RPS,
+			},
+			GraphQLErrors: o.GraphQLErrors,
+			Latency:       *distributionToJSON(o.Latency),
+		})
+	}
+
+	for _, c := range summary.Checks {
+		output.Checks = append(output.Checks, JSONCheck{
+			Check:    c.Name,
+			Passes:   c.Passes,
+			Fails:    c.Fails,
+			PassRate: c.PassRate(),
+		})
+	}
+
+	for _, r := range thresholds {
+		output.Thresholds = append(output.Thresholds, JSONThreshold{
+			Threshold: r.Threshold.String(),
+			Metric:    r.Threshold.Metric,
+			Observed:  r.Observed,
+			Expected:  r.Threshold.Value,
+			Passed:    r.Passed,
+		})
+	}
+
+	if len(summary.SlowTraces) > 0 || len(summary.FailedTraces) > 0 {
+		output.Metrics.Traces = &JSONTraces{
+			Slow:   tracesToJSON(summary.SlowTraces),
+			Failed: tracesToJSON(summary.FailedTraces),
+		}
+	}
+
+	return output
+}
+
+// statusCodesToJSON converts a status code map from int keys to string keys for JSON
+// Status code 0 represents network/connection errors and is reported as "error" (OK for gRPC)
+func statusCodesToJSON(counts map[int]int64, grpc bool) map[string]int64 {
+	statusCodes := make(map[string]int64)
+	for code, count := range counts {
+		statusCodes[statusText(code, grpc)] = count
+	}
+	return statusCodes
+}
+
+// distributionToJSON converts a latency distribution to its JSON form
+func distributionToJSON(d runner.LatencyDistribution) *JSONLatency {
+	return &JSONLatency{
+		Min: durationToJSON(d.Min),
+		Max: durationToJSON(d.Max),
+		Avg: durationToJSON(d.Avg),
+		P90: durationToJSON(d.P90),
+		P95: durationToJSON(d.P95),
+		P99: durationToJSON(d.P99),
+	}
+}
+
+// transportToJSON converts transport options for the JSON metadata
+func transportToJSON(t httpclient.TransportOptions) *JSONTransport {
+	redirects := fmt.Sprintf("follow (max %d)", t.MaxRedirects)
+	if t.NoRedirects {
+		redirects = "none"
+	}
+	var tlsSettings *JSONTLSSettings
+	if o := t.TLS; o.CACert != "" || o.Cert != "" || o.Insecure || o.ServerName != "" ||
+		o.MinVersion != 0 || o.MaxVersion != 0 || len(o.CipherSuites) > 0 {
+		tlsSettings = &JSONTLSSettings{
+			CACert:     o.CACert,
+			ClientCert: o.Cert,
+			Insecure:   o.Insecure,
+			ServerName: o.ServerName,
+		}
+		if o.MinVersion != 0 {
+			tlsSettings.MinVersion = tls.VersionName(o.MinVersion)
+		}
+		if o.MaxVersion != 0 {
+			tlsSettings.MaxVersion = tls.VersionName(o.MaxVersion)
+		}
+		for _, id := range o.CipherSuites {
+			tlsSettings.CipherSuites = append(tlsSettings.CipherSuites, tls.CipherSuiteName(id))
+		}
+	}
+
+	protocol := t.Protocol
+	if protocol == httpclient.ProtocolAuto {
+		protocol = "auto"
+	}
+	var h2Conns, h2MaxStreams int
+	if t.Protocol == httpclient.ProtocolHTTP2 || t.Protocol == httpclient.ProtocolH2C {
+		h2Conns = t.H2Conns
+		h2MaxStreams = t.H2MaxStreams
+	}
+
+	return &JSONTransport{
+		Timeout:             t.Timeout.String(),
+		ConnectTimeout:      t.ConnectTimeout.String(),
+		TLSHandshakeTimeout: t.TLSHandshakeTimeout.String(),
+		MaxIdleConnsPerHost: t.MaxIdleConnsPerHost,
+		MaxConnsPerHost:     t.MaxConnsPerHost,
+		KeepAlive:           !t.DisableKeepAlives,
+		Redirects:           redirects,
+		Protocol:            protocol,
+		H2Conns:             h2Conns,
+		H2MaxStreams:        h2MaxStreams,
+		TLS:                 tlsSettings,
+	}
 }
 
 // durationToJSON converts a time.Duration to JSONDuration format
@@ -307,3 +1136,22 @@ func durationToJSON(d time.Duration) JSONDuration {
 		Ms:    float64(d.Nanoseconds()) / 1000000.0, // Convert to milliseconds
 	}
 }
+
+// tracesToJSON converts trace samples to their JSON representation
+func tracesToJSON(samples []runner.TraceSample) []JSONTraceSample {
+	if len(samples) == 0 {
+		return nil
+	}
+	out := make([]JSONTraceSample, 0, len(samples))
+	for _, t := range samples {
+		out = append(out, JSONTraceSample{
+			TraceID: t.TraceID,
+			URL:     t.URL,
+			Status:  statusText(t.StatusCode, false),
+			Latency: durationToJSON(t.Latency),
+			Error:   t.Error,
+			Time:    t.Time.Format(time.RFC3339Nano),
+		})
+	}
+	return out
+}
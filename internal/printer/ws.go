@@ -0,0 +1,82 @@
+package printer
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/calummacc/g0/internal/ws"
+)
+
+// PrintWSStart prints the WebSocket test configuration
+func PrintWSStart(config ws.Config) {
+	fmt.Println("WebSocket Load Test Started")
+	fmt.Printf("URL: %s\n", config.URL)
+	fmt.Printf("Connections: %d\n", config.Connections)
+	fmt.Printf("Duration: %s\n", config.Duration)
+	switch {
+	case len(config.Messages) == 0:
+		fmt.Println("Messages: none (connect and listen)")
+	case config.Rate > 0:
+		fmt.Printf("Messages: %d template(s) at %.1f/s per connection\n", len(config.Messages), config.Rate)
+	default:
+		fmt.Printf("Messages: %d template(s), next sent when the previous one is answered\n", len(config.Messages))
+	}
+	fmt.Println()
+}
+
+// PrintWSRunning shows that the test is running on stderr (cleared by PrintWSResults)
+func PrintWSRunning(duration time.Duration) {
+	fmt.Fprintf(os.Stderr, "\033[2K\rRunning for %s...", formatDurationShort(duration))
+	os.Stderr.Sync()
+}
+
+// PrintWSResults prints the WebSocket test results
+func PrintWSResults(summary *ws.Summary) {
+	fmt.Fprint(os.Stderr, "\033[2K\r")
+
+	fmt.Println("Results:")
+	fmt.Printf("Connections: %d established of %d attempts\n", summary.Connected, summary.ConnectAttempts)
+	fmt.Printf("Messages Sent: %d (%.1f/s)\n", summary.Sent, summary.SentPerSec)
+	fmt.Printf("Messages Received: %d (%.1f/s)\n", summary.Received, summary.ReceivedPerSec)
+	if summary.Sent > 0 {
+		fmt.Printf("Replies Matched: %d | Lost (no reply in time or before disconnect): %d\n", summary.Matched, summary.Lost)
+	}
+
+	if summary.Connected > 0 {
+		c := summary.ConnectTime
+		fmt.Println()
+		fmt.Println("Connect Time:")
+		fmt.Printf("  Min: %s | Avg: %s | Max: %s | p99: %s\n",
+			formatDuration(c.Min), formatDuration(c.Avg), formatDuration(c.Max), formatDuration(c.P99))
+	}
+
+	if summary.Matched > 0 {
+		rtt := summary.RTT
+		fmt.Println()
+		fmt.Println("Round-trip Latency:")
+		fmt.Printf("  Min: %s\n", formatDuration(rtt.Min))
+		fmt.Printf("  Avg: %s\n", formatDuration(rtt.Avg))
+		fmt.Printf("  Max: %s\n", formatDuration(rtt.Max))
+		fmt.Printf("  p90: %s\n", formatDuration(rtt.P90))
+		fmt.Printf("  p95: %s\n", formatDuration(rtt.P95))
+		fmt.Printf("  p99: %s\n", formatDuration(rtt.P99))
+	}
+
+	if len(summary.ConnectErrors) > 0 {
+		fmt.Println()
+		fmt.Println("Connect Errors:")
+		for _, r := range summary.ConnectErrors {
+			fmt.Printf("  %s: %d\n", r.Reason, r.Count)
+		}
+	}
+
+	if len(summary.Disconnects) > 0 {
+		fmt.Println()
+		fmt.Println("Disconnects:")
+		for _, r := range summary.Disconnects {
+			fmt.Printf("  %s: %d\n", r.Reason, r.Count)
+		}
+	}
+	fmt.Println()
+}
@@ -0,0 +1,199 @@
+package runner
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Adaptive rate control tuning (AIMD: additive increase, multiplicative decrease)
+const (
+	adaptiveWindow        = time.Second // Results are evaluated once per window
+	adaptiveDecrease      = 0.5         // Rate multiplier after a throttled or erroring window
+	adaptiveIncreaseSteps = 20          // A clean window raises the rate by 1/20 of the maximum
+	adaptiveMinRateFactor = 0.01        // The rate never drops below 1% of the maximum
+	adaptiveErrorRise     = 0.05        // Error rate increase over the baseline that counts as overload
+	adaptiveMinResults    = 10          // Minimum results in a window to judge its error rate
+)
+
+// AdaptiveController adjusts a RateLimiter based on the responses of the target
+// It halves the rate when a window contains 429/503 responses or a rising error rate,
+// pauses on Retry-After, and probes upward again while windows stay clean
+type AdaptiveController struct {
+	mu      sync.Mutex
+	limiter *RateLimiter
+	maxRate float64
+	minRate float64
+	rate    float64
+
+	// Current window
+	windowStart time.Time
+	total       int64
+	throttled   int64
+	failed      int64
+
+	lastDecrease  time.Time // Results of requests sent before this are ignored
+	baselineError float64   // Lowest error rate seen in a clean window (-1 = none yet)
+	cleanRate     float64   // Rate of the last clean window since the last backoff (0 = none)
+
+	// Summary
+	lowestRate  float64
+	heldRates   []float64 // Rate of the clean window preceding each backoff
+	backoffs    int
+	pauses      int
+	pausedFor   time.Duration
+	pausedUntil time.Time
+}
+
+// NewAdaptiveController creates a controller that starts at maxRate and never exceeds it
+func NewAdaptiveController(limiter *RateLimiter, maxRate float64) *AdaptiveController {
+	now := time.Now()
+	return &AdaptiveController{
+		limiter:       limiter,
+		maxRate:       maxRate,
+		minRate:       maxRate * adaptiveMinRateFactor,
+		rate:          maxRate,
+		windowStart:   now,
+		lastDecrease:  now,
+		baselineError: -1,
+		lowestRate:    maxRate,
+	}
+}
+
+// Observe records one result and adjusts the rate at the end of each window
+func (a *AdaptiveController) Observe(result Result) {
+	if a == nil {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.observe(result, time.Now())
+}
+
+// observe records a result received at now; the caller holds a.mu
+func (a *AdaptiveController) observe(result Result, now time.Time) {
+	throttled := result.StatusCode == http.StatusTooManyRequests || result.StatusCode == http.StatusServiceUnavailable
+
+	if throttled && result.RetryAfter > 0 {
+		a.pause(now, now.Add(result.RetryAfter))
+	}
+
+	// Requests sent at the old rate say nothing about the new one
+	if result.SentAt.IsZero() || !result.SentAt.Before(a.lastDecrease) {
+		a.total++
+		if throttled {
+			a.throttled++
+		} else if result.Error != nil || result.StatusCode >= 500 {
+			a.failed++
+		}
+	}
+
+	if now.Sub(a.windowStart) >= adaptiveWindow {
+		a.evaluate(now)
+	}
+}
+
+// pause holds back requests until the given time, tracking pause count and duration
+func (a *AdaptiveController) pause(now, until time.Time) {
+	if !until.After(a.pausedUntil) {
+		return
+	}
+	if now.After(a.pausedUntil) {
+		a.pauses++
+		a.pausedFor += until.Sub(now)
+	} else {
+		a.pausedFor += until.Sub(a.pausedUntil)
+	}
+	a.pausedUntil = until
+	a.limiter.PauseUntil(until)
+}
+
+// evaluate closes the current window and decreases or increases the rate
+func (a *AdaptiveController) evaluate(now time.Time) {
+	defer func() {
+		a.windowStart = now
+		a.total, a.throttled, a.failed = 0, 0, 0
+	}()
+
+	if a.total == 0 {
+		return
+	}
+
+	errorRate := float64(a.failed) / float64(a.total)
+	judged := a.total >= adaptiveMinResults
+	rising := judged && a.baselineError >= 0 && errorRate > a.baselineError+adaptiveErrorRise
+
+	if a.throttled > 0 || rising {
+		if a.cleanRate > 0 {
+			a.heldRates = append(a.heldRates, a.cleanRate)
+			a.cleanRate = 0
+		}
+		a.backoffs++
+		a.lastDecrease = now
+		a.setRate(a.rate * adaptiveDecrease)
+		return
+	}
+
+	a.cleanRate = a.rate
+	if judged && (a.baselineError < 0 || errorRate < a.baselineError) {
+		a.baselineError = errorRate
+	}
+	a.setRate(a.rate + a.maxRate/adaptiveIncreaseSteps)
+}
+
+// setRate clamps the rate to the allowed range and applies it to the limiter
+func (a *AdaptiveController) setRate(rate float64) {
+	if rate > a.maxRate {
+		rate = a.maxRate
+	}
+	if rate < a.minRate {
+		rate = a.minRate
+	}
+	if rate == a.rate {
+		return
+	}
+	a.rate = rate
+	if rate < a.lowestRate {
+		a.lowestRate = rate
+	}
+	a.limiter.SetRate(rate)
+}
+
+// Summary returns the outcome of adaptive rate control, or nil if it was not enabled
+func (a *AdaptiveController) Summary() *AdaptiveSummary {
+	if a == nil {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	s := &AdaptiveSummary{
+		MaxRate:    a.maxRate,
+		FinalRate:  a.rate,
+		LowestRate: a.lowestRate,
+		Backoffs:   a.backoffs,
+		Pauses:     a.pauses,
+		PausedFor:  a.pausedFor,
+	}
+	if len(a.heldRates) > 0 {
+		var sum float64
+		for _, rate := range a.heldRates {
+			sum += rate
+		}
+		s.SustainableRate = sum / float64(len(a.heldRates))
+	}
+	return s
+}
+
+// AdaptiveSummary describes how adaptive rate control behaved during a run
+type AdaptiveSummary struct {
+	MaxRate         float64
+	FinalRate       float64
+	LowestRate      float64
+	SustainableRate float64 // Average rate that held just before each backoff (0 = never backed off)
+	Backoffs        int
+	Pauses          int           // Retry-After pauses
+	PausedFor       time.Duration // Total time paused by Retry-After
+}
@@ -0,0 +1,180 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// newTestAdaptiveController returns a controller at maxRate with its limiter
+func newTestAdaptiveController(maxRate float64) *AdaptiveController {
+	return NewAdaptiveController(NewRateLimiter(maxRate, 1, Arrival{}), maxRate)
+}
+
+// observeWindow spreads results over the controller's current window, the last one closing it
+func observeWindow(a *AdaptiveController, results ...Result) {
+	start := a.windowStart
+	for i, r := range results {
+		a.observe(r, start.Add(adaptiveWindow*time.Duration(i+1)/time.Duration(len(results))))
+	}
+}
+
+// results returns n copies of r
+func results(n int, r Result) []Result {
+	rs := make([]Result, n)
+	for i := range rs {
+		rs[i] = r
+	}
+	return rs
+}
+
+// checkRate fails the test unless both the controller and its limiter are at want
+func checkRate(t *testing.T, step string, a *AdaptiveController, want float64) {
+	t.Helper()
+	// The limiter keeps the interval, so its rate may be off by rounding
+	if a.rate != want || math.Abs(a.limiter.Rate()-want) > 1e-9 {
+		t.Errorf("%s: rate = %.2f (limiter %.2f), want %.2f", step, a.rate, a.limiter.Rate(), want)
+	}
+}
+
+func TestAdaptiveBacksOffOnThrottling(t *testing.T) {
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusServiceUnavailable} {
+		a := newTestAdaptiveController(100)
+
+		observeWindow(a, results(20, Result{StatusCode: 200})...)
+		checkRate(t, "clean window at the maximum", a, 100)
+
+		// A single throttled response halves the rate
+		observeWindow(a, append(results(19, Result{StatusCode: 200}), Result{StatusCode: status})...)
+		checkRate(t, http.StatusText(status), a, 50)
+
+		summary := a.Summary()
+		if summary.Backoffs != 1 || summary.LowestRate != 50 || summary.SustainableRate != 100 {
+			t.Errorf("%d: summary = %+v, want 1 backoff from a sustainable 100/s down to 50/s", status, summary)
+		}
+	}
+}
+
+func TestAdaptiveBacksOffOnRisingErrors(t *testing.T) {
+	a := newTestAdaptiveController(100)
+	failed := Result{Error: errors.New("connection reset by peer")}
+	serverError := Result{StatusCode: 500}
+
+	// The baseline error rate is 5%; up to 5 points more is tolerated
+	observeWindow(a, append(results(19, Result{StatusCode: 200}), serverError)...)
+	checkRate(t, "baseline", a, 100)
+	observeWindow(a, append(results(23, Result{StatusCode: 200}), failed, serverError)...)
+	checkRate(t, "8% errors", a, 100)
+
+	observeWindow(a, append(results(16, Result{StatusCode: 200}), failed, failed, serverError, serverError)...)
+	checkRate(t, "20% errors", a, 50)
+
+	// Too few results to judge an error rate
+	observeWindow(a, failed, failed, Result{StatusCode: 200})
+	checkRate(t, "3 results", a, 55)
+}
+
+func TestAdaptiveIgnoresLatency(t *testing.T) {
+	a := newTestAdaptiveController(100)
+	observeWindow(a, results(20, Result{StatusCode: 200, Latency: time.Millisecond})...)
+
+	// Only throttling and errors are overload signals; slow but successful responses are not
+	observeWindow(a, results(20, Result{StatusCode: 200, Latency: 5 * time.Second})...)
+	checkRate(t, "slow responses", a, 100)
+}
+
+func TestAdaptiveProbesUpward(t *testing.T) {
+	a := newTestAdaptiveController(100)
+	observeWindow(a, Result{StatusCode: http.StatusTooManyRequests})
+	checkRate(t, "backoff", a, 50)
+
+	// Each clean window adds 1/20 of the maximum, up to the maximum
+	for i, want := range []float64{55, 60, 65, 70, 75, 80, 85, 90, 95, 100, 100} {
+		observeWindow(a, results(20, Result{StatusCode: 200})...)
+		checkRate(t, fmt.Sprintf("clean window %d", i+1), a, want)
+	}
+
+	// A window without results changes nothing
+	a.observe(Result{StatusCode: http.StatusTooManyRequests, SentAt: a.lastDecrease.Add(-time.Millisecond)}, a.windowStart.Add(adaptiveWindow))
+	checkRate(t, "empty window", a, 100)
+}
+
+func TestAdaptiveClampsToMinimumRate(t *testing.T) {
+	a := newTestAdaptiveController(100)
+	for i := 0; i < 10; i++ {
+		observeWindow(a, Result{StatusCode: http.StatusTooManyRequests})
+	}
+	checkRate(t, "10 backoffs", a, 100*adaptiveMinRateFactor)
+
+	summary := a.Summary()
+	if summary.Backoffs != 10 || summary.LowestRate != 1 || summary.FinalRate != 1 {
+		t.Errorf("summary = %+v, want 10 backoffs down to 1/s", summary)
+	}
+	// It never held a clean window
+	if summary.SustainableRate != 0 {
+		t.Errorf("sustainable rate = %.2f, want 0", summary.SustainableRate)
+	}
+}
+
+func TestAdaptiveIgnoresResultsSentBeforeBackoff(t *testing.T) {
+	a := newTestAdaptiveController(100)
+	observeWindow(a, Result{StatusCode: http.StatusTooManyRequests})
+	checkRate(t, "backoff", a, 50)
+
+	// Throttled responses to requests sent at the old rate arrive after the backoff
+	backoff := a.lastDecrease
+	stale := Result{StatusCode: http.StatusTooManyRequests, SentAt: backoff.Add(-time.Millisecond)}
+	fresh := Result{StatusCode: 200, SentAt: backoff.Add(time.Millisecond)}
+	observeWindow(a, append(results(5, stale), results(20, fresh)...)...)
+	checkRate(t, "stale throttling", a, 55)
+}
+
+func TestAdaptivePausesOnRetryAfter(t *testing.T) {
+	a := newTestAdaptiveController(100)
+	start := a.windowStart
+	throttled := func(retryAfter time.Duration) Result {
+		return Result{StatusCode: http.StatusTooManyRequests, RetryAfter: retryAfter}
+	}
+
+	a.observe(throttled(2*time.Second), start)
+	// Overlapping Retry-After extends the same pause; a shorter one changes nothing
+	a.observe(throttled(2*time.Second), start.Add(time.Second))
+	a.observe(throttled(time.Second), start.Add(1500*time.Millisecond))
+	if until := a.limiter.pausedUntil(); !until.Equal(start.Add(3 * time.Second)) {
+		t.Errorf("limiter paused until +%s, want +3s", until.Sub(start))
+	}
+	// Retry-After on other statuses is ignored
+	a.observe(Result{StatusCode: 200, RetryAfter: time.Minute}, start.Add(2*time.Second))
+
+	// A later one starts a new pause
+	a.observe(throttled(time.Second), start.Add(5*time.Second))
+
+	summary := a.Summary()
+	if summary.Pauses != 2 || summary.PausedFor != 4*time.Second {
+		t.Errorf("%d pauses for %s, want 2 for 4s", summary.Pauses, summary.PausedFor)
+	}
+	if until := a.limiter.pausedUntil(); !until.Equal(start.Add(6 * time.Second)) {
+		t.Errorf("limiter paused until +%s, want +6s", until.Sub(start))
+	}
+}
+
+func TestAdaptiveRetryAfterHoldsBackRequests(t *testing.T) {
+	a := newTestAdaptiveController(1000)
+	a.Observe(Result{StatusCode: http.StatusServiceUnavailable, RetryAfter: 100 * time.Millisecond})
+
+	start := time.Now()
+	due, ok := a.limiter.Wait(context.Background())
+	if !ok {
+		t.Fatal("Wait was cancelled")
+	}
+	if waited := time.Since(start); waited < 90*time.Millisecond {
+		t.Errorf("request released after %s, want after the 100ms Retry-After", waited)
+	}
+	if due.Before(start.Add(90 * time.Millisecond)) {
+		t.Errorf("request due %s after the pause started, want at its end", due.Sub(start))
+	}
+}
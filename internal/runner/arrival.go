@@ -0,0 +1,61 @@
+package runner
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// Arrival distributions for request pacing under a rate limit
+const (
+	ArrivalConstant = "constant" // Evenly spaced requests
+	ArrivalUniform  = "uniform"  // Gaps drawn uniformly around the mean (spread set by Jitter)
+	ArrivalPoisson  = "poisson"  // Exponentially distributed gaps, like independent users
+)
+
+// Arrival shapes the gaps between scheduled requests while keeping their long-run mean
+type Arrival struct {
+	Kind   string
+	Jitter float64 // For uniform arrivals: gaps vary by up to ±Jitter × mean (0-1)
+}
+
+// ParseArrival validates an arrival distribution name and jitter
+func ParseArrival(kind string, jitter float64) (Arrival, error) {
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	switch kind {
+	case "", ArrivalConstant:
+		return Arrival{Kind: ArrivalConstant}, nil
+	case ArrivalUniform:
+		if jitter <= 0 || jitter > 1 {
+			return Arrival{}, fmt.Errorf("jitter must be between 0 (exclusive) and 1 for uniform arrivals")
+		}
+		return Arrival{Kind: ArrivalUniform, Jitter: jitter}, nil
+	case ArrivalPoisson:
+		return Arrival{Kind: ArrivalPoisson}, nil
+	default:
+		return Arrival{}, fmt.Errorf("unknown arrival distribution %q (supported: constant, uniform, poisson)", kind)
+	}
+}
+
+// gap returns the time until the next scheduled request, in nanoseconds, for the given mean gap
+func (a Arrival) gap(mean float64, rng *rand.Rand) float64 {
+	switch a.Kind {
+	case ArrivalUniform:
+		return mean * (1 + a.Jitter*(2*rng.Float64()-1))
+	case ArrivalPoisson:
+		return mean * rng.ExpFloat64()
+	default:
+		return mean
+	}
+}
+
+// String returns the distribution name, including the jitter for uniform arrivals
+func (a Arrival) String() string {
+	if a.Kind == ArrivalUniform {
+		return fmt.Sprintf("%s ±%.0f%%", a.Kind, a.Jitter*100)
+	}
+	if a.Kind == "" {
+		return ArrivalConstant
+	}
+	return a.Kind
+}
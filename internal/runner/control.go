@@ -0,0 +1,183 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// errNotStarted is returned when changing a load test that has not started yet
+var errNotStarted = errors.New("the load test has not started yet")
+
+// Control pauses, resumes or stops a running load test from outside the runner, and changes
+// its number of workers and rate while it runs
+// Pausing holds workers back before their next request; requests in flight complete normally.
+// The run's clock keeps going while paused, so paused time counts towards the duration
+type Control struct {
+	mu          sync.Mutex
+	resumed     chan struct{} // Closed on resume (nil while running)
+	paused      atomic.Bool   // Whether resumed is set, read by workers without mu
+	pausedAt    time.Time
+	pausedTotal time.Duration
+	rateLimiter *RateLimiter // Set by the runner; unlimited unless the run has a rate
+	pool        *workerPool  // Set by the runner
+	stats       *Stats       // Set by the runner
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewControl creates a control for a load test that has not started yet
+func NewControl() *Control {
+	return &Control{stop: make(chan struct{})}
+}
+
+// Pause holds workers back until Resume, reporting false if already paused
+func (c *Control) Pause() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.resumed != nil {
+		return false
+	}
+	c.resumed = make(chan struct{})
+	c.pausedAt = time.Now()
+	c.paused.Store(true)
+	return true
+}
+
+// Resume lets workers continue, reporting false if not paused
+func (c *Control) Resume() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.resumed == nil {
+		return false
+	}
+	c.pausedTotal += time.Since(c.pausedAt)
+	// Slots missed while paused are dropped so the rate does not catch up in a burst
+	c.rateLimiter.PauseUntil(time.Now())
+	close(c.resumed)
+	c.resumed = nil
+	c.paused.Store(false)
+	return true
+}
+
+// Paused reports whether the load test is paused
+func (c *Control) Paused() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.resumed != nil
+}
+
+// PausedTime returns how long the load test has been paused in total
+func (c *Control) PausedTime() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	total := c.pausedTotal
+	if c.resumed != nil {
+		total += time.Since(c.pausedAt)
+	}
+	return total
+}
+
+// Stop ends the load test early; the results so far are reported as usual
+func (c *Control) Stop() {
+	c.stopOnce.Do(func() { close(c.stop) })
+}
+
+// Stopped returns a channel closed by Stop (nil for a nil control, which never stops)
+func (c *Control) Stopped() <-chan struct{} {
+	if c == nil {
+		return nil
+	}
+	return c.stop
+}
+
+// Workers returns the number of running workers (0 before the load test starts)
+func (c *Control) Workers() int {
+	c.mu.Lock()
+	pool := c.pool
+	c.mu.Unlock()
+	if pool == nil {
+		return 0
+	}
+	return pool.size()
+}
+
+// SetWorkers starts or retires workers until n are running
+// Retired workers finish their request in flight first
+func (c *Control) SetWorkers(n int) error {
+	if n < 1 {
+		return fmt.Errorf("workers must be at least 1")
+	}
+	c.mu.Lock()
+	pool := c.pool
+	c.mu.Unlock()
+	if pool == nil {
+		return errNotStarted
+	}
+	pool.resize(n)
+	return nil
+}
+
+// Rate returns the current rate limit in requests per second (0 = unlimited)
+func (c *Control) Rate() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rateLimiter.Rate()
+}
+
+// SetRate changes the rate limit in requests per second; 0 removes it
+// Pacing is then reported against the last rate set
+func (c *Control) SetRate(maxRPS float64) error {
+	if maxRPS < 0 {
+		return fmt.Errorf("max-rps must be greater than or equal to 0")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.rateLimiter == nil {
+		return errNotStarted
+	}
+	c.rateLimiter.SetRate(maxRPS)
+
+	c.stats.mu.Lock()
+	c.stats.TargetRate = maxRPS
+	c.stats.mu.Unlock()
+	return nil
+}
+
+// attach connects the control to a running load test
+func (c *Control) attach(rateLimiter *RateLimiter, pool *workerPool, stats *Stats) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rateLimiter = rateLimiter
+	c.pool = pool
+	c.stats = stats
+}
+
+// wait blocks while the load test is paused, returning false if ctx is done or it is stopped first
+func (c *Control) wait(ctx context.Context) bool {
+	if c == nil || !c.paused.Load() {
+		return true
+	}
+	c.mu.Lock()
+	resumed := c.resumed
+	c.mu.Unlock()
+	if resumed == nil {
+		return true
+	}
+
+	select {
+	case <-resumed:
+		return true
+	case <-c.stop:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,183 @@
+package runner
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/calummacc/g0/internal/graphql"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Error classes used to group network failures (StatusCode 0 results)
+const (
+	ErrorClassTimeout           = "timeout"
+	ErrorClassConnectionRefused = "connection_refused"
+	ErrorClassConnectionReset   = "connection_reset"
+	ErrorClassDNS               = "dns"
+	ErrorClassTLS               = "tls"
+	ErrorClassCanceled          = "canceled"
+	ErrorClassEOF               = "eof"
+	ErrorClassGraphQL           = "graphql"     // Errors in a GraphQL response with a 2xx status
+	ErrorClassGRPC              = "grpc_status" // Non-OK status returned by a gRPC server
+	ErrorClassOther             = "other"
+)
+
+// ClassifyError maps a request error to one of the ErrorClass constants
+// Returns an empty string if err is nil
+func ClassifyError(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	// Errors returned in a GraphQL response body
+	var graphqlErr *graphql.Error
+	if errors.As(err, &graphqlErr) {
+		return ErrorClassGraphQL
+	}
+
+	// gRPC statuses; transport failures surface as UNAVAILABLE and are classified by message
+	if st, ok := status.FromError(err); ok {
+		switch st.Code() {
+		case codes.DeadlineExceeded:
+			return ErrorClassTimeout
+		case codes.Canceled:
+			return ErrorClassCanceled
+		case codes.Unavailable:
+			if class := classifyMessage(st.Message()); class != "" {
+				return class
+			}
+		}
+		return ErrorClassGRPC
+	}
+
+	// Context errors first: a cancelled run surfaces as a wrapped context error
+	if errors.Is(err, context.Canceled) {
+		return ErrorClassCanceled
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorClassTimeout
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return ErrorClassDNS
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return ErrorClassConnectionRefused
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return ErrorClassConnectionReset
+	}
+
+	if isTLSError(err) {
+		return ErrorClassTLS
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ErrorClassTimeout
+	}
+
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return ErrorClassEOF
+	}
+
+	// Fall back to message matching for errors that lose their type on the way up
+	// (e.g. errors re-created as strings by the HTTP/2 transport)
+	if class := classifyMessage(err.Error()); class != "" {
+		return class
+	}
+	return ErrorClassOther
+}
+
+// classifyMessage maps an error message to an ErrorClass constant, or returns "" if it matches none
+func classifyMessage(msg string) string {
+	msg = strings.ToLower(msg)
+	switch {
+	case strings.Contains(msg, "timeout"):
+		return ErrorClassTimeout
+	case strings.Contains(msg, "connection refused"):
+		return ErrorClassConnectionRefused
+	case strings.Contains(msg, "connection reset"), strings.Contains(msg, "broken pipe"):
+		return ErrorClassConnectionReset
+	case strings.Contains(msg, "no such host"):
+		return ErrorClassDNS
+	case strings.Contains(msg, "tls:"), strings.Contains(msg, "x509:"), strings.Contains(msg, "http response to https client"):
+		return ErrorClassTLS
+	case strings.HasSuffix(msg, "eof"):
+		return ErrorClassEOF
+	}
+	return ""
+}
+
+// isTLSError reports whether err originates from the TLS handshake or certificate verification
+func isTLSError(err error) bool {
+	var recordErr tls.RecordHeaderError
+	if errors.As(err, &recordErr) {
+		return true
+	}
+	var alertErr tls.AlertError
+	if errors.As(err, &alertErr) {
+		return true
+	}
+	var verifyErr *tls.CertificateVerificationError
+	if errors.As(err, &verifyErr) {
+		return true
+	}
+	var unknownAuthErr x509.UnknownAuthorityError
+	if errors.As(err, &unknownAuthErr) {
+		return true
+	}
+	var hostnameErr x509.HostnameError
+	if errors.As(err, &hostnameErr) {
+		return true
+	}
+	var certInvalidErr x509.CertificateInvalidError
+	return errors.As(err, &certInvalidErr)
+}
+
+// maxErrorMessages caps how many distinct error messages are tracked per run
+const maxErrorMessages = 1000
+
+// otherErrorMessage collects messages seen after maxErrorMessages distinct ones were tracked
+const otherErrorMessage = "(other messages)"
+
+// ErrorMessage counts occurrences of one distinct error message
+type ErrorMessage struct {
+	Message   string
+	Count     int64
+	FirstSeen time.Time
+}
+
+// ErrorClassSummary contains the count and most frequent messages of one error class
+type ErrorClassSummary struct {
+	Class    string
+	Count    int64
+	Messages []ErrorMessage // Most frequent first, at most the configured limit
+}
+
+// ephemeralAddrPattern matches the local "ip:port->" part of socket errors, which differs per connection
+var ephemeralAddrPattern = regexp.MustCompile(`(\[[0-9a-fA-F:.]+\]|[0-9.]+):\d+->`)
+
+// idPattern matches UUIDs and long hex strings such as request or trace IDs
+var idPattern = regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b|\b[0-9a-fA-F]{16,}\b`)
+
+// streamIDPattern matches the HTTP/2 stream ID of stream errors, which differs per request
+var streamIDPattern = regexp.MustCompile(`stream ID \d+`)
+
+// normalizeErrorMessage strips per-connection and per-request details so identical failures group together
+func normalizeErrorMessage(msg string) string {
+	msg = ephemeralAddrPattern.ReplaceAllString(msg, "")
+	msg = idPattern.ReplaceAllString(msg, "<id>")
+	return streamIDPattern.ReplaceAllString(msg, "stream ID <n>")
+}
@@ -0,0 +1,171 @@
+package runner
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/calummacc/g0/internal/graphql"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// timeoutError is a net.Error reporting a timeout without wrapping a known error
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "read deadline reached" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// dialError wraps err the way net.Dial reports a failed connect
+func dialError(err error) error {
+	return &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", err)}
+}
+
+// urlError wraps err the way http.Client reports a failed request
+func urlError(err error) error {
+	return &url.Error{Op: "Get", URL: "http://example.com/", Err: err}
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"nil", nil, ""},
+
+		{"deadline exceeded", context.DeadlineExceeded, ErrorClassTimeout},
+		{"client timeout", urlError(context.DeadlineExceeded), ErrorClassTimeout},
+		{"net.Error timeout", urlError(&net.OpError{Op: "read", Net: "tcp", Err: timeoutError{}}), ErrorClassTimeout},
+		{"canceled", urlError(context.Canceled), ErrorClassCanceled},
+
+		{"DNS", urlError(&net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", Name: "nowhere.invalid", IsNotFound: true}}), ErrorClassDNS},
+		{"connection refused", urlError(dialError(syscall.ECONNREFUSED)), ErrorClassConnectionRefused},
+		{"connection reset", urlError(&net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}), ErrorClassConnectionReset},
+		{"broken pipe", &net.OpError{Op: "write", Net: "tcp", Err: os.NewSyscallError("write", syscall.EPIPE)}, ErrorClassConnectionReset},
+
+		{"TLS record header", urlError(tls.RecordHeaderError{Msg: "first record does not look like a TLS handshake"}), ErrorClassTLS},
+		{"unknown authority", urlError(x509.UnknownAuthorityError{}), ErrorClassTLS},
+		{"hostname mismatch", urlError(&tls.CertificateVerificationError{Err: x509.HostnameError{Host: "example.com", Certificate: &x509.Certificate{}}}), ErrorClassTLS},
+
+		{"EOF", urlError(io.EOF), ErrorClassEOF},
+		{"unexpected EOF", fmt.Errorf("reading body: %w", io.ErrUnexpectedEOF), ErrorClassEOF},
+
+		// Errors only identifiable by their message, e.g. from HTTP/2 or QUIC transports
+		{"timeout message", errors.New("http2: timeout awaiting response headers"), ErrorClassTimeout},
+		{"refused message", errors.New("dial tcp 127.0.0.1:1: connect: connection refused"), ErrorClassConnectionRefused},
+		{"reset message", errors.New("read tcp: connection reset by peer"), ErrorClassConnectionReset},
+		{"DNS message", errors.New("lookup nowhere.invalid: no such host"), ErrorClassDNS},
+		{"TLS message", errors.New("remote error: tls: bad certificate"), ErrorClassTLS},
+		{"plain HTTP message", errors.New("http: server gave HTTP response to HTTPS client"), ErrorClassTLS},
+		{"EOF message", errors.New("http2: server sent GOAWAY and closed the connection; LastStreamID=1, ErrCode=NO_ERROR, debug=\"\": unexpected EOF"), ErrorClassEOF},
+
+		{"GraphQL", fmt.Errorf("response: %w", &graphql.Error{Messages: []string{"user not found"}}), ErrorClassGraphQL},
+
+		{"gRPC status", status.Error(codes.NotFound, "no such user"), ErrorClassGRPC},
+		{"gRPC deadline", status.Error(codes.DeadlineExceeded, "context deadline exceeded"), ErrorClassTimeout},
+		{"gRPC canceled", status.Error(codes.Canceled, "context canceled"), ErrorClassCanceled},
+		{"gRPC transport failure", status.Error(codes.Unavailable, `connection error: desc = "transport: Error while dialing: dial tcp 127.0.0.1:1: connect: connection refused"`), ErrorClassConnectionRefused},
+		{"gRPC unavailable", status.Error(codes.Unavailable, "server is draining"), ErrorClassGRPC},
+
+		{"unknown", errors.New("something went wrong"), ErrorClassOther},
+	}
+	for _, tt := range tests {
+		if got := ClassifyError(tt.err); got != tt.want {
+			t.Errorf("%s: ClassifyError(%v) = %q, want %q", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeErrorMessage(t *testing.T) {
+	tests := []struct {
+		name, msg, want string
+	}{
+		{
+			"local IPv4 address",
+			"read tcp 127.0.0.1:54321->10.0.0.1:8080: read: connection reset by peer",
+			"read tcp 10.0.0.1:8080: read: connection reset by peer",
+		},
+		{
+			"local IPv6 address",
+			"write tcp [::1]:54321->[fe80::1]:8080: write: broken pipe",
+			"write tcp [fe80::1]:8080: write: broken pipe",
+		},
+		{
+			"remote address kept",
+			"dial tcp 10.0.0.1:8080: connect: connection refused",
+			"dial tcp 10.0.0.1:8080: connect: connection refused",
+		},
+		{
+			"URL kept",
+			`Get "http://10.0.0.1:8080/users/42": EOF`,
+			`Get "http://10.0.0.1:8080/users/42": EOF`,
+		},
+		{
+			"UUID",
+			"request 123e4567-e89b-12d3-a456-426614174000 rejected",
+			"request <id> rejected",
+		},
+		{
+			"hex ID",
+			"trace 4bf92f3577b34da6a3ce929d0e0e4736 failed",
+			"trace <id> failed",
+		},
+		{
+			"HTTP/2 stream ID",
+			"stream error: stream ID 7; INTERNAL_ERROR; received from peer",
+			"stream error: stream ID <n>; INTERNAL_ERROR; received from peer",
+		},
+		{
+			"short numbers kept",
+			"unexpected status 503 after 3 retries",
+			"unexpected status 503 after 3 retries",
+		},
+	}
+	for _, tt := range tests {
+		if got := normalizeErrorMessage(tt.msg); got != tt.want {
+			t.Errorf("%s: normalizeErrorMessage(%q) = %q, want %q", tt.name, tt.msg, got, tt.want)
+		}
+	}
+}
+
+func TestStatsGroupsErrorMessages(t *testing.T) {
+	stats := NewStats()
+	for _, msg := range []string{
+		"read tcp 127.0.0.1:50001->10.0.0.1:8080: read: connection reset by peer",
+		"read tcp 127.0.0.1:50002->10.0.0.1:8080: read: connection reset by peer",
+		"read tcp 127.0.0.1:50003->10.0.0.2:8080: read: connection reset by peer",
+	} {
+		stats.AddResult(Result{URL: "http://a/", Error: errors.New(msg)})
+	}
+
+	summary := stats.GetSummary()
+	if len(summary.Errors) != 1 || summary.Errors[0].Class != ErrorClassConnectionReset || summary.Errors[0].Count != 3 {
+		t.Fatalf("errors = %+v, want 3 connection resets", summary.Errors)
+	}
+	counts := make(map[string]int64)
+	for _, m := range summary.Errors[0].Messages {
+		counts[m.Message] = m.Count
+	}
+	want := map[string]int64{
+		"read tcp 10.0.0.1:8080: read: connection reset by peer": 2,
+		"read tcp 10.0.0.2:8080: read: connection reset by peer": 1,
+	}
+	if len(counts) != len(want) {
+		t.Errorf("messages = %v, want %v", counts, want)
+	}
+	for msg, n := range want {
+		if counts[msg] != n {
+			t.Errorf("%q counted %d times, want %d", msg, counts[msg], n)
+		}
+	}
+}
@@ -0,0 +1,351 @@
+package runner
+
+import (
+	"math/bits"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// healthInterval is how often the load generator samples its own resource usage
+const healthInterval = 500 * time.Millisecond
+
+// probeInterval is how long the scheduler probe sleeps before measuring how late it woke up
+const probeInterval = 5 * time.Millisecond
+
+// Limits past which results are likely skewed by the load generator rather than the target
+const (
+	cpuBoundThreshold = 0.85                  // Average fraction of the available cores used
+	fdLimitThreshold  = 0.9                   // Fraction of the open file limit reached
+	gcPauseThreshold  = 0.05                  // Fraction of the run spent in GC pauses
+	lagThreshold      = 10 * time.Millisecond // p99 of scheduler, token or worker loop lag
+)
+
+// lagSubBuckets is the number of LagHistogram buckets per power of two, bounding the
+// error of its percentiles to 1/lagSubBuckets of the value
+const lagSubBuckets = 16
+
+// LagHistogram counts lag samples in log-linear buckets, so that the lag of every request and
+// scheduler probe is kept in bounded memory however long the run
+// The zero value is an empty histogram
+type LagHistogram struct {
+	Counts []int64 // Samples per bucket (see lagBucket), up to the highest bucket used
+	Count  int64
+	Sum    time.Duration
+	Min    time.Duration
+	Max    time.Duration
+}
+
+// lagBucket returns the bucket of d: values below 2*lagSubBuckets ns have their own bucket,
+// larger ones share each power of two between lagSubBuckets buckets
+func lagBucket(d time.Duration) int {
+	if d < 2*lagSubBuckets {
+		return int(max(d, 0))
+	}
+	shift := bits.Len64(uint64(d)) - bits.Len64(2*lagSubBuckets-1)
+	return shift*lagSubBuckets + int(d>>shift)
+}
+
+// lagBucketRange returns the lowest value of bucket i and the width of the bucket
+func lagBucketRange(i int) (time.Duration, time.Duration) {
+	shift := max(i/lagSubBuckets-1, 0)
+	return time.Duration(i-shift*lagSubBuckets) << shift, time.Duration(1) << shift
+}
+
+// Add records a sample
+func (h *LagHistogram) Add(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	i := lagBucket(d)
+	if i >= len(h.Counts) {
+		h.Counts = append(h.Counts, make([]int64, i+1-len(h.Counts))...)
+	}
+	h.Counts[i]++
+	if h.Count == 0 || d < h.Min {
+		h.Min = d
+	}
+	if d > h.Max {
+		h.Max = d
+	}
+	h.Count++
+	h.Sum += d
+}
+
+// merge adds the samples of o
+func (h *LagHistogram) merge(o LagHistogram) {
+	if o.Count == 0 {
+		return
+	}
+	if len(o.Counts) > len(h.Counts) {
+		h.Counts = append(h.Counts, make([]int64, len(o.Counts)-len(h.Counts))...)
+	}
+	for i, n := range o.Counts {
+		h.Counts[i] += n
+	}
+	if h.Count == 0 || o.Min < h.Min {
+		h.Min = o.Min
+	}
+	if o.Max > h.Max {
+		h.Max = o.Max
+	}
+	h.Count += o.Count
+	h.Sum += o.Sum
+}
+
+// clone returns a copy that does not share the bucket counts
+func (h LagHistogram) clone() LagHistogram {
+	h.Counts = slices.Clone(h.Counts)
+	return h
+}
+
+// percentile estimates the given percentile (0-100), interpolating within its bucket
+// like Percentile does between samples
+func (h LagHistogram) percentile(percentile float64) time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	rank := float64(h.Count-1) * percentile / 100
+	var below int64
+	for i, n := range h.Counts {
+		if n == 0 || float64(below+n) <= rank {
+			below += n
+			continue
+		}
+		low, width := lagBucketRange(i)
+		value := low + time.Duration(float64(width)*(rank-float64(below))/float64(n))
+		return min(max(value, h.Min), h.Max)
+	}
+	return h.Max
+}
+
+// Distribution summarises the samples
+func (h LagHistogram) Distribution() LatencyDistribution {
+	if h.Count == 0 {
+		return LatencyDistribution{}
+	}
+	return LatencyDistribution{
+		Min: h.Min,
+		Max: h.Max,
+		Avg: h.Sum / time.Duration(h.Count),
+		P90: h.percentile(90),
+		P95: h.percentile(95),
+		P99: h.percentile(99),
+	}
+}
+
+// HealthMonitor samples the CPU usage, goroutines, GC pauses and open files of the load generator
+type HealthMonitor struct {
+	stats *Stats
+	stop  chan struct{}
+	wg    sync.WaitGroup
+
+	lastWall   time.Time
+	lastCPU    time.Duration
+	cpuKnown   bool
+	lastGC     uint32
+	lastPauses uint64
+}
+
+// StartHealthMonitor starts sampling into stats until Stop is called
+func StartHealthMonitor(stats *Stats) *HealthMonitor {
+	m := &HealthMonitor{
+		stats:    stats,
+		stop:     make(chan struct{}),
+		lastWall: time.Now(),
+	}
+	m.lastCPU, m.cpuKnown = processCPUTime()
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	m.lastGC, m.lastPauses = mem.NumGC, mem.PauseTotalNs
+
+	stats.mu.Lock()
+	stats.CPUCores = runtime.GOMAXPROCS(0)
+	stats.FileLimit = fileLimit()
+	stats.mu.Unlock()
+
+	m.wg.Add(2)
+	go m.run()
+	go m.probe()
+	return m
+}
+
+// Stop takes a last sample and stops the monitor
+func (m *HealthMonitor) Stop() {
+	close(m.stop)
+	m.wg.Wait()
+}
+
+// run samples every healthInterval until stopped
+func (m *HealthMonitor) run() {
+	defer m.wg.Done()
+	ticker := time.NewTicker(healthInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			m.sample()
+		case <-m.stop:
+			m.sample()
+			return
+		}
+	}
+}
+
+// probe repeatedly sleeps for probeInterval and records how late it woke up
+// Goroutines that wake up late mean the Go scheduler or the machine's CPUs are saturated
+func (m *HealthMonitor) probe() {
+	defer m.wg.Done()
+	timer := time.NewTimer(probeInterval)
+	defer timer.Stop()
+	for {
+		start := time.Now()
+		select {
+		case <-timer.C:
+		case <-m.stop:
+			return
+		}
+		lag := time.Since(start) - probeInterval
+		if lag < 0 {
+			lag = 0
+		}
+		m.stats.mu.Lock()
+		m.stats.SchedulerLags.Add(lag)
+		m.stats.mu.Unlock()
+		timer.Reset(probeInterval)
+	}
+}
+
+// sample records the usage since the previous sample
+func (m *HealthMonitor) sample() {
+	now := time.Now()
+	wall := now.Sub(m.lastWall)
+	m.lastWall = now
+
+	cpu, cpuKnown := processCPUTime()
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	goroutines := int64(runtime.NumGoroutine())
+	openFDs := openFiles()
+
+	// Pauses of the cycles since the last sample (the runtime keeps the last 256)
+	var maxPause time.Duration
+	cycles := mem.NumGC - m.lastGC
+	for i := uint32(0); i < cycles && i < uint32(len(mem.PauseNs)); i++ {
+		pause := time.Duration(mem.PauseNs[(mem.NumGC-i+uint32(len(mem.PauseNs))-1)%uint32(len(mem.PauseNs))])
+		if pause > maxPause {
+			maxPause = pause
+		}
+	}
+
+	s := m.stats
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.HealthDuration += wall
+	// A short final interval would make a noisy sample
+	if cpuKnown && m.cpuKnown && wall >= healthInterval/2 && s.CPUCores > 0 {
+		s.CPUSamples = append(s.CPUSamples, float64(cpu-m.lastCPU)/float64(wall)/float64(s.CPUCores))
+	}
+	if goroutines > s.MaxGoroutines {
+		s.MaxGoroutines = goroutines
+	}
+	if openFDs > s.MaxOpenFiles {
+		s.MaxOpenFiles = openFDs
+	}
+	s.GCCycles += int64(cycles)
+	s.GCPauseTotal += time.Duration(mem.PauseTotalNs - m.lastPauses)
+	if maxPause > s.GCPauseMax {
+		s.GCPauseMax = maxPause
+	}
+
+	m.lastCPU, m.cpuKnown = cpu, cpuKnown
+	m.lastGC, m.lastPauses = mem.NumGC, mem.PauseTotalNs
+}
+
+// HealthSummary describes the resource usage of the load generator during the run
+type HealthSummary struct {
+	Cores         int     // GOMAXPROCS of the load generator
+	CPUSamples    int     // Number of CPU samples (0 if CPU usage is unavailable on this platform)
+	CPUAvg        float64 // Average fraction of Cores used
+	CPUMax        float64 // Highest fraction of Cores used in one sample
+	MaxGoroutines int64
+	GCCycles      int64
+	GCPauseTotal  time.Duration
+	GCPauseMax    time.Duration
+	GCPauseShare  float64 // Fraction of the run spent in GC pauses
+	MaxOpenFiles  int64   // Open file descriptors (0 if unavailable on this platform)
+	FileLimit     int64   // Open file limit (0 if unknown)
+
+	SchedulerLag LatencyDistribution  // How late a sleeping goroutine was woken up
+	TokenLag     *LatencyDistribution // How late the rate limiter woke workers for their slots (nil without a rate limit)
+	LoopLag      LatencyDistribution  // Worker time outside of requests and rate limiting
+
+	CPUBound        bool // CPU usage close to the available cores
+	FilesNearLimit  bool // Open files close to the limit
+	OutOfFiles      bool // Requests failed with "too many open files"
+	GCHeavy         bool // A significant share of the run was spent in GC pauses
+	SchedulerBusy   bool // Goroutines were woken up late
+	TokenLagHigh    bool // Rate limiter slots were released late
+	LoopLagHigh     bool // Workers were held up between requests
+	GeneratorLimits bool // Any of the above: results are likely limited by the load generator
+}
+
+// healthSummary summarises the samples of a HealthMonitor (nil if the run was not monitored)
+func (s *Stats) healthSummary() *HealthSummary {
+	if s.CPUCores == 0 {
+		return nil
+	}
+
+	h := &HealthSummary{
+		Cores:         s.CPUCores,
+		CPUSamples:    len(s.CPUSamples),
+		MaxGoroutines: s.MaxGoroutines,
+		GCCycles:      s.GCCycles,
+		GCPauseTotal:  s.GCPauseTotal,
+		GCPauseMax:    s.GCPauseMax,
+		MaxOpenFiles:  s.MaxOpenFiles,
+		FileLimit:     s.FileLimit,
+		SchedulerLag:  s.SchedulerLags.Distribution(),
+		LoopLag:       s.LoopLags.Distribution(),
+	}
+	for _, cpu := range s.CPUSamples {
+		h.CPUAvg += cpu
+		if cpu > h.CPUMax {
+			h.CPUMax = cpu
+		}
+	}
+	if h.CPUSamples > 0 {
+		h.CPUAvg /= float64(h.CPUSamples)
+	}
+	if s.HealthDuration > 0 {
+		h.GCPauseShare = float64(s.GCPauseTotal) / float64(s.HealthDuration)
+	}
+	if s.TargetRate > 0 {
+		lag := s.TokenLags.Distribution()
+		h.TokenLag = &lag
+	}
+
+	h.CPUBound = h.CPUAvg >= cpuBoundThreshold
+	h.FilesNearLimit = h.FileLimit > 0 && float64(h.MaxOpenFiles) >= fdLimitThreshold*float64(h.FileLimit)
+	h.OutOfFiles = s.hasErrorMessage("too many open files")
+	h.GCHeavy = h.GCPauseShare >= gcPauseThreshold
+	h.SchedulerBusy = h.SchedulerLag.P99 >= lagThreshold
+	h.TokenLagHigh = h.TokenLag != nil && h.TokenLag.P99 >= lagThreshold
+	h.LoopLagHigh = h.LoopLag.P99 >= lagThreshold
+	h.GeneratorLimits = h.CPUBound || h.FilesNearLimit || h.OutOfFiles || h.GCHeavy || h.SchedulerBusy || h.TokenLagHigh || h.LoopLagHigh
+	return h
+}
+
+// hasErrorMessage reports whether any recorded error message contains text
+func (s *Stats) hasErrorMessage(text string) bool {
+	for _, messages := range s.ErrorMessages {
+		for message := range messages {
+			if strings.Contains(message, text) {
+				return true
+			}
+		}
+	}
+	return false
+}
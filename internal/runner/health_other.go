@@ -0,0 +1,20 @@
+//go:build !linux && !darwin && !windows
+
+package runner
+
+import "time"
+
+// processCPUTime is not available on this platform
+func processCPUTime() (time.Duration, bool) {
+	return 0, false
+}
+
+// openFiles is not available on this platform
+func openFiles() int64 {
+	return 0
+}
+
+// fileLimit is not available on this platform
+func fileLimit() int64 {
+	return 0
+}
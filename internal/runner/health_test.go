@@ -0,0 +1,203 @@
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// lagSamples returns n lags spread over several orders of magnitude, like real lags
+func lagSamples(n int, seed int64) []time.Duration {
+	rng := rand.New(rand.NewSource(seed))
+	lags := make([]time.Duration, n)
+	for i := range lags {
+		lags[i] = time.Duration(math.Exp(rng.Float64()*math.Log(float64(100*time.Millisecond)))) - 1
+	}
+	return lags
+}
+
+func TestLagBucketRange(t *testing.T) {
+	for _, d := range []time.Duration{0, 1, 15, 31, 32, 33, 63, 64, 1000, time.Millisecond, time.Hour, math.MaxInt64} {
+		low, width := lagBucketRange(lagBucket(d))
+		if d < low || d-low >= width {
+			t.Errorf("%d falls in bucket %d covering %d+%d", d, lagBucket(d), low, width)
+		}
+		if d >= 2*lagSubBuckets && float64(width)/float64(low) > 1.0/lagSubBuckets {
+			t.Errorf("bucket of %d is %d wide from %d, more than 1/%d", d, width, low, lagSubBuckets)
+		}
+	}
+	// Adjacent buckets leave no gap
+	for i := 1; i < 1000; i++ {
+		low, _ := lagBucketRange(i)
+		prevLow, prevWidth := lagBucketRange(i - 1)
+		if prevLow+prevWidth != low {
+			t.Fatalf("bucket %d ends at %d, bucket %d starts at %d", i-1, prevLow+prevWidth, i, low)
+		}
+	}
+}
+
+func TestLagHistogramDistribution(t *testing.T) {
+	lags := lagSamples(100000, 1)
+	var h LagHistogram
+	for _, lag := range lags {
+		h.Add(lag)
+	}
+
+	got, want := h.Distribution(), Distribution(lags)
+	if got.Min != want.Min || got.Max != want.Max || got.Avg != want.Avg {
+		t.Errorf("min/max/avg = %s/%s/%s, want %s/%s/%s", got.Min, got.Max, got.Avg, want.Min, want.Max, want.Avg)
+	}
+	for name, p := range map[string][2]time.Duration{"p90": {got.P90, want.P90}, "p95": {got.P95, want.P95}, "p99": {got.P99, want.P99}} {
+		if math.Abs(float64(p[0]-p[1])) > float64(p[1])/lagSubBuckets {
+			t.Errorf("%s = %s, want %s within 1/%d", name, p[0], p[1], lagSubBuckets)
+		}
+	}
+
+	// Memory is bounded by the range of the values, not their number
+	if len(h.Counts) > 500 {
+		t.Errorf("%d buckets for lags up to %s", len(h.Counts), got.Max)
+	}
+
+	if (LagHistogram{}).Distribution() != (LatencyDistribution{}) {
+		t.Error("empty histogram has a non-zero distribution")
+	}
+	var constant LagHistogram
+	addLags(&constant, 10, 3*time.Millisecond)
+	if d := constant.Distribution(); d.Min != 3*time.Millisecond || d.P99 != 3*time.Millisecond {
+		t.Errorf("constant lags summarised as %+v, want 3ms throughout", d)
+	}
+}
+
+func TestLagHistogramMerge(t *testing.T) {
+	var whole LagHistogram
+	parts := make([]LagHistogram, 3)
+	for i := range parts {
+		for _, lag := range lagSamples(1000*(i+1), int64(i)) {
+			whole.Add(lag)
+			parts[i].Add(lag)
+		}
+	}
+
+	// Partial results travel as JSON from the agents to the controller
+	var merged LagHistogram
+	for _, part := range parts {
+		data, err := json.Marshal(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded LagHistogram
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		merged.merge(decoded)
+	}
+	merged.merge(LagHistogram{})
+	if !reflect.DeepEqual(merged, whole) {
+		t.Errorf("merged histogram differs from the histogram of all samples")
+	}
+}
+
+func TestSnapshotDoesNotShareLags(t *testing.T) {
+	stats := NewStats()
+	stats.AddResult(Result{StatusCode: 200, LoopLag: time.Millisecond})
+	snapshot := stats.snapshot()
+	stats.AddResult(Result{StatusCode: 200, LoopLag: time.Millisecond})
+	if n := snapshot.LoopLags.Counts[lagBucket(time.Millisecond)]; n != 1 {
+		t.Errorf("snapshot counts %d lags, want the 1 recorded before it", n)
+	}
+}
+
+func TestHealthMonitor(t *testing.T) {
+	stats := NewStats()
+	monitor := StartHealthMonitor(stats)
+	time.Sleep(2*healthInterval + healthInterval/4)
+	monitor.Stop()
+
+	summary := stats.GetSummary().Health
+	if summary == nil || summary.Cores == 0 {
+		t.Fatalf("health = %+v, want the load generator's cores", summary)
+	}
+	// The probe wakes up every probeInterval; a busy machine wakes it less often
+	if n := stats.SchedulerLags.Count; n < 10 || n > int64(3*healthInterval/probeInterval) {
+		t.Errorf("%d scheduler probes in %s", n, 2*healthInterval+healthInterval/4)
+	}
+	if stats.HealthDuration < 2*healthInterval {
+		t.Errorf("health samples cover %s, want the whole run", stats.HealthDuration)
+	}
+	if summary.MaxGoroutines == 0 {
+		t.Error("no goroutines sampled")
+	}
+	if _, ok := processCPUTime(); ok && summary.CPUSamples < 2 {
+		t.Errorf("%d CPU samples, want one per interval", summary.CPUSamples)
+	}
+	if summary.TokenLag != nil {
+		t.Errorf("token lag %+v reported without a rate limit", summary.TokenLag)
+	}
+}
+
+// addLags adds n lags of d to h
+func addLags(h *LagHistogram, n int, d time.Duration) {
+	for i := 0; i < n; i++ {
+		h.Add(d)
+	}
+}
+
+func TestHealthSummaryFlags(t *testing.T) {
+	if NewStats().healthSummary() != nil {
+		t.Error("health reported for a run that was not monitored")
+	}
+
+	idle := NewStats()
+	idle.CPUCores = 4
+	idle.CPUSamples = []float64{0.2, 0.4}
+	idle.FileLimit, idle.MaxOpenFiles = 1024, 100
+	idle.HealthDuration, idle.GCPauseTotal = time.Second, time.Millisecond
+	idle.TargetRate = 100
+	addLags(&idle.SchedulerLags, 100, 100*time.Microsecond)
+	addLags(&idle.TokenLags, 100, 100*time.Microsecond)
+	addLags(&idle.LoopLags, 100, 100*time.Microsecond)
+	h := idle.healthSummary()
+	if h.CPUAvg < 0.299 || h.CPUAvg > 0.301 || h.CPUMax != 0.4 || h.GCPauseShare != 0.001 || h.TokenLag == nil {
+		t.Errorf("health = %+v, want 30%% CPU, 40%% at most, 0.1%% GC and a token lag", h)
+	}
+	if h.GeneratorLimits {
+		t.Errorf("idle generator flagged: %+v", h)
+	}
+
+	for name, overload := range map[string]func(s *Stats){
+		"CPUBound":       func(s *Stats) { s.CPUSamples = []float64{0.9, 0.95} },
+		"FilesNearLimit": func(s *Stats) { s.MaxOpenFiles = 1000 },
+		"OutOfFiles": func(s *Stats) {
+			s.recordErrorMessage(ErrorClassOther, errors.New("accept tcp: too many open files"))
+		},
+		"GCHeavy":       func(s *Stats) { s.GCPauseTotal = 100 * time.Millisecond },
+		"SchedulerBusy": func(s *Stats) { addLags(&s.SchedulerLags, 5, 2*lagThreshold) },
+		"TokenLagHigh":  func(s *Stats) { addLags(&s.TokenLags, 5, 2*lagThreshold) },
+		"LoopLagHigh":   func(s *Stats) { addLags(&s.LoopLags, 5, 2*lagThreshold) },
+	} {
+		s := idle.snapshot()
+		overload(s)
+		h := s.healthSummary()
+		flags := map[string]bool{
+			"CPUBound":       h.CPUBound,
+			"FilesNearLimit": h.FilesNearLimit,
+			"OutOfFiles":     h.OutOfFiles,
+			"GCHeavy":        h.GCHeavy,
+			"SchedulerBusy":  h.SchedulerBusy,
+			"TokenLagHigh":   h.TokenLagHigh,
+			"LoopLagHigh":    h.LoopLagHigh,
+		}
+		for flag, set := range flags {
+			if set != (flag == name) {
+				t.Errorf("%s: %s = %v", name, flag, set)
+			}
+		}
+		if !h.GeneratorLimits {
+			t.Errorf("%s: generator limits not reported", name)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+//go:build windows
+
+package runner
+
+import (
+	"syscall"
+	"time"
+)
+
+// processCPUTime returns the user and kernel CPU time used by the process so far
+func processCPUTime() (time.Duration, bool) {
+	process, err := syscall.GetCurrentProcess()
+	if err != nil {
+		return 0, false
+	}
+	var creation, exit, kernel, user syscall.Filetime
+	if err := syscall.GetProcessTimes(process, &creation, &exit, &kernel, &user); err != nil {
+		return 0, false
+	}
+	return filetimeDuration(kernel) + filetimeDuration(user), true
+}
+
+// filetimeDuration converts a FILETIME interval, counted in 100ns units, to a duration
+func filetimeDuration(ft syscall.Filetime) time.Duration {
+	return time.Duration(int64(ft.HighDateTime)<<32|int64(ft.LowDateTime)) * 100
+}
+
+// openFiles is not available on Windows
+func openFiles() int64 {
+	return 0
+}
+
+// fileLimit is not available on Windows
+func fileLimit() int64 {
+	return 0
+}
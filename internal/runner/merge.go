@@ -0,0 +1,248 @@
+package runner
+
+import (
+	"sort"
+	"time"
+)
+
+// Flush moves the results collected so far into a new Stats instance and resets them in s
+// Settings such as the start time, target rate and warm-up period stay in s
+// Used by distributed agents to stream partial results that are combined with Merge
+func (s *Stats) Flush() *Stats {
+	partial := NewStats()
+	if s.Warmup != nil {
+		partial.Warmup = s.Warmup.Flush()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	partial.TotalRequests, s.TotalRequests = s.TotalRequests, 0
+	partial.SuccessRequests, s.SuccessRequests = s.SuccessRequests, 0
+	partial.FailedRequests, s.FailedRequests = s.FailedRequests, 0
+	partial.StatusCodeCounts, s.StatusCodeCounts = s.StatusCodeCounts, make(map[int]int64)
+	partial.Latencies, s.Latencies = s.Latencies, make([]time.Duration, 0)
+	partial.ResponseTimes, s.ResponseTimes = s.ResponseTimes, nil
+	partial.SendOffsets, s.SendOffsets = s.SendOffsets, nil
+	partial.MissedSlots, s.MissedSlots = s.MissedSlots, 0
+
+	partial.URLStatusCounts, s.URLStatusCounts = s.URLStatusCounts, make(map[string]map[int]int64)
+	partial.URLFailures, s.URLFailures = s.URLFailures, make(map[string]int64)
+	partial.URLLatencies, s.URLLatencies = s.URLLatencies, make(map[string][]time.Duration)
+	partial.ErrorClassCounts, s.ErrorClassCounts = s.ErrorClassCounts, make(map[string]int64)
+	partial.BucketCounts, s.BucketCounts = s.BucketCounts, make([]int64, len(LatencyBuckets)+1)
+	partial.LatencySum, s.LatencySum = s.LatencySum, 0
+
+	partial.ErrorMessages, s.ErrorMessages = s.ErrorMessages, make(map[string]map[string]*ErrorMessage)
+	partial.distinctErrorMessages, s.distinctErrorMessages = s.distinctErrorMessages, 0
+
+	partial.SlowTraces, s.SlowTraces = s.SlowTraces, nil
+	partial.FailedTraces, s.FailedTraces = s.FailedTraces, nil
+
+	partial.NewConns, s.NewConns = s.NewConns, 0
+	partial.ReusedConns, s.ReusedConns = s.ReusedConns, 0
+	partial.ConnIdleTimes, s.ConnIdleTimes = s.ConnIdleTimes, nil
+	partial.ConnLifetimes, s.ConnLifetimes = s.ConnLifetimes, nil
+
+	partial.TLSVersionCounts, s.TLSVersionCounts = s.TLSVersionCounts, make(map[string]int64)
+	partial.TLSCipherCounts, s.TLSCipherCounts = s.TLSCipherCounts, make(map[string]int64)
+	partial.ProtocolCounts, s.ProtocolCounts = s.ProtocolCounts, make(map[string]int64)
+
+	partial.QUICHandshakes, s.QUICHandshakes = s.QUICHandshakes, nil
+	partial.QUICResumed, s.QUICResumed = s.QUICResumed, 0
+	partial.QUIC0RTT, s.QUIC0RTT = s.QUIC0RTT, 0
+
+	partial.StreamResponses, s.StreamResponses = s.StreamResponses, 0
+	partial.StreamSSE, s.StreamSSE = s.StreamSSE, 0
+	partial.StreamEventCounts, s.StreamEventCounts = s.StreamEventCounts, nil
+	partial.StreamFirstEvents, s.StreamFirstEvents = s.StreamFirstEvents, nil
+	partial.StreamGaps, s.StreamGaps = s.StreamGaps, nil
+	partial.StreamDurations, s.StreamDurations = s.StreamDurations, nil
+
+	partial.Operations, s.Operations = s.Operations, make(map[string]*OperationStats)
+	partial.CheckCounts, s.CheckCounts = s.CheckCounts, make([]CheckCount, len(s.Checks))
+
+	partial.CPUCores = s.CPUCores
+	partial.FileLimit = s.FileLimit
+	partial.CPUSamples, s.CPUSamples = s.CPUSamples, nil
+	partial.MaxGoroutines, s.MaxGoroutines = s.MaxGoroutines, 0
+	partial.GCCycles, s.GCCycles = s.GCCycles, 0
+	partial.GCPauseTotal, s.GCPauseTotal = s.GCPauseTotal, 0
+	partial.GCPauseMax, s.GCPauseMax = s.GCPauseMax, 0
+	partial.MaxOpenFiles, s.MaxOpenFiles = s.MaxOpenFiles, 0
+	partial.HealthDuration, s.HealthDuration = s.HealthDuration, 0
+	partial.SchedulerLags, s.SchedulerLags = s.SchedulerLags, LagHistogram{}
+	partial.TokenLags, s.TokenLags = s.TokenLags, LagHistogram{}
+	partial.LoopLags, s.LoopLags = s.LoopLags, LagHistogram{}
+
+	return partial
+}
+
+// Merge adds the results of a partial Stats instance (from Flush) to s
+// Settings of the partial instance are ignored; s keeps its own
+func (s *Stats) Merge(partial *Stats) {
+	if partial.Warmup != nil && s.Warmup != nil {
+		s.Warmup.Merge(partial.Warmup)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.TotalRequests += partial.TotalRequests
+	s.SuccessRequests += partial.SuccessRequests
+	s.FailedRequests += partial.FailedRequests
+	addIntCounts(s.StatusCodeCounts, partial.StatusCodeCounts)
+	s.Latencies = append(s.Latencies, partial.Latencies...)
+	s.ResponseTimes = append(s.ResponseTimes, partial.ResponseTimes...)
+	s.SendOffsets = append(s.SendOffsets, partial.SendOffsets...)
+	s.MissedSlots += partial.MissedSlots
+
+	for url, counts := range partial.URLStatusCounts {
+		urlCounts, ok := s.URLStatusCounts[url]
+		if !ok {
+			urlCounts = make(map[int]int64)
+			s.URLStatusCounts[url] = urlCounts
+		}
+		addIntCounts(urlCounts, counts)
+	}
+	addStringCounts(s.URLFailures, partial.URLFailures)
+	for url, latencies := range partial.URLLatencies {
+		s.URLLatencies[url] = append(s.URLLatencies[url], latencies...)
+	}
+	addStringCounts(s.ErrorClassCounts, partial.ErrorClassCounts)
+	for i := range s.BucketCounts {
+		if i < len(partial.BucketCounts) {
+			s.BucketCounts[i] += partial.BucketCounts[i]
+		}
+	}
+	s.LatencySum += partial.LatencySum
+
+	for class, messages := range partial.ErrorMessages {
+		for _, m := range messages {
+			s.mergeErrorMessage(class, *m)
+		}
+	}
+
+	s.SlowTraces = append(s.SlowTraces, partial.SlowTraces...)
+	sort.Slice(s.SlowTraces, func(i, j int) bool {
+		return s.SlowTraces[i].Latency > s.SlowTraces[j].Latency
+	})
+	if len(s.SlowTraces) > maxTraceSamples {
+		s.SlowTraces = s.SlowTraces[:maxTraceSamples]
+	}
+	for _, t := range partial.FailedTraces {
+		if len(s.FailedTraces) < maxTraceSamples {
+			s.FailedTraces = append(s.FailedTraces, t)
+		}
+	}
+
+	s.NewConns += partial.NewConns
+	s.ReusedConns += partial.ReusedConns
+	s.ConnIdleTimes = append(s.ConnIdleTimes, partial.ConnIdleTimes...)
+	s.ConnLifetimes = append(s.ConnLifetimes, partial.ConnLifetimes...)
+
+	addStringCounts(s.TLSVersionCounts, partial.TLSVersionCounts)
+	addStringCounts(s.TLSCipherCounts, partial.TLSCipherCounts)
+	addStringCounts(s.ProtocolCounts, partial.ProtocolCounts)
+
+	s.QUICHandshakes = append(s.QUICHandshakes, partial.QUICHandshakes...)
+	s.QUICResumed += partial.QUICResumed
+	s.QUIC0RTT += partial.QUIC0RTT
+
+	s.StreamResponses += partial.StreamResponses
+	s.StreamSSE += partial.StreamSSE
+	s.StreamEventCounts = append(s.StreamEventCounts, partial.StreamEventCounts...)
+	s.StreamFirstEvents = append(s.StreamFirstEvents, partial.StreamFirstEvents...)
+	s.StreamGaps = append(s.StreamGaps, partial.StreamGaps...)
+	s.StreamDurations = append(s.StreamDurations, partial.StreamDurations...)
+
+	for name, op := range partial.Operations {
+		existing, ok := s.Operations[name]
+		if !ok {
+			existing = &OperationStats{}
+			s.Operations[name] = existing
+		}
+		existing.Total += op.Total
+		existing.Failed += op.Failed
+		existing.GraphQLErrors += op.GraphQLErrors
+		existing.Latencies = append(existing.Latencies, op.Latencies...)
+	}
+
+	for i, c := range partial.CheckCounts {
+		if i < len(s.CheckCounts) {
+			s.CheckCounts[i].Passes += c.Passes
+			s.CheckCounts[i].Fails += c.Fails
+		}
+	}
+
+	// Health samples of several generators: the worst of each, totals for GC
+	if partial.CPUCores > s.CPUCores {
+		s.CPUCores = partial.CPUCores
+	}
+	if partial.FileLimit > 0 && (s.FileLimit == 0 || partial.FileLimit < s.FileLimit) {
+		s.FileLimit = partial.FileLimit
+	}
+	s.CPUSamples = append(s.CPUSamples, partial.CPUSamples...)
+	if partial.MaxGoroutines > s.MaxGoroutines {
+		s.MaxGoroutines = partial.MaxGoroutines
+	}
+	s.GCCycles += partial.GCCycles
+	s.GCPauseTotal += partial.GCPauseTotal
+	if partial.GCPauseMax > s.GCPauseMax {
+		s.GCPauseMax = partial.GCPauseMax
+	}
+	if partial.MaxOpenFiles > s.MaxOpenFiles {
+		s.MaxOpenFiles = partial.MaxOpenFiles
+	}
+	s.HealthDuration += partial.HealthDuration
+	s.SchedulerLags.merge(partial.SchedulerLags)
+	s.TokenLags.merge(partial.TokenLags)
+	s.LoopLags.merge(partial.LoopLags)
+}
+
+// mergeErrorMessage adds the count of an error message from another instance
+func (s *Stats) mergeErrorMessage(class string, m ErrorMessage) {
+	messages, ok := s.ErrorMessages[class]
+	if !ok {
+		messages = make(map[string]*ErrorMessage)
+		s.ErrorMessages[class] = messages
+	}
+
+	entry, ok := messages[m.Message]
+	if !ok {
+		if s.distinctErrorMessages >= maxErrorMessages {
+			m.Message = otherErrorMessage
+			entry, ok = messages[m.Message]
+		}
+		if !ok {
+			entry = &ErrorMessage{Message: m.Message, FirstSeen: m.FirstSeen}
+			messages[m.Message] = entry
+			s.distinctErrorMessages++
+		}
+	}
+	entry.Count += m.Count
+	if m.FirstSeen.Before(entry.FirstSeen) {
+		entry.FirstSeen = m.FirstSeen
+	}
+}
+
+// FinalizeAt marks the end of the test at the given time
+func (s *Stats) FinalizeAt(end time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.EndTime = end
+}
+
+// addIntCounts adds the counts of src to dst
+func addIntCounts(dst, src map[int]int64) {
+	for key, count := range src {
+		dst[key] += count
+	}
+}
+
+// addStringCounts adds the counts of src to dst
+func addStringCounts(dst, src map[string]int64) {
+	for key, count := range src {
+		dst[key] += count
+	}
+}
@@ -0,0 +1,95 @@
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// mergeTestResults returns the results of agent i of a distributed run
+func mergeTestResults(i int, start time.Time) []Result {
+	var results []Result
+	for j := 0; j < 50; j++ {
+		r := Result{
+			URL:        "http://a/",
+			Latency:    time.Duration(i*50+j+1) * time.Millisecond,
+			StatusCode: 200,
+			SentAt:     start.Add(time.Duration(j) * 10 * time.Millisecond),
+			Protocol:   "HTTP/1.1",
+		}
+		switch {
+		case j%10 == 0:
+			r.URL = "http://b/"
+			r.StatusCode = 500
+		case j%17 == 0:
+			r.StatusCode = 0
+			r.Error = errors.New("dial tcp: connection refused")
+			r.Protocol = ""
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+func TestStatsFlushAndMerge(t *testing.T) {
+	start := time.Now()
+	direct := NewStats()
+	combined := NewStats()
+	combined.StartTime = start
+
+	for i := 0; i < 3; i++ {
+		agent := NewStats()
+		agent.StartTime = start
+		for _, r := range mergeTestResults(i, start) {
+			agent.AddResult(r)
+			direct.AddResult(r)
+		}
+
+		// Partial results travel as JSON from the agents to the controller
+		data, err := json.Marshal(agent.Flush())
+		if err != nil {
+			t.Fatal(err)
+		}
+		partial := NewStats()
+		if err := json.Unmarshal(data, partial); err != nil {
+			t.Fatal(err)
+		}
+		combined.Merge(partial)
+
+		if agent.TotalRequests != 0 || len(agent.Latencies) != 0 || len(agent.StatusCodeCounts) != 0 {
+			t.Errorf("agent %d still holds results after Flush", i)
+		}
+	}
+
+	got, want := combined.GetSummary(), direct.GetSummary()
+	if got.TotalRequests != 150 || got.TotalRequests != want.TotalRequests || got.FailedRequests != want.FailedRequests {
+		t.Errorf("merged %d requests with %d failures, want %d with %d", got.TotalRequests, got.FailedRequests, want.TotalRequests, want.FailedRequests)
+	}
+	if !reflect.DeepEqual(got.StatusCodeCounts, want.StatusCodeCounts) {
+		t.Errorf("status codes = %v, want %v", got.StatusCodeCounts, want.StatusCodeCounts)
+	}
+	if got.MinLatency != want.MinLatency || got.MaxLatency != want.MaxLatency || got.P90Latency != want.P90Latency || got.P99Latency != want.P99Latency {
+		t.Errorf("latencies = %s..%s p90 %s p99 %s, want %s..%s p90 %s p99 %s",
+			got.MinLatency, got.MaxLatency, got.P90Latency, got.P99Latency,
+			want.MinLatency, want.MaxLatency, want.P90Latency, want.P99Latency)
+	}
+	if !reflect.DeepEqual(got.Endpoints, want.Endpoints) {
+		t.Errorf("endpoints = %+v, want %+v", got.Endpoints, want.Endpoints)
+	}
+	// First-seen times differ: each instance records when it saw the error
+	for _, summary := range []*Summary{&got, &want} {
+		for _, class := range summary.Errors {
+			for i := range class.Messages {
+				class.Messages[i].FirstSeen = time.Time{}
+			}
+		}
+	}
+	if !reflect.DeepEqual(got.Errors, want.Errors) {
+		t.Errorf("errors = %+v, want %+v", got.Errors, want.Errors)
+	}
+	if !reflect.DeepEqual(got.Protocols, want.Protocols) {
+		t.Errorf("protocols = %v, want %v", got.Protocols, want.Protocols)
+	}
+}
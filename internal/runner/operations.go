@@ -0,0 +1,30 @@
+package runner
+
+import (
+	"sync/atomic"
+
+	"github.com/calummacc/g0/internal/graphql"
+)
+
+// OperationRotator provides round-robin selection of GraphQL operations
+type OperationRotator struct {
+	operations []graphql.Operation
+	idx        int64 // Atomic counter for round-robin selection
+}
+
+// NewOperationRotator creates a rotator over operations, or returns nil if there are none
+func NewOperationRotator(operations []graphql.Operation) *OperationRotator {
+	if len(operations) == 0 {
+		return nil
+	}
+	return &OperationRotator{operations: operations}
+}
+
+// Next returns the next operation in round-robin fashion, or nil without operations
+func (r *OperationRotator) Next() *graphql.Operation {
+	if r == nil {
+		return nil
+	}
+	idx := atomic.AddInt64(&r.idx, 1) - 1
+	return &r.operations[int(idx%int64(len(r.operations)))]
+}
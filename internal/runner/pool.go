@@ -0,0 +1,75 @@
+package runner
+
+import (
+	"context"
+	"sync"
+)
+
+// workerPool runs the workers of a load test and changes their number while it runs
+type workerPool struct {
+	ctx       context.Context
+	newWorker func() *Worker
+	stats     *Stats
+	hosts     int // Distinct hosts, for the expected number of connections
+
+	mu     sync.Mutex
+	retire []context.CancelFunc // One per running worker, oldest first
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// newWorkerPool creates an empty pool whose workers run until ctx is done
+func newWorkerPool(ctx context.Context, newWorker func() *Worker, stats *Stats, hosts int) *workerPool {
+	return &workerPool{ctx: ctx, newWorker: newWorker, stats: stats, hosts: hosts}
+}
+
+// resize starts or retires workers until n are running, retiring the newest first
+// Retired workers finish their request in flight before they stop
+func (p *workerPool) resize(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return
+	}
+
+	for len(p.retire) < n {
+		retire, cancel := context.WithCancel(p.ctx)
+		p.retire = append(p.retire, cancel)
+		worker := p.newWorker()
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			worker.StartUntil(p.ctx, retire)
+		}()
+	}
+	for len(p.retire) > n {
+		last := len(p.retire) - 1
+		p.retire[last]()
+		p.retire = p.retire[:last]
+	}
+
+	// More workers keep more connections open, which is not churn
+	p.stats.mu.Lock()
+	if expected := int64(n * p.hosts); p.stats.ExpectedConnections > 0 && expected > p.stats.ExpectedConnections {
+		p.stats.ExpectedConnections = expected
+	}
+	p.stats.mu.Unlock()
+}
+
+// size returns the number of running workers
+func (p *workerPool) size() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.retire)
+}
+
+// wait stops further resizing and waits for every worker to stop
+func (p *workerPool) wait() {
+	p.mu.Lock()
+	p.closed = true
+	for _, cancel := range p.retire {
+		cancel()
+	}
+	p.mu.Unlock()
+	p.wg.Wait()
+}
@@ -2,85 +2,250 @@ package runner
 
 import (
 	"context"
+	"math/rand"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
-// RateLimiter implements a token bucket rate limiter
-// It ensures that requests don't exceed the specified rate per second
+// RateLimiter paces requests on a virtual clock (GCRA-style)
+// Instead of refilling tokens from a ticker, it computes when each request is due from the
+// elapsed time: each slot follows the previous one by a gap drawn from the arrival
+// distribution (exactly one interval for constant arrivals). A request may be released up to
+// (burst-1) intervals ahead of its slot. A schedule that fell behind (the target stalled or
+// every worker was busy) restarts from the current time, so missed slots are dropped rather
+// than sent back-to-back and no more than burst requests are ever released at once; the
+// dropped slots are handed out with the next slot so they can still be accounted for
 type RateLimiter struct {
-	tokens   chan struct{}
-	interval time.Duration
-	ctx      context.Context
-	cancel   context.CancelFunc
+	mu        sync.Mutex
+	start     time.Time
+	interval  float64 // Nanoseconds between scheduled requests (fractional for non-integer rates)
+	next      float64 // Offset of the next slot from start, in nanoseconds
+	tolerance float64 // How far ahead of its slot a request may be released, in nanoseconds
+	burst     int
+	unlimited atomic.Bool // Every request is let through (until SetRate sets a rate); read without mu
+	arrival   Arrival
+	rng       *rand.Rand // Guarded by mu
+	pausedTo  time.Time  // No request is released before this time (set by PauseUntil)
+
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
-// NewRateLimiter creates a new rate limiter with the specified max RPS
+// NewRateLimiter creates a new rate limiter with the specified max RPS, burst size and arrival distribution
+// maxRPS may be fractional (e.g. 0.5 = one request every 2s); burst is the number of requests
+// that may be sent back-to-back (values below 1 are treated as 1)
 // If maxRPS is 0 or negative, rate limiting is disabled (returns nil)
-func NewRateLimiter(maxRPS int) *RateLimiter {
+func NewRateLimiter(maxRPS float64, burst int, arrival Arrival) *RateLimiter {
 	if maxRPS <= 0 {
 		return nil // No rate limiting
 	}
+	if burst < 1 {
+		burst = 1
+	}
 
+	interval := float64(time.Second) / maxRPS
 	ctx, cancel := context.WithCancel(context.Background())
-	rl := &RateLimiter{
-		tokens:   make(chan struct{}, maxRPS), // Buffer allows burst up to maxRPS
-		interval: time.Second / time.Duration(maxRPS),
-		ctx:      ctx,
-		cancel:   cancel,
+	return &RateLimiter{
+		start:     time.Now(),
+		interval:  interval,
+		tolerance: float64(burst-1) * interval,
+		burst:     burst,
+		arrival:   arrival,
+		rng:       rand.New(rand.NewSource(time.Now().UnixNano())),
+		ctx:       ctx,
+		cancel:    cancel,
 	}
+}
+
+// newUnlimitedRateLimiter creates a rate limiter that lets every request through until SetRate
+// sets a rate, for runs whose rate may be limited later on
+func newUnlimitedRateLimiter(burst int, arrival Arrival) *RateLimiter {
+	rl := NewRateLimiter(1, burst, arrival)
+	rl.unlimited.Store(true)
+	return rl
+}
+
+// Slot is a place in the rate schedule handed out by the rate limiter
+type Slot struct {
+	Due time.Time     // When the request is due (zero without rate limiting)
+	Lag time.Duration // How late the caller woke up after the slot was released (0 if no wait was needed)
 
-	// Pre-fill the bucket with tokens
-	for i := 0; i < maxRPS; i++ {
-		rl.tokens <- struct{}{}
+	// Slots dropped just before this one because the schedule fell behind: the first was due
+	// at MissedFrom and the others followed every MissedGap (on average for random arrivals)
+	Missed     int
+	MissedFrom time.Time
+	MissedGap  time.Duration
+}
+
+// reserve claims the next slot and returns it with the earliest release time
+// No slot is claimed while the limiter is unlimited
+func (rl *RateLimiter) reserve() (slot Slot, release time.Time, unlimited bool) {
+	// Unlimited runs never contend for the lock; the flag is checked again under it
+	// in case SetRate changed it in between
+	if rl.unlimited.Load() {
+		return Slot{}, time.Time{}, true
+	}
+	rl.mu.Lock()
+	if rl.unlimited.Load() {
+		rl.mu.Unlock()
+		return Slot{}, time.Time{}, true
+	}
+	// Releasing the slots missed while behind would be an unbounded burst, so the schedule
+	// restarts now; with the tolerance, at most burst requests are then released at once
+	if now := float64(time.Since(rl.start)); rl.next < now {
+		slot.Missed = int((now - rl.next) / rl.interval)
+		slot.MissedFrom = rl.start.Add(time.Duration(rl.next))
+		slot.MissedGap = time.Duration(rl.interval)
+		rl.next = now
 	}
+	offset := rl.next
+	tolerance := rl.tolerance
+	rl.next += rl.arrival.gap(rl.interval, rl.rng)
+	rl.mu.Unlock()
 
-	// Start token refill goroutine
-	go rl.refill()
+	slot.Due = rl.start.Add(time.Duration(offset))
+	release = rl.start.Add(time.Duration(offset - tolerance))
+	return slot, release, false
+}
 
-	return rl
+// SetRate changes the rate for slots that have not been claimed yet
+// A rate of 0 or less lets every request through until a rate is set again
+func (rl *RateLimiter) SetRate(maxRPS float64) {
+	if rl == nil {
+		return
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if maxRPS <= 0 {
+		rl.unlimited.Store(true)
+		return
+	}
+	interval := float64(time.Second) / maxRPS
+	// The schedule starts afresh when a limit is set again; no slot was missed while unlimited
+	if now := float64(time.Since(rl.start)); rl.unlimited.Load() && rl.next < now {
+		rl.next = now
+	}
+	rl.tolerance = float64(rl.burst-1) * interval
+	rl.interval = interval
+	rl.unlimited.Store(false)
 }
 
-// refill continuously adds tokens to the bucket at the specified rate
-func (rl *RateLimiter) refill() {
-	ticker := time.NewTicker(rl.interval)
-	defer ticker.Stop()
+// Rate returns the current rate (0 if unlimited)
+func (rl *RateLimiter) Rate() float64 {
+	if rl == nil {
+		return 0
+	}
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if rl.unlimited.Load() {
+		return 0
+	}
+	return float64(time.Second) / rl.interval
+}
 
-	for {
-		select {
-		case <-rl.ctx.Done():
-			return
-		case <-ticker.C:
-			// Try to add a token, but don't block if bucket is full
-			select {
-			case rl.tokens <- struct{}{}:
-			default:
-				// Bucket is full, skip
-			}
-		}
+// PauseUntil holds back all requests, including already scheduled ones, until t
+func (rl *RateLimiter) PauseUntil(t time.Time) {
+	if rl == nil {
+		return
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if t.After(rl.pausedTo) {
+		rl.pausedTo = t
 	}
+	if offset := float64(t.Sub(rl.start)) + rl.tolerance; offset > rl.next {
+		rl.next = offset
+	}
+}
+
+// pausedUntil returns the end of the current pause (zero if never paused)
+func (rl *RateLimiter) pausedUntil() time.Time {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return rl.pausedTo
 }
 
-// Wait blocks until a token is available, ensuring rate limit is respected
+// Wait blocks until the next scheduled slot, ensuring rate limit is respected
+// It returns the time the request was due according to the rate schedule, which is zero if
+// rate limiting is disabled
 // Returns false if context is cancelled
-func (rl *RateLimiter) Wait(ctx context.Context) bool {
+func (rl *RateLimiter) Wait(ctx context.Context) (time.Time, bool) {
+	slot, ok := rl.WaitSlot(ctx)
+	return slot.Due, ok
+}
+
+// WaitSlot is Wait that returns the whole slot, including how late the caller woke up
+// (a consistently late wake-up means the load generator is overloaded) and the slots dropped
+// before it
+func (rl *RateLimiter) WaitSlot(ctx context.Context) (Slot, bool) {
 	if rl == nil {
-		return true // No rate limiting, proceed immediately
+		return Slot{}, true // No rate limiting, proceed immediately
+	}
+
+	slot, release, unlimited := rl.reserve()
+	if unlimited {
+		// Not scheduled, so there is no due time: proceed unless stopped
+		return Slot{}, rl.sleepUntil(ctx, time.Time{})
 	}
 
+	for {
+		sleeping := time.Now().Before(release)
+		if !rl.sleepUntil(ctx, release) {
+			return Slot{}, false
+		}
+		if sleeping {
+			slot.Lag = time.Since(release)
+		}
+
+		// A pause requested after the slot was claimed (e.g. Retry-After) still applies;
+		// the request is then due when the pause ends rather than at its original slot
+		paused := rl.pausedUntil()
+		if !time.Now().Before(paused) {
+			return slot, true // Slot reached, proceed
+		}
+		if slot.Due.Before(paused) {
+			slot.Due = paused
+		}
+		release = paused
+	}
+}
+
+// sleepUntil blocks until t, returning false if either context is cancelled first
+func (rl *RateLimiter) sleepUntil(ctx context.Context, t time.Time) bool {
+	wait := time.Until(t)
+	if wait <= 0 {
+		// Slot already reached (or behind schedule): proceed unless stopped
+		select {
+		case <-ctx.Done():
+			return false
+		case <-rl.ctx.Done():
+			return false
+		default:
+			return true
+		}
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
 	select {
 	case <-ctx.Done():
 		return false
 	case <-rl.ctx.Done():
 		return false
-	case <-rl.tokens:
-		return true // Token acquired, proceed
+	case <-timer.C:
+		return true
 	}
 }
 
-// Stop stops the rate limiter
+// Stop stops the rate limiter, releasing any waiting workers
 func (rl *RateLimiter) Stop() {
 	if rl != nil {
 		rl.cancel()
 	}
 }
-
@@ -0,0 +1,164 @@
+package runner
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterSchedule(t *testing.T) {
+	arrivals := []Arrival{
+		{Kind: ArrivalConstant},
+		{Kind: ArrivalUniform, Jitter: 0.5},
+		{Kind: ArrivalPoisson},
+	}
+	const slots = 100000
+	for _, rate := range []float64{0.5, 1, 10, 1000, 100000, 500000} {
+		for _, arrival := range arrivals {
+			rl := NewRateLimiter(rate, 1, arrival)
+			rl.rng = rand.New(rand.NewSource(1))
+			// Keep the schedule ahead of the clock so that no slot is dropped
+			rl.start = rl.start.Add(time.Hour)
+
+			first, _, _ := rl.reserve()
+			last := first
+			for i := 1; i < slots; i++ {
+				slot, _, _ := rl.reserve()
+				if slot.Missed != 0 {
+					t.Fatalf("%.1f/s %s: slot %d dropped %d slots", rate, arrival, i, slot.Missed)
+				}
+				last = slot
+			}
+
+			achieved := float64(slots-1) / last.Due.Sub(first.Due).Seconds()
+			if math.Abs(achieved-rate)/rate > 0.01 {
+				t.Errorf("%.1f/s %s: scheduled %.3f/s, want within 1%%", rate, arrival, achieved)
+			}
+		}
+	}
+}
+
+func TestRateLimiterWallClock(t *testing.T) {
+	const (
+		rate     = 5000
+		workers  = 32
+		duration = time.Second
+	)
+	rl := NewRateLimiter(rate, 1, Arrival{Kind: ArrivalConstant})
+	defer rl.Stop()
+	ctx, cancel := context.WithDeadline(context.Background(), rl.start.Add(duration))
+	defer cancel()
+
+	var sent, missed atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				slot, ok := rl.WaitSlot(ctx)
+				if !ok {
+					return
+				}
+				sent.Add(1)
+				missed.Add(int64(slot.Missed))
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Slots are dropped when the workers are starved of CPU (e.g. by other tests running in
+	// parallel), so the schedule is checked by the slots handed out or dropped
+	want := rate * duration.Seconds()
+	if got := float64(sent.Load() + missed.Load()); math.Abs(got-want)/want > 0.01 {
+		t.Errorf("scheduled %.0f slots in %s (%d sent, %d dropped), want %.0f within 1%%", got, duration, sent.Load(), missed.Load(), want)
+	}
+	if missed.Load() > sent.Load()/10 {
+		t.Logf("%d of %d slots dropped: the workers were starved of CPU", missed.Load(), sent.Load()+missed.Load())
+	}
+}
+
+func TestRateLimiterDropsMissedSlots(t *testing.T) {
+	const burst = 5
+	rl := NewRateLimiter(100, burst, Arrival{Kind: ArrivalConstant})
+	// No slot was claimed for a second, as if every worker had been stuck on a stalled target
+	rl.start = rl.start.Add(-time.Second)
+
+	released := 0
+	for i := 0; i < 50; i++ {
+		slot, release, _ := rl.reserve()
+		if i == 0 && slot.Missed != 100 {
+			t.Errorf("first slot after the stall dropped %d slots, want 100", slot.Missed)
+		}
+		if i > 0 && slot.Missed != 0 {
+			t.Errorf("slot %d dropped %d slots, want 0", i, slot.Missed)
+		}
+		if release.After(time.Now()) {
+			break
+		}
+		released++
+	}
+	if released != burst {
+		t.Errorf("released %d requests at once after the stall, want %d (the burst)", released, burst)
+	}
+}
+
+func TestRateLimiterSetRateAfterUnlimited(t *testing.T) {
+	rl := newUnlimitedRateLimiter(1, Arrival{Kind: ArrivalConstant})
+	rl.start = rl.start.Add(-time.Second)
+
+	rl.SetRate(100)
+	if slot, _, _ := rl.reserve(); slot.Missed != 0 {
+		t.Errorf("first slot after setting a rate dropped %d slots, want 0", slot.Missed)
+	}
+}
+
+func TestUnlimitedRateLimiterSkipsTheLock(t *testing.T) {
+	rl := newUnlimitedRateLimiter(1, Arrival{Kind: ArrivalConstant})
+	defer rl.Stop()
+	control := NewControl()
+
+	// Unlimited runs with --ui or --control go through both on every request; neither may
+	// serialize the workers on a mutex
+	rl.mu.Lock()
+	control.mu.Lock()
+	defer rl.mu.Unlock()
+	defer control.mu.Unlock()
+
+	done := make(chan bool)
+	go func() {
+		_, ok := rl.WaitSlot(context.Background())
+		done <- ok && control.wait(context.Background())
+	}()
+	select {
+	case ok := <-done:
+		if !ok {
+			t.Error("request was not let through")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("an unlimited, unpaused request waited for a lock")
+	}
+}
+
+func BenchmarkRateLimiter(b *testing.B) {
+	const rate = 500000
+	rl := NewRateLimiter(rate, 1, Arrival{Kind: ArrivalConstant})
+	defer rl.Stop()
+
+	var missed atomic.Int64
+	b.SetParallelism(64)
+	b.ResetTimer()
+	start := time.Now()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			slot, _ := rl.WaitSlot(context.Background())
+			missed.Add(int64(slot.Missed))
+		}
+	})
+	b.ReportMetric(float64(b.N)/time.Since(start).Seconds(), "req/s")
+	b.ReportMetric(float64(missed.Load()), "missed")
+}
@@ -3,9 +3,11 @@ package runner
 import (
 	"context"
 	"fmt"
-	"sync"
+	"net/url"
 	"time"
 
+	"github.com/calummacc/g0/internal/check"
+	"github.com/calummacc/g0/internal/graphql"
 	"github.com/calummacc/g0/internal/httpclient"
 )
 
@@ -13,11 +15,40 @@ import (
 type Config struct {
 	URLs        []string // URLs to test (supports multiple endpoints)
 	Concurrency int
-	Duration    time.Duration
+	Duration    time.Duration // Measured duration, after any warm-up
+	Warmup      time.Duration // Load is generated but results are excluded from the main statistics
 	Method      string
 	Body        string
 	Headers     map[string]string
-	MaxRPS      int // Maximum requests per second (0 = no limit)
+	MaxRPS      float64 // Maximum requests per second, may be fractional (0 = no limit)
+	Burst       int     // Requests that may be sent back-to-back under MaxRPS (default 1)
+	Arrival     Arrival // Distribution of gaps between requests under MaxRPS (default constant)
+	Adaptive    bool    // Back off on 429/503 and rising errors, treating MaxRPS as the ceiling
+
+	TraceSampleRate    float64       // Fraction of requests that carry a W3C traceparent header (0 = disabled)
+	TraceSlowThreshold time.Duration // Sampled requests at least this slow have their trace ID reported
+
+	TopErrors int // Number of most frequent error messages reported per error class (0 = default of 5)
+
+	Stream bool // Read response bodies incrementally and report event timings (SSE or chunked)
+
+	GraphQL []graphql.Operation // Operations sent in turn as the POST body, replacing Body (optional)
+
+	Checks []check.Check // Assertions evaluated against every response (optional)
+
+	Transport httpclient.TransportOptions // Timeouts, connection pool and redirects (idle pool defaults to Concurrency)
+
+	Control *Control `json:"-"` // Pauses, resumes or stops the run early (optional)
+}
+
+// HTTPTransport returns the transport options the run uses, with defaults filled in
+// The idle pool defaults to one connection per worker so connections are not churned
+func (c Config) HTTPTransport() httpclient.TransportOptions {
+	transport := c.Transport
+	if transport.MaxIdleConnsPerHost <= 0 {
+		transport.MaxIdleConnsPerHost = c.Concurrency
+	}
+	return transport.WithDefaults()
 }
 
 // RunResult contains both the stats instance (for progress monitoring) and the final summary
@@ -48,22 +79,42 @@ func RunWithStatsAndChannel(config Config, statsChan chan<- *Stats) (*RunResult,
 		return nil, fmt.Errorf("at least one URL is required")
 	}
 
+	// Create context with timeout (warm-up runs before the measured duration)
+	ctx, cancel := context.WithTimeout(context.Background(), config.Warmup+config.Duration)
+	defer cancel()
+
+	// Create stats collector
+	stats := NewRunStats(config)
+
 	// Create HTTP client
-	client := httpclient.New()
+	client, err := httpclient.New(httpclient.Options{
+		TraceSampleRate: config.TraceSampleRate,
+		Transport:       config.HTTPTransport(),
+		Stream:          config.Stream,
+		ConnClosed: func(lifetime time.Duration) {
+			// Connections torn down by cancelling in-flight requests at the end are not churn
+			if ctx.Err() == nil {
+				stats.ConnectionClosed(lifetime)
+			}
+		},
+		QUICHandshake: func(h httpclient.QUICHandshake) {
+			if ctx.Err() == nil {
+				stats.QUICHandshakeDone(h)
+			}
+		},
+	})
+
+	if err != nil {
+		return nil, err
+	}
 
 	// Create URL rotator for round-robin distribution
 	urlRotator := NewURLRotator(config.URLs)
-
-	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), config.Duration)
-	defer cancel()
+	operations := NewOperationRotator(config.GraphQL)
 
 	// Create results channel
 	results := make(chan Result, config.Concurrency*10)
 
-	// Create stats collector
-	stats := NewStats()
-
 	// Send stats instance to channel if provided (for progress monitoring)
 	if statsChan != nil {
 		select {
@@ -73,6 +124,21 @@ func RunWithStatsAndChannel(config Config, statsChan chan<- *Stats) (*RunResult,
 		}
 	}
 
+	// Create rate limiter if MaxRPS is specified
+	var rateLimiter *RateLimiter
+	var adaptive *AdaptiveController
+	if config.MaxRPS > 0 {
+		rateLimiter = NewRateLimiter(config.MaxRPS, config.Burst, config.Arrival)
+		defer rateLimiter.Stop()
+		if config.Adaptive {
+			adaptive = NewAdaptiveController(rateLimiter, config.MaxRPS)
+		}
+	} else if config.Control != nil {
+		// The control may set a rate later on
+		rateLimiter = newUnlimitedRateLimiter(config.Burst, config.Arrival)
+		defer rateLimiter.Stop()
+	}
+
 	// Start stats collector goroutine
 	statsDone := make(chan struct{})
 	go func() {
@@ -83,6 +149,7 @@ func RunWithStatsAndChannel(config Config, statsChan chan<- *Stats) (*RunResult,
 				if !ok {
 					return
 				}
+				adaptive.Observe(result)
 				stats.AddResult(result)
 			case <-ctx.Done():
 				// Drain remaining results after context is done
@@ -98,37 +165,33 @@ func RunWithStatsAndChannel(config Config, statsChan chan<- *Stats) (*RunResult,
 		}
 	}()
 
-	// Create rate limiter if MaxRPS is specified
-	var rateLimiter *RateLimiter
-	if config.MaxRPS > 0 {
-		rateLimiter = NewRateLimiter(config.MaxRPS)
-		defer rateLimiter.Stop()
-	}
-
-	// Use WaitGroup to wait for all workers to finish
-	var wg sync.WaitGroup
+	// Sample the load generator's own resource usage while it runs
+	health := StartHealthMonitor(stats)
 
-	// Start workers
-	for i := 0; i < config.Concurrency; i++ {
-		wg.Add(1)
+	// Start workers (the control may change their number while the test runs)
+	pool := newWorkerPool(ctx, func() *Worker {
 		// Create base request configuration (URL will be selected dynamically)
 		baseRequest := httpclient.Request{
-			Method:  config.Method,
-			Body:    config.Body,
-			Headers: config.Headers,
+			Method:   config.Method,
+			Body:     config.Body,
+			Headers:  config.Headers,
+			KeepBody: check.NeedsBody(config.Checks),
 		}
-		worker := NewWorker(client, baseRequest, results, rateLimiter, urlRotator)
-		go func() {
-			defer wg.Done()
-			worker.Start(ctx)
-		}()
+		return NewWorker(client, baseRequest, results, rateLimiter, urlRotator, operations, config.Checks, stats, config.Control)
+	}, stats, countHosts(config.URLs))
+	pool.resize(config.Concurrency)
+	config.Control.attach(rateLimiter, pool, stats)
+
+	// Wait for duration to complete, or for the run to be stopped early
+	select {
+	case <-ctx.Done():
+	case <-config.Control.Stopped():
+		cancel()
 	}
 
-	// Wait for duration to complete
-	<-ctx.Done()
-
 	// Wait for all workers to finish (they will stop when ctx.Done() is triggered)
-	wg.Wait()
+	pool.wait()
+	health.Stop()
 
 	// Close results channel to signal stats collector to finish
 	// This is safe now because all workers have stopped
@@ -142,6 +205,7 @@ func RunWithStatsAndChannel(config Config, statsChan chan<- *Stats) (*RunResult,
 
 	// Get summary
 	summary := stats.GetSummary()
+	summary.Adaptive = adaptive.Summary()
 
 	return &RunResult{
 		Stats:   stats,
@@ -149,3 +213,33 @@ func RunWithStatsAndChannel(config Config, statsChan chan<- *Stats) (*RunResult,
 	}, nil
 }
 
+// NewRunStats creates a stats collector configured for a load test, starting now
+func NewRunStats(config Config) *Stats {
+	stats := NewStats()
+	stats.SetWarmup(config.Warmup)
+	stats.TraceSlowThreshold = config.TraceSlowThreshold
+	if !config.Transport.DisableKeepAlives {
+		stats.ExpectedConnections = int64(config.Concurrency * countHosts(config.URLs))
+	}
+	stats.TargetRate = config.MaxRPS
+	stats.Arrival = config.Arrival.String()
+	stats.SetChecks(config.Checks)
+	if config.TopErrors > 0 {
+		stats.ErrorMessageLimit = config.TopErrors
+	}
+	return stats
+}
+
+// countHosts returns the number of distinct hosts among urls (at least 1)
+func countHosts(urls []string) int {
+	hosts := make(map[string]bool)
+	for _, raw := range urls {
+		if u, err := url.Parse(raw); err == nil {
+			hosts[u.Host] = true
+		}
+	}
+	if len(hosts) == 0 {
+		return 1
+	}
+	return len(hosts)
+}
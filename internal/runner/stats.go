@@ -1,15 +1,80 @@
 package runner
 
 import (
+	"crypto/tls"
+	"maps"
+	"math"
+	"slices"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/calummacc/g0/internal/check"
+	"github.com/calummacc/g0/internal/httpclient"
 )
 
 // Result represents a single request result
 type Result struct {
+	URL        string // URL the request was sent to
 	Latency    time.Duration
 	StatusCode int
 	Error      error
+	TraceID    string // Set if the request was sampled for tracing
+
+	// ResponseTime is measured from the intended send time of the rate schedule
+	// (service time plus time spent queued); zero if no rate limit is set
+	ResponseTime time.Duration
+
+	// Rate schedule slots dropped just before this request because the schedule fell behind
+	// (see Slot); each is recorded with the response time it would have had
+	Missed     int
+	MissedFrom time.Time
+	MissedGap  time.Duration
+
+	SentAt     time.Time     // When the request was actually sent
+	RetryAfter time.Duration // Retry-After delay of a 429/503 response
+
+	Conn     httpclient.ConnInfo    // New or reused connection
+	Protocol string                 // Negotiated HTTP protocol, e.g. "HTTP/2.0" (empty on error)
+	Stream   *httpclient.StreamInfo // Body events in streaming mode (nil otherwise)
+
+	Operation string // GraphQL operation name (empty without GraphQL)
+	Checks    []bool // Outcome of each configured check, in order (nil without checks)
+
+	TokenLag time.Duration // How late the rate limiter woke the worker for its slot
+	LoopLag  time.Duration // Worker time outside of the request and rate limiting
+}
+
+// maxTraceSamples caps how many slow and failed trace IDs are kept for the report
+const maxTraceSamples = 10
+
+// TraceSample links a slow or failed request to its server-side trace
+type TraceSample struct {
+	TraceID    string
+	URL        string
+	StatusCode int
+	Latency    time.Duration
+	Error      string
+	Time       time.Time
+}
+
+// LatencyBuckets are the upper bounds of the latency histogram kept by Stats
+// Results slower than the last bound are counted in an extra overflow bucket
+var LatencyBuckets = []time.Duration{
+	1 * time.Millisecond,
+	2500 * time.Microsecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	1 * time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
 }
 
 // Stats aggregates statistics from all requests
@@ -21,26 +86,191 @@ type Stats struct {
 	FailedRequests   int64
 	StatusCodeCounts map[int]int64
 	Latencies        []time.Duration
+	ResponseTimes    []time.Duration // Coordinated-omission-corrected latencies (rate-limited runs only)
+	SendOffsets      []time.Duration // Send times relative to StartTime (rate-limited runs only)
+	MissedSlots      int64           // Rate schedule slots dropped while the schedule was behind
 	StartTime        time.Time
 	EndTime          time.Time
+
+	URLStatusCounts  map[string]map[int]int64 // Status code counts per URL (0 = network error)
+	URLFailures      map[string]int64         // Failed requests per URL, including errors in a 2xx response (e.g. GraphQL)
+	URLLatencies     map[string][]time.Duration
+	ErrorClassCounts map[string]int64 // Network error counts per ErrorClass
+	BucketCounts     []int64          // Latency histogram, one count per LatencyBuckets bound plus overflow
+	LatencySum       time.Duration
+
+	ErrorMessages         map[string]map[string]*ErrorMessage // Distinct error messages per ErrorClass
+	ErrorMessageLimit     int                                 // Number of top messages per class reported in the summary
+	distinctErrorMessages int                                 // Number of entries across ErrorMessages
+
+	TargetRate float64 // Requested rate under --max-rps (0 = unlimited, send times not recorded)
+	Arrival    string  // Arrival distribution used for pacing
+
+	TraceSlowThreshold time.Duration // Minimum latency for a sampled request to count as slow
+	SlowTraces         []TraceSample // Slowest sampled requests, slowest first
+	FailedTraces       []TraceSample // First sampled failed requests
+
+	NewConns            int64           // Requests that opened a new connection
+	ReusedConns         int64           // Requests sent on a kept-alive connection
+	ConnIdleTimes       []time.Duration // Idle time of reused connections before each reuse
+	ConnLifetimes       []time.Duration // Lifetimes of connections closed during the run
+	ExpectedConnections int64           // New connections expected with keep-alive, usually one per worker (0 = no check)
+
+	TLSVersionCounts map[string]int64 // Successful TLS handshakes per negotiated version
+	TLSCipherCounts  map[string]int64 // Successful TLS handshakes per negotiated cipher suite
+	ProtocolCounts   map[string]int64 // Responses per negotiated HTTP protocol
+
+	QUICHandshakes []time.Duration // Durations of completed QUIC handshakes (HTTP/3)
+	QUICResumed    int64           // QUIC handshakes that resumed an earlier TLS session
+	QUIC0RTT       int64           // QUIC handshakes whose 0-RTT early data was accepted
+
+	GRPC bool // StatusCode holds gRPC status codes (0 = OK) rather than HTTP codes
+
+	StreamResponses   int64           // Successful responses read in streaming mode
+	StreamSSE         int64           // Of which were text/event-stream
+	StreamEventCounts []int64         // Events per streamed response
+	StreamFirstEvents []time.Duration // Time to first event of streamed responses with events
+	StreamGaps        []time.Duration // Gaps between consecutive events
+	StreamDurations   []time.Duration // From sending the request to the end of the stream
+
+	Operations map[string]*OperationStats // Per GraphQL operation
+
+	Checks      []string     // Expressions of the configured checks (nil without checks)
+	CheckCounts []CheckCount // Passes and failures per entry of Checks
+
+	CPUCores       int           // GOMAXPROCS of the load generator (0 = not monitored)
+	CPUSamples     []float64     // Process CPU usage per health sample, as a fraction of CPUCores
+	MaxGoroutines  int64         // Highest goroutine count sampled
+	GCCycles       int64         // Garbage collections during the run
+	GCPauseTotal   time.Duration // Time the program was paused for garbage collection
+	GCPauseMax     time.Duration // Longest single GC pause
+	MaxOpenFiles   int64         // Highest open file descriptor count sampled (0 = unavailable)
+	FileLimit      int64         // Open file descriptor limit (0 = unknown)
+	HealthDuration time.Duration // Time covered by health samples
+	SchedulerLags  LagHistogram  // How late the scheduler probe woke up
+	TokenLags      LagHistogram  // How late the rate limiter woke workers (rate-limited runs only)
+	LoopLags       LagHistogram  // Worker time outside of requests and rate limiting
+
+	Warmup      *Stats    // Results that arrived during the warm-up period (nil without warm-up)
+	warmupUntil time.Time // Results arriving before this go to Warmup
+
+	inFlight      int64 // Requests currently being sent (atomic)
+	activeWorkers int64 // Workers currently running (atomic)
 }
 
 // NewStats creates a new Stats instance
 func NewStats() *Stats {
 	return &Stats{
-		StatusCodeCounts: make(map[int]int64),
-		Latencies:        make([]time.Duration, 0),
-		StartTime:        time.Now(),
+		StatusCodeCounts:  make(map[int]int64),
+		Latencies:         make([]time.Duration, 0),
+		StartTime:         time.Now(),
+		URLStatusCounts:   make(map[string]map[int]int64),
+		URLFailures:       make(map[string]int64),
+		URLLatencies:      make(map[string][]time.Duration),
+		ErrorClassCounts:  make(map[string]int64),
+		ErrorMessages:     make(map[string]map[string]*ErrorMessage),
+		ErrorMessageLimit: 5,
+		BucketCounts:      make([]int64, len(LatencyBuckets)+1),
+		TLSVersionCounts:  make(map[string]int64),
+		TLSCipherCounts:   make(map[string]int64),
+		ProtocolCounts:    make(map[string]int64),
+		Operations:        make(map[string]*OperationStats),
 	}
 }
 
+// SetWarmup diverts results arriving during the next d into a separate Warmup instance
+// The main statistics, including the RPS calculation, then start when the warm-up ends
+// Must be called before any result is added
+func (s *Stats) SetWarmup(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	s.Warmup = NewStats()
+	s.Warmup.StartTime = s.StartTime
+	s.Warmup.EndTime = s.StartTime.Add(d)
+	s.warmupUntil = s.Warmup.EndTime
+	s.StartTime = s.warmupUntil
+}
+
+// ConnectionClosed records the lifetime of a closed connection
+// Like results, connections closed during warm-up are recorded in Warmup
+func (s *Stats) ConnectionClosed(lifetime time.Duration) {
+	if s.warmingUp() {
+		s.Warmup.ConnectionClosed(lifetime)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ConnLifetimes = append(s.ConnLifetimes, lifetime)
+}
+
+// QUICHandshakeDone records a completed QUIC handshake
+func (s *Stats) QUICHandshakeDone(h httpclient.QUICHandshake) {
+	if s.warmingUp() {
+		s.Warmup.QUICHandshakeDone(h)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.QUICHandshakes = append(s.QUICHandshakes, h.Duration)
+	if h.Resumed {
+		s.QUICResumed++
+	}
+	if h.Used0RTT {
+		s.QUIC0RTT++
+	}
+}
+
+// warmingUp reports whether results are currently diverted to Warmup
+func (s *Stats) warmingUp() bool {
+	return s.Warmup != nil && time.Now().Before(s.warmupUntil)
+}
+
 // AddResult adds a result to the statistics
 func (s *Stats) AddResult(result Result) {
+	if s.warmingUp() {
+		s.Warmup.AddResult(result)
+		return
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	s.TotalRequests++
 	s.Latencies = append(s.Latencies, result.Latency)
+	s.LatencySum += result.Latency
+	s.BucketCounts[bucketIndex(result.Latency)]++
+	if result.ResponseTime > 0 {
+		s.ResponseTimes = append(s.ResponseTimes, result.ResponseTime)
+		s.recordMissed(result)
+	}
+	if result.Conn.Acquired {
+		if result.Conn.Reused {
+			s.ReusedConns++
+			s.ConnIdleTimes = append(s.ConnIdleTimes, result.Conn.IdleTime)
+		} else {
+			s.NewConns++
+		}
+	}
+	if result.Conn.TLSVersion != 0 {
+		s.TLSVersionCounts[tls.VersionName(result.Conn.TLSVersion)]++
+		s.TLSCipherCounts[tls.CipherSuiteName(result.Conn.TLSCipherSuite)]++
+	}
+	if result.Protocol != "" {
+		s.ProtocolCounts[result.Protocol]++
+	}
+	if result.Stream != nil && result.Error == nil && result.StatusCode < 400 {
+		s.recordStream(result.Stream)
+	}
+	// A request sent during warm-up may complete after it; its send time would predate
+	// StartTime and skew the pacing, so only sends within the measured period count
+	if s.TargetRate > 0 && !result.SentAt.IsZero() && !result.SentAt.Before(s.StartTime) {
+		s.SendOffsets = append(s.SendOffsets, result.SentAt.Sub(s.StartTime))
+		s.TokenLags.Add(result.TokenLag)
+	}
+	s.LoopLags.Add(result.LoopLag)
 
 	if result.Error != nil || result.StatusCode >= 400 {
 		s.FailedRequests++
@@ -50,7 +280,10 @@ func (s *Stats) AddResult(result Result) {
 
 	// Record status code, including 0 for network errors
 	// StatusCode 0 indicates network/connection errors (not HTTP status codes)
-	if result.Error != nil && result.StatusCode == 0 {
+	if s.GRPC {
+		// gRPC status codes, where 0 is OK
+		s.StatusCodeCounts[result.StatusCode]++
+	} else if result.Error != nil && result.StatusCode == 0 {
 		// Network error: use 0 to represent connection/network errors
 		s.StatusCodeCounts[0]++
 	} else if result.StatusCode > 0 {
@@ -58,6 +291,190 @@ func (s *Stats) AddResult(result Result) {
 		s.StatusCodeCounts[result.StatusCode]++
 	}
 	// Note: If StatusCode is 0 and Error is nil, it shouldn't happen in normal flow
+
+	if result.Error != nil {
+		class := ClassifyError(result.Error)
+		s.ErrorClassCounts[class]++
+		s.recordErrorMessage(class, result.Error)
+	}
+
+	if result.URL != "" {
+		urlCounts, ok := s.URLStatusCounts[result.URL]
+		if !ok {
+			urlCounts = make(map[int]int64)
+			s.URLStatusCounts[result.URL] = urlCounts
+		}
+		urlCounts[result.StatusCode]++
+		if result.Error != nil || result.StatusCode >= 400 {
+			s.URLFailures[result.URL]++
+		}
+		s.URLLatencies[result.URL] = append(s.URLLatencies[result.URL], result.Latency)
+	}
+
+	if result.Operation != "" {
+		s.recordOperation(result)
+	}
+
+	for i, passed := range result.Checks {
+		if i >= len(s.CheckCounts) {
+			break
+		}
+		if passed {
+			s.CheckCounts[i].Passes++
+		} else {
+			s.CheckCounts[i].Fails++
+		}
+	}
+
+	if result.TraceID != "" {
+		s.recordTrace(result)
+	}
+}
+
+// OperationStats collects the results of one GraphQL operation
+type OperationStats struct {
+	Total         int64
+	Failed        int64
+	GraphQLErrors int64 // Failed with errors in a 2xx response
+	Latencies     []time.Duration
+}
+
+// CheckCount counts the responses that passed and failed one check
+type CheckCount struct {
+	Passes int64
+	Fails  int64
+}
+
+// SetChecks sets the checks whose outcomes are counted; must be called before any result is added
+func (s *Stats) SetChecks(checks []check.Check) {
+	s.Checks = nil
+	for _, c := range checks {
+		s.Checks = append(s.Checks, c.String())
+	}
+	s.CheckCounts = make([]CheckCount, len(s.Checks))
+}
+
+// recordOperation adds a result to the statistics of its GraphQL operation
+func (s *Stats) recordOperation(result Result) {
+	op, ok := s.Operations[result.Operation]
+	if !ok {
+		op = &OperationStats{}
+		s.Operations[result.Operation] = op
+	}
+	op.Total++
+	op.Latencies = append(op.Latencies, result.Latency)
+	if result.Error != nil || result.StatusCode >= 400 {
+		op.Failed++
+	}
+	if ClassifyError(result.Error) == ErrorClassGraphQL {
+		op.GraphQLErrors++
+	}
+}
+
+// recordStream records the event timings of a successful streamed response
+func (s *Stats) recordStream(stream *httpclient.StreamInfo) {
+	s.StreamResponses++
+	if stream.SSE {
+		s.StreamSSE++
+	}
+	s.StreamEventCounts = append(s.StreamEventCounts, int64(stream.Events))
+	if stream.Events > 0 {
+		s.StreamFirstEvents = append(s.StreamFirstEvents, stream.FirstEvent)
+	}
+	s.StreamGaps = append(s.StreamGaps, stream.Gaps...)
+	s.StreamDurations = append(s.StreamDurations, stream.Duration)
+}
+
+// recordErrorMessage counts a distinct error message within its class
+func (s *Stats) recordErrorMessage(class string, err error) {
+	messages, ok := s.ErrorMessages[class]
+	if !ok {
+		messages = make(map[string]*ErrorMessage)
+		s.ErrorMessages[class] = messages
+	}
+
+	msg := normalizeErrorMessage(err.Error())
+	entry, ok := messages[msg]
+	if !ok {
+		if s.distinctErrorMessages >= maxErrorMessages {
+			msg = otherErrorMessage
+			entry, ok = messages[msg]
+		}
+		if !ok {
+			entry = &ErrorMessage{Message: msg, FirstSeen: time.Now()}
+			messages[msg] = entry
+			s.distinctErrorMessages++
+		}
+	}
+	entry.Count++
+}
+
+// recordTrace keeps the trace ID of a sampled request if it failed or was among the slowest
+func (s *Stats) recordTrace(result Result) {
+	sample := TraceSample{
+		TraceID:    result.TraceID,
+		URL:        result.URL,
+		StatusCode: result.StatusCode,
+		Latency:    result.Latency,
+		Time:       time.Now(),
+	}
+
+	if result.Error != nil || result.StatusCode >= 400 {
+		if len(s.FailedTraces) < maxTraceSamples {
+			if result.Error != nil {
+				sample.Error = result.Error.Error()
+			}
+			s.FailedTraces = append(s.FailedTraces, sample)
+		}
+		return
+	}
+
+	if result.Latency < s.TraceSlowThreshold {
+		return
+	}
+	if len(s.SlowTraces) == maxTraceSamples && result.Latency <= s.SlowTraces[len(s.SlowTraces)-1].Latency {
+		return
+	}
+
+	// Insert keeping the slice sorted slowest first, dropping the fastest when full
+	i := sort.Search(len(s.SlowTraces), func(i int) bool {
+		return s.SlowTraces[i].Latency < result.Latency
+	})
+	if len(s.SlowTraces) < maxTraceSamples {
+		s.SlowTraces = append(s.SlowTraces, TraceSample{})
+	}
+	copy(s.SlowTraces[i+1:], s.SlowTraces[i:])
+	s.SlowTraces[i] = sample
+}
+
+// bucketIndex returns the index in BucketCounts for the given latency
+func bucketIndex(latency time.Duration) int {
+	for i, bound := range LatencyBuckets {
+		if latency <= bound {
+			return i
+		}
+	}
+	return len(LatencyBuckets)
+}
+
+// RequestStarted marks a request as in flight
+func (s *Stats) RequestStarted() {
+	atomic.AddInt64(&s.inFlight, 1)
+}
+
+// RequestFinished marks an in-flight request as completed
+func (s *Stats) RequestFinished() {
+	atomic.AddInt64(&s.inFlight, -1)
+}
+
+// WorkerStarted marks a worker as running
+func (s *Stats) WorkerStarted() {
+	atomic.AddInt64(&s.activeWorkers, 1)
+}
+
+// WorkerStopped marks a worker as stopped
+func (s *Stats) WorkerStopped() {
+	atomic.AddInt64(&s.activeWorkers, -1)
 }
 
 // Finalize marks the end of the test
@@ -65,65 +482,516 @@ func (s *Stats) Finalize() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.EndTime = time.Now()
+	// A run stopped during the warm-up has no measured time
+	if s.EndTime.Before(s.StartTime) {
+		s.EndTime = s.StartTime
+	}
 }
 
 // GetSummary returns a summary of the statistics
 func (s *Stats) GetSummary() Summary {
+	snapshot := s.snapshot()
+	return snapshot.summaryUntil(snapshot.EndTime)
+}
+
+// GetLiveSummary returns a summary of the results so far, while the test is still running
+func (s *Stats) GetLiveSummary() Summary {
+	return s.snapshot().summaryUntil(time.Now())
+}
+
+// snapshot copies what the summary is built from, so that the percentiles can be computed
+// without holding the lock: AddResult is only held up for the copy
+// Maps are cloned, as results keep updating them; the sample slices only ever grow by
+// appending, so their elements up to the current length are shared rather than copied
+func (s *Stats) snapshot() *Stats {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	if len(s.Latencies) == 0 {
-		return Summary{
-			TotalRequests:   s.TotalRequests,
-			SuccessRequests: s.SuccessRequests,
-			FailedRequests:  s.FailedRequests,
-			StatusCodeCounts: s.StatusCodeCounts,
-		}
-	}
+	c := &Stats{
+		TotalRequests:    s.TotalRequests,
+		SuccessRequests:  s.SuccessRequests,
+		FailedRequests:   s.FailedRequests,
+		StatusCodeCounts: maps.Clone(s.StatusCodeCounts),
+		Latencies:        s.Latencies[:len(s.Latencies):len(s.Latencies)],
+		ResponseTimes:    s.ResponseTimes[:len(s.ResponseTimes):len(s.ResponseTimes)],
+		SendOffsets:      s.SendOffsets[:len(s.SendOffsets):len(s.SendOffsets)],
+		MissedSlots:      s.MissedSlots,
+		StartTime:        s.StartTime,
+		EndTime:          s.EndTime,
 
-	// Calculate latency statistics
-	var min, max, sum time.Duration
-	min = s.Latencies[0]
-	max = s.Latencies[0]
+		URLStatusCounts:  make(map[string]map[int]int64, len(s.URLStatusCounts)),
+		URLFailures:      maps.Clone(s.URLFailures),
+		URLLatencies:     make(map[string][]time.Duration, len(s.URLLatencies)),
+		ErrorClassCounts: maps.Clone(s.ErrorClassCounts),
 
-	for _, lat := range s.Latencies {
-		if lat < min {
-			min = lat
-		}
-		if lat > max {
-			max = lat
-		}
-		sum += lat
-	}
+		ErrorMessages:     make(map[string]map[string]*ErrorMessage, len(s.ErrorMessages)),
+		ErrorMessageLimit: s.ErrorMessageLimit,
+
+		TargetRate: s.TargetRate,
+		Arrival:    s.Arrival,
+
+		SlowTraces:   slices.Clone(s.SlowTraces),
+		FailedTraces: slices.Clone(s.FailedTraces),
+
+		NewConns:            s.NewConns,
+		ReusedConns:         s.ReusedConns,
+		ConnIdleTimes:       s.ConnIdleTimes[:len(s.ConnIdleTimes):len(s.ConnIdleTimes)],
+		ConnLifetimes:       s.ConnLifetimes[:len(s.ConnLifetimes):len(s.ConnLifetimes)],
+		ExpectedConnections: s.ExpectedConnections,
+
+		TLSVersionCounts: maps.Clone(s.TLSVersionCounts),
+		TLSCipherCounts:  maps.Clone(s.TLSCipherCounts),
+		ProtocolCounts:   maps.Clone(s.ProtocolCounts),
+
+		QUICHandshakes: s.QUICHandshakes[:len(s.QUICHandshakes):len(s.QUICHandshakes)],
+		QUICResumed:    s.QUICResumed,
+		QUIC0RTT:       s.QUIC0RTT,
+
+		GRPC: s.GRPC,
+
+		StreamResponses:   s.StreamResponses,
+		StreamSSE:         s.StreamSSE,
+		StreamEventCounts: s.StreamEventCounts[:len(s.StreamEventCounts):len(s.StreamEventCounts)],
+		StreamFirstEvents: s.StreamFirstEvents[:len(s.StreamFirstEvents):len(s.StreamFirstEvents)],
+		StreamGaps:        s.StreamGaps[:len(s.StreamGaps):len(s.StreamGaps)],
+		StreamDurations:   s.StreamDurations[:len(s.StreamDurations):len(s.StreamDurations)],
+
+		Operations: make(map[string]*OperationStats, len(s.Operations)),
 
-	avg := sum / time.Duration(len(s.Latencies))
+		Checks:      s.Checks,
+		CheckCounts: slices.Clone(s.CheckCounts),
 
-	// Calculate percentiles
-	p90 := Percentile(s.Latencies, 90)
-	p95 := Percentile(s.Latencies, 95)
-	p99 := Percentile(s.Latencies, 99)
+		CPUCores:       s.CPUCores,
+		CPUSamples:     s.CPUSamples[:len(s.CPUSamples):len(s.CPUSamples)],
+		MaxGoroutines:  s.MaxGoroutines,
+		GCCycles:       s.GCCycles,
+		GCPauseTotal:   s.GCPauseTotal,
+		GCPauseMax:     s.GCPauseMax,
+		MaxOpenFiles:   s.MaxOpenFiles,
+		FileLimit:      s.FileLimit,
+		HealthDuration: s.HealthDuration,
+		SchedulerLags:  s.SchedulerLags.clone(),
+		TokenLags:      s.TokenLags.clone(),
+		LoopLags:       s.LoopLags.clone(),
 
+		Warmup: s.Warmup,
+	}
+	for url, counts := range s.URLStatusCounts {
+		c.URLStatusCounts[url] = maps.Clone(counts)
+	}
+	for url, latencies := range s.URLLatencies {
+		c.URLLatencies[url] = latencies[:len(latencies):len(latencies)]
+	}
+	for class, messages := range s.ErrorMessages {
+		copied := make(map[string]*ErrorMessage, len(messages))
+		for text, m := range messages {
+			entry := *m
+			copied[text] = &entry
+		}
+		c.ErrorMessages[class] = copied
+	}
+	for name, op := range s.Operations {
+		entry := *op
+		entry.Latencies = op.Latencies[:len(op.Latencies):len(op.Latencies)]
+		c.Operations[name] = &entry
+	}
+	return c
+}
+
+// summaryUntil builds the summary with the measured period ending at end
+// Called on a snapshot, which the summary may share maps and slices with
+func (s *Stats) summaryUntil(end time.Time) Summary {
 	// Calculate RPS
-	duration := s.EndTime.Sub(s.StartTime)
+	duration := end.Sub(s.StartTime)
 	var rps float64
 	if duration > 0 {
 		rps = float64(s.TotalRequests) / duration.Seconds()
 	}
 
+	min, max, avg := latencyRange(s.Latencies)
+
 	return Summary{
 		TotalRequests:    s.TotalRequests,
 		SuccessRequests:  s.SuccessRequests,
 		FailedRequests:   s.FailedRequests,
 		StatusCodeCounts: s.StatusCodeCounts,
+		GRPC:             s.GRPC,
 		MinLatency:       min,
 		MaxLatency:       max,
 		AvgLatency:       avg,
-		P90Latency:       p90,
-		P95Latency:       p95,
-		P99Latency:       p99,
+		P90Latency:       Percentile(s.Latencies, 90),
+		P95Latency:       Percentile(s.Latencies, 95),
+		P99Latency:       Percentile(s.Latencies, 99),
 		RPS:              rps,
 		Duration:         duration,
+		ResponseTime:     responseTimeSummary(s.ResponseTimes),
+		Pacing:           s.pacingSummary(),
+		Warmup:           s.warmupSummary(),
+		Connections:      s.connectionSummary(),
+		TLS:              s.tlsSummary(),
+		Protocols:        s.protocolSummary(),
+		QUIC:             s.quicSummary(),
+		Stream:           s.streamSummary(),
+		Endpoints:        s.endpointSummaries(duration),
+		Operations:       s.operationSummaries(duration),
+		Checks:           s.checkSummaries(),
+		Health:           s.healthSummary(),
+		Errors:           s.errorSummaries(),
+		SlowTraces:       s.SlowTraces,
+		FailedTraces:     s.FailedTraces,
+	}
+}
+
+// endpointSummaries builds per-URL summaries, sorted by URL
+func (s *Stats) endpointSummaries(duration time.Duration) []EndpointSummary {
+	urls := make([]string, 0, len(s.URLStatusCounts))
+	for url := range s.URLStatusCounts {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+
+	endpoints := make([]EndpointSummary, 0, len(urls))
+	for _, url := range urls {
+		e := EndpointSummary{
+			URL:              url,
+			StatusCodeCounts: s.URLStatusCounts[url],
+		}
+		for _, count := range e.StatusCodeCounts {
+			e.TotalRequests += count
+		}
+		// Failures are counted as for the totals, so an error in a 2xx response is one too
+		e.FailedRequests = s.URLFailures[url]
+		e.SuccessRequests = e.TotalRequests - e.FailedRequests
+
+		latencies := s.URLLatencies[url]
+		e.MinLatency, e.MaxLatency, e.AvgLatency = latencyRange(latencies)
+		e.P90Latency = Percentile(latencies, 90)
+		e.P95Latency = Percentile(latencies, 95)
+		e.P99Latency = Percentile(latencies, 99)
+		if duration > 0 {
+			e.RPS = float64(e.TotalRequests) / duration.Seconds()
+		}
+
+		endpoints = append(endpoints, e)
+	}
+	return endpoints
+}
+
+// operationSummaries builds per-operation summaries, sorted by name (nil without GraphQL)
+func (s *Stats) operationSummaries(duration time.Duration) []OperationSummary {
+	if len(s.Operations) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(s.Operations))
+	for name := range s.Operations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	operations := make([]OperationSummary, 0, len(names))
+	for _, name := range names {
+		op := s.Operations[name]
+		o := OperationSummary{
+			Name:            name,
+			TotalRequests:   op.Total,
+			SuccessRequests: op.Total - op.Failed,
+			FailedRequests:  op.Failed,
+			GraphQLErrors:   op.GraphQLErrors,
+			Latency:         Distribution(op.Latencies),
+		}
+		if duration > 0 {
+			o.RPS = float64(op.Total) / duration.Seconds()
+		}
+		operations = append(operations, o)
+	}
+	return operations
+}
+
+// checkSummaries returns the outcome of each check, in order (nil without checks)
+func (s *Stats) checkSummaries() []CheckSummary {
+	if len(s.Checks) == 0 {
+		return nil
+	}
+
+	checks := make([]CheckSummary, len(s.Checks))
+	for i, name := range s.Checks {
+		checks[i] = CheckSummary{Name: name}
+		if i < len(s.CheckCounts) {
+			checks[i].Passes = s.CheckCounts[i].Passes
+			checks[i].Fails = s.CheckCounts[i].Fails
+		}
+	}
+	return checks
+}
+
+// responseTimeSummary summarises corrected response times, or returns nil if none were recorded
+func responseTimeSummary(responseTimes []time.Duration) *LatencyDistribution {
+	if len(responseTimes) == 0 {
+		return nil
+	}
+
+	d := Distribution(responseTimes)
+	return &d
+}
+
+// connectionSummary summarises connection reuse, or returns nil if no connection was made
+func (s *Stats) connectionSummary() *ConnectionSummary {
+	total := s.NewConns + s.ReusedConns
+	if total == 0 {
+		return nil
 	}
+
+	c := &ConnectionSummary{
+		New:             s.NewConns,
+		Reused:          s.ReusedConns,
+		ReuseRate:       float64(s.ReusedConns) / float64(total),
+		Closed:          int64(len(s.ConnLifetimes)),
+		IdleBeforeReuse: Distribution(s.ConnIdleTimes),
+		Lifetime:        Distribution(s.ConnLifetimes),
+	}
+	if s.NewConns > 0 {
+		c.RequestsPerConn = float64(total) / float64(s.NewConns)
+	}
+
+	// With keep-alive, each worker should need about one connection; many more means
+	// connections are being closed (by the server, a proxy or timeouts) and reopened
+	if s.ExpectedConnections > 0 && s.NewConns > 2*s.ExpectedConnections && c.ReuseRate < lowReuseRate {
+		c.LowReuse = true
+	}
+	return c
+}
+
+// tlsSummary summarises negotiated TLS versions and cipher suites, or returns nil if no handshake completed
+func (s *Stats) tlsSummary() *TLSSummary {
+	var handshakes int64
+	for _, count := range s.TLSVersionCounts {
+		handshakes += count
+	}
+	if handshakes == 0 {
+		return nil
+	}
+	return &TLSSummary{
+		Handshakes:   handshakes,
+		Versions:     s.TLSVersionCounts,
+		CipherSuites: s.TLSCipherCounts,
+	}
+}
+
+// protocolSummary returns the responses per HTTP protocol, or nil if no response was received
+func (s *Stats) protocolSummary() map[string]int64 {
+	if len(s.ProtocolCounts) == 0 {
+		return nil
+	}
+	return s.ProtocolCounts
+}
+
+// quicSummary summarises QUIC handshakes, or returns nil if none completed
+func (s *Stats) quicSummary() *QUICSummary {
+	if len(s.QUICHandshakes) == 0 {
+		return nil
+	}
+	return &QUICSummary{
+		Handshakes:    int64(len(s.QUICHandshakes)),
+		HandshakeTime: Distribution(s.QUICHandshakes),
+		Resumed:       s.QUICResumed,
+		ZeroRTT:       s.QUIC0RTT,
+	}
+}
+
+// streamSummary summarises the events of streamed responses, or returns nil if none were streamed
+func (s *Stats) streamSummary() *StreamSummary {
+	if s.StreamResponses == 0 {
+		return nil
+	}
+
+	var events int64
+	for _, count := range s.StreamEventCounts {
+		events += count
+	}
+	return &StreamSummary{
+		Responses:         s.StreamResponses,
+		SSE:               s.StreamSSE,
+		Events:            events,
+		EventsPerResponse: countDistribution(s.StreamEventCounts),
+		FirstEvent:        Distribution(s.StreamFirstEvents),
+		Gap:               Distribution(s.StreamGaps),
+		Duration:          Distribution(s.StreamDurations),
+	}
+}
+
+// lowReuseRate is the reuse rate below which connection churn is reported
+const lowReuseRate = 0.9
+
+// Distribution summarises a set of durations (all zero if empty)
+func Distribution(durations []time.Duration) LatencyDistribution {
+	min, max, avg := latencyRange(durations)
+	return LatencyDistribution{
+		Min: min,
+		Max: max,
+		Avg: avg,
+		P90: Percentile(durations, 90),
+		P95: Percentile(durations, 95),
+		P99: Percentile(durations, 99),
+	}
+}
+
+// countDistribution summarises a set of counts (all zero if empty)
+func countDistribution(counts []int64) CountDistribution {
+	if len(counts) == 0 {
+		return CountDistribution{}
+	}
+
+	// Counts are held as durations to share Percentile's interpolation
+	values := make([]time.Duration, len(counts))
+	for i, count := range counts {
+		values[i] = time.Duration(count)
+	}
+	min, max, _ := latencyRange(values)
+	var sum int64
+	for _, count := range counts {
+		sum += count
+	}
+	return CountDistribution{
+		Min: int64(min),
+		Max: int64(max),
+		Avg: float64(sum) / float64(len(counts)),
+		P50: float64(Percentile(values, 50)),
+		P90: float64(Percentile(values, 90)),
+		P99: float64(Percentile(values, 99)),
+	}
+}
+
+// warmupSummary summarises the results excluded as warm-up, or returns nil without warm-up
+func (s *Stats) warmupSummary() *WarmupSummary {
+	if s.Warmup == nil {
+		return nil
+	}
+
+	w := s.Warmup.GetSummary()
+	return &WarmupSummary{
+		Duration:        w.Duration,
+		TotalRequests:   w.TotalRequests,
+		SuccessRequests: w.SuccessRequests,
+		FailedRequests:  w.FailedRequests,
+		RPS:             w.RPS,
+		Latency: LatencyDistribution{
+			Min: w.MinLatency,
+			Max: w.MaxLatency,
+			Avg: w.AvgLatency,
+			P90: w.P90Latency,
+			P95: w.P95Latency,
+			P99: w.P99Latency,
+		},
+	}
+}
+
+// recordMissed records a response time for every slot dropped before result, as if the
+// request due in that slot had waited for the stall to end with this one
+// Without this, dropping slots would hide the stall from the corrected response times
+func (s *Stats) recordMissed(result Result) {
+	if result.Missed <= 0 {
+		return
+	}
+	s.MissedSlots += int64(result.Missed)
+	completed := result.SentAt.Add(result.Latency)
+	for i := 0; i < result.Missed; i++ {
+		due := result.MissedFrom.Add(time.Duration(i) * result.MissedGap)
+		if wait := completed.Sub(due); wait > 0 {
+			s.ResponseTimes = append(s.ResponseTimes, wait)
+		}
+	}
+}
+
+// pacingSummary measures the achieved rate and inter-arrival gaps from actual send times
+// Returns nil without a rate limit or if fewer than two requests were sent
+func (s *Stats) pacingSummary() *PacingSummary {
+	if s.TargetRate <= 0 || len(s.SendOffsets) < 2 {
+		return nil
+	}
+
+	// Results arrive out of order from concurrent workers, so sort a copy by send time
+	sent := make([]time.Duration, len(s.SendOffsets))
+	copy(sent, s.SendOffsets)
+	sort.Slice(sent, func(i, j int) bool { return sent[i] < sent[j] })
+
+	gaps := len(sent) - 1
+	span := sent[gaps] - sent[0]
+	mean := float64(span) / float64(gaps)
+
+	var variance float64
+	for i := 1; i < len(sent); i++ {
+		d := float64(sent[i]-sent[i-1]) - mean
+		variance += d * d
+	}
+	variance /= float64(gaps)
+	stdDev := math.Sqrt(variance)
+
+	p := &PacingSummary{
+		Arrival:    s.Arrival,
+		TargetRate: s.TargetRate,
+		GapMean:    time.Duration(mean),
+		GapStdDev:  time.Duration(stdDev),
+		Missed:     s.MissedSlots,
+	}
+	if span > 0 {
+		p.AchievedRate = float64(gaps) / span.Seconds()
+	}
+	if mean > 0 {
+		p.GapCV = stdDev / mean
+	}
+	return p
+}
+
+// errorSummaries builds per-class error summaries, most frequent class first
+func (s *Stats) errorSummaries() []ErrorClassSummary {
+	summaries := make([]ErrorClassSummary, 0, len(s.ErrorClassCounts))
+	for class, count := range s.ErrorClassCounts {
+		messages := make([]ErrorMessage, 0, len(s.ErrorMessages[class]))
+		for _, m := range s.ErrorMessages[class] {
+			messages = append(messages, *m)
+		}
+		sort.Slice(messages, func(i, j int) bool {
+			if messages[i].Count != messages[j].Count {
+				return messages[i].Count > messages[j].Count
+			}
+			return messages[i].FirstSeen.Before(messages[j].FirstSeen)
+		})
+		if len(messages) > s.ErrorMessageLimit {
+			messages = messages[:s.ErrorMessageLimit]
+		}
+
+		summaries = append(summaries, ErrorClassSummary{Class: class, Count: count, Messages: messages})
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Count != summaries[j].Count {
+			return summaries[i].Count > summaries[j].Count
+		}
+		return summaries[i].Class < summaries[j].Class
+	})
+	return summaries
+}
+
+// latencyRange returns the min, max and average of latencies (all zero if empty)
+func latencyRange(latencies []time.Duration) (min, max, avg time.Duration) {
+	if len(latencies) == 0 {
+		return 0, 0, 0
+	}
+
+	min = latencies[0]
+	max = latencies[0]
+	var sum time.Duration
+	for _, lat := range latencies {
+		if lat < min {
+			min = lat
+		}
+		if lat > max {
+			max = lat
+		}
+		sum += lat
+	}
+
+	return min, max, sum / time.Duration(len(latencies))
 }
 
 // ProgressStats contains current progress statistics (for real-time display)
@@ -131,10 +999,18 @@ type ProgressStats struct {
 	TotalRequests   int64
 	SuccessRequests int64
 	FailedRequests  int64
+	WarmingUp       bool          // Counts are for the warm-up period
+	Elapsed         time.Duration // Time since the current period (warm-up or measurement) started
 }
 
 // GetProgressStats returns current progress statistics without locking for long operations
 func (s *Stats) GetProgressStats() ProgressStats {
+	if s.warmingUp() {
+		progress := s.Warmup.GetProgressStats()
+		progress.WarmingUp = true
+		return progress
+	}
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -142,6 +1018,72 @@ func (s *Stats) GetProgressStats() ProgressStats {
 		TotalRequests:   s.TotalRequests,
 		SuccessRequests: s.SuccessRequests,
 		FailedRequests:  s.FailedRequests,
+		Elapsed:         time.Since(s.StartTime),
+	}
+}
+
+// GetLatenciesSince returns a copy of the latencies recorded after the first `from` results,
+// along with the index to pass on the next call (for per-interval percentiles)
+func (s *Stats) GetLatenciesSince(from int) ([]time.Duration, int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	end := len(s.Latencies)
+	if from >= end {
+		return nil, end
+	}
+	latencies := make([]time.Duration, end-from)
+	copy(latencies, s.Latencies[from:end])
+	return latencies, end
+}
+
+// MetricsSnapshot is a point-in-time copy of the live counters (for metrics exporters)
+type MetricsSnapshot struct {
+	TotalRequests    int64
+	SuccessRequests  int64
+	FailedRequests   int64
+	URLStatusCounts  map[string]map[int]int64
+	GRPC             bool // URLStatusCounts holds gRPC status codes (0 = OK)
+	ErrorClassCounts map[string]int64
+	BucketCounts     []int64
+	LatencySum       time.Duration
+	InFlight         int64
+	ActiveWorkers    int64
+}
+
+// GetMetricsSnapshot returns a copy of the live counters that is safe to use without locking
+func (s *Stats) GetMetricsSnapshot() MetricsSnapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	urlStatusCounts := make(map[string]map[int]int64, len(s.URLStatusCounts))
+	for url, counts := range s.URLStatusCounts {
+		urlCounts := make(map[int]int64, len(counts))
+		for code, count := range counts {
+			urlCounts[code] = count
+		}
+		urlStatusCounts[url] = urlCounts
+	}
+
+	errorClassCounts := make(map[string]int64, len(s.ErrorClassCounts))
+	for class, count := range s.ErrorClassCounts {
+		errorClassCounts[class] = count
+	}
+
+	bucketCounts := make([]int64, len(s.BucketCounts))
+	copy(bucketCounts, s.BucketCounts)
+
+	return MetricsSnapshot{
+		TotalRequests:    s.TotalRequests,
+		SuccessRequests:  s.SuccessRequests,
+		FailedRequests:   s.FailedRequests,
+		URLStatusCounts:  urlStatusCounts,
+		GRPC:             s.GRPC,
+		ErrorClassCounts: errorClassCounts,
+		BucketCounts:     bucketCounts,
+		LatencySum:       s.LatencySum,
+		InFlight:         atomic.LoadInt64(&s.inFlight),
+		ActiveWorkers:    atomic.LoadInt64(&s.activeWorkers),
 	}
 }
 
@@ -151,6 +1093,7 @@ type Summary struct {
 	SuccessRequests  int64
 	FailedRequests   int64
 	StatusCodeCounts map[int]int64
+	GRPC             bool // StatusCodeCounts holds gRPC status codes
 	MinLatency       time.Duration
 	MaxLatency       time.Duration
 	AvgLatency       time.Duration
@@ -159,5 +1102,141 @@ type Summary struct {
 	P99Latency       time.Duration
 	RPS              float64
 	Duration         time.Duration
+	ResponseTime     *LatencyDistribution // Latency from the intended send time (nil without a rate limit)
+	Pacing           *PacingSummary       // Achieved send rate and inter-arrival gaps (nil without a rate limit)
+	Adaptive         *AdaptiveSummary     // Outcome of adaptive rate control (nil unless enabled)
+	Health           *HealthSummary       // Resource usage of the load generator (nil unless monitored)
+	Warmup           *WarmupSummary       // Results excluded as warm-up (nil without warm-up)
+	Connections      *ConnectionSummary   // New vs reused connections (nil if no connection was made)
+	TLS              *TLSSummary          // Negotiated TLS parameters (nil without TLS handshakes)
+	Protocols        map[string]int64     // Responses per HTTP protocol, e.g. "HTTP/2.0" (nil without responses)
+	QUIC             *QUICSummary         // QUIC handshakes (nil unless HTTP/3 was used)
+	Stream           *StreamSummary       // Event timings of streamed responses (nil unless streaming)
+	Endpoints        []EndpointSummary    // Per-URL breakdown
+	Operations       []OperationSummary   // Per GraphQL operation (nil without GraphQL)
+	Checks           []CheckSummary       // Outcome of each check, in order (nil without checks)
+	Errors           []ErrorClassSummary  // Network errors by class, most frequent first
+	SlowTraces       []TraceSample
+	FailedTraces     []TraceSample
 }
 
+// LatencyDistribution summarises a set of latencies
+type LatencyDistribution struct {
+	Min time.Duration
+	Max time.Duration
+	Avg time.Duration
+	P90 time.Duration
+	P95 time.Duration
+	P99 time.Duration
+}
+
+// ConnectionSummary describes connection reuse during the run
+type ConnectionSummary struct {
+	New             int64
+	Reused          int64
+	ReuseRate       float64 // Fraction of requests sent on a reused connection
+	RequestsPerConn float64
+	IdleBeforeReuse LatencyDistribution
+	Closed          int64 // Connections closed during the run
+	Lifetime        LatencyDistribution
+	LowReuse        bool // Far more new connections than workers despite keep-alive
+}
+
+// TLSSummary counts successful TLS handshakes by negotiated version and cipher suite
+type TLSSummary struct {
+	Handshakes   int64
+	Versions     map[string]int64 // e.g. "TLS 1.3"
+	CipherSuites map[string]int64 // e.g. "TLS_AES_128_GCM_SHA256"
+}
+
+// QUICSummary describes the QUIC handshakes of an HTTP/3 run
+type QUICSummary struct {
+	Handshakes    int64
+	HandshakeTime LatencyDistribution
+	Resumed       int64 // Handshakes that resumed an earlier TLS session
+	ZeroRTT       int64 // Handshakes whose 0-RTT early data was accepted
+}
+
+// StreamSummary describes the events of successful responses read in streaming mode
+type StreamSummary struct {
+	Responses         int64
+	SSE               int64 // Responses that were text/event-stream; the rest count body chunks as events
+	Events            int64
+	EventsPerResponse CountDistribution
+	FirstEvent        LatencyDistribution // From sending the request to the first event
+	Gap               LatencyDistribution // Between consecutive events of a response
+	Duration          LatencyDistribution // From sending the request to the end of the stream
+}
+
+// CountDistribution summarises a set of counts
+type CountDistribution struct {
+	Min int64
+	Max int64
+	Avg float64
+	P50 float64
+	P90 float64
+	P99 float64
+}
+
+// WarmupSummary contains the results that arrived during the warm-up period
+type WarmupSummary struct {
+	Duration        time.Duration
+	TotalRequests   int64
+	SuccessRequests int64
+	FailedRequests  int64
+	RPS             float64
+	Latency         LatencyDistribution
+}
+
+// PacingSummary describes how requests were actually spaced under a rate limit
+type PacingSummary struct {
+	Arrival      string
+	TargetRate   float64
+	AchievedRate float64 // Sends per second between the first and last request
+	GapMean      time.Duration
+	GapStdDev    time.Duration // Square root of the inter-arrival variance
+	GapCV        float64       // Coefficient of variation (0 = constant, ~1 = poisson)
+	Missed       int64         // Slots dropped because the schedule fell behind
+}
+
+// OperationSummary contains aggregated statistics for a single GraphQL operation
+type OperationSummary struct {
+	Name            string
+	TotalRequests   int64
+	SuccessRequests int64
+	FailedRequests  int64
+	GraphQLErrors   int64 // Failed requests whose 2xx response carried GraphQL errors
+	RPS             float64
+	Latency         LatencyDistribution
+}
+
+// CheckSummary counts the responses that passed and failed one check
+type CheckSummary struct {
+	Name   string // Check expression
+	Passes int64
+	Fails  int64
+}
+
+// PassRate returns the fraction of responses that passed the check (1 if none were checked)
+func (c CheckSummary) PassRate() float64 {
+	if c.Passes+c.Fails == 0 {
+		return 1
+	}
+	return float64(c.Passes) / float64(c.Passes+c.Fails)
+}
+
+// EndpointSummary contains aggregated statistics for a single URL
+type EndpointSummary struct {
+	URL              string
+	TotalRequests    int64
+	SuccessRequests  int64
+	FailedRequests   int64
+	StatusCodeCounts map[int]int64
+	MinLatency       time.Duration
+	MaxLatency       time.Duration
+	AvgLatency       time.Duration
+	P90Latency       time.Duration
+	P95Latency       time.Duration
+	P99Latency       time.Duration
+	RPS              float64
+}
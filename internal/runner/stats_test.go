@@ -0,0 +1,156 @@
+package runner
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/calummacc/g0/internal/graphql"
+	"github.com/calummacc/g0/internal/httpclient"
+)
+
+func TestStatsRecordsMissedSlots(t *testing.T) {
+	stats := NewStats()
+	stats.TargetRate = 100
+
+	// The request due at 1s was sent at 1.5s after a stall that dropped the slots due at
+	// 1.2s, 1.3s and 1.4s, and took 10ms
+	start := time.Now()
+	stats.AddResult(Result{
+		StatusCode:   200,
+		Latency:      10 * time.Millisecond,
+		ResponseTime: 510 * time.Millisecond,
+		SentAt:       start.Add(1500 * time.Millisecond),
+		Missed:       3,
+		MissedFrom:   start.Add(1200 * time.Millisecond),
+		MissedGap:    100 * time.Millisecond,
+	})
+
+	if stats.MissedSlots != 3 {
+		t.Errorf("MissedSlots = %d, want 3", stats.MissedSlots)
+	}
+	want := []time.Duration{510 * time.Millisecond, 310 * time.Millisecond, 210 * time.Millisecond, 110 * time.Millisecond}
+	if len(stats.ResponseTimes) != len(want) {
+		t.Fatalf("ResponseTimes = %v, want %v", stats.ResponseTimes, want)
+	}
+	for i := range want {
+		if stats.ResponseTimes[i] != want[i] {
+			t.Errorf("ResponseTimes = %v, want %v", stats.ResponseTimes, want)
+			break
+		}
+	}
+	if stats.TotalRequests != 1 {
+		t.Errorf("TotalRequests = %d, want 1 (missed slots are not requests)", stats.TotalRequests)
+	}
+}
+
+func TestStatsPacingExcludesWarmupSends(t *testing.T) {
+	stats := NewStats()
+	stats.TargetRate = 100
+	stats.SetWarmup(10 * time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+
+	// Sent during warm-up but completed after it
+	stats.AddResult(Result{StatusCode: 200, SentAt: stats.StartTime.Add(-5 * time.Millisecond)})
+	for i := 0; i < 10; i++ {
+		stats.AddResult(Result{StatusCode: 200, SentAt: stats.StartTime.Add(time.Duration(i) * 10 * time.Millisecond)})
+	}
+
+	if len(stats.SendOffsets) != 10 {
+		t.Fatalf("recorded %d send offsets, want 10 (the warm-up send excluded)", len(stats.SendOffsets))
+	}
+	p := stats.GetSummary().Pacing
+	if p.GapMean != 10*time.Millisecond || p.GapStdDev != 0 {
+		t.Errorf("gaps = mean %s, std dev %s, want 10ms and 0", p.GapMean, p.GapStdDev)
+	}
+}
+
+func TestStatsWarmupConnectionEvents(t *testing.T) {
+	stats := NewStats()
+	stats.SetWarmup(20 * time.Millisecond)
+	stats.ConnectionClosed(5 * time.Millisecond)
+	stats.QUICHandshakeDone(httpclient.QUICHandshake{Duration: 3 * time.Millisecond, Resumed: true, Used0RTT: true})
+	time.Sleep(30 * time.Millisecond)
+	stats.ConnectionClosed(50 * time.Millisecond)
+	stats.QUICHandshakeDone(httpclient.QUICHandshake{Duration: 30 * time.Millisecond})
+
+	// Each side only holds the events of its own period
+	for name, s := range map[string]*Stats{"warm-up": stats.Warmup, "measured": stats} {
+		if len(s.ConnLifetimes) != 1 || len(s.QUICHandshakes) != 1 {
+			t.Errorf("%s: %d connection lifetimes and %d QUIC handshakes, want 1 each", name, len(s.ConnLifetimes), len(s.QUICHandshakes))
+		}
+	}
+	if stats.ConnLifetimes[0] != 50*time.Millisecond || stats.QUICHandshakes[0] != 30*time.Millisecond {
+		t.Errorf("measured lifetime %s and handshake %s, want those recorded after the warm-up", stats.ConnLifetimes[0], stats.QUICHandshakes[0])
+	}
+	if stats.QUICResumed != 0 || stats.QUIC0RTT != 0 || stats.Warmup.QUICResumed != 1 || stats.Warmup.QUIC0RTT != 1 {
+		t.Errorf("resumed/0-RTT = %d/%d, warm-up %d/%d, want the warm-up handshake counted only in the warm-up",
+			stats.QUICResumed, stats.QUIC0RTT, stats.Warmup.QUICResumed, stats.Warmup.QUIC0RTT)
+	}
+}
+
+func TestStatsLiveSummarySharesNothing(t *testing.T) {
+	stats := NewStats()
+	stats.TraceSlowThreshold = time.Millisecond
+	versions := []uint16{tls.VersionTLS12, tls.VersionTLS13}
+	suites := []uint16{tls.TLS_AES_128_GCM_SHA256, tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 5000; i++ {
+			r := Result{
+				URL:        fmt.Sprintf("http://host/%d", i%7),
+				Latency:    time.Duration(i%100) * time.Millisecond,
+				StatusCode: 200 + i%50,
+				SentAt:     time.Now(),
+				Protocol:   fmt.Sprintf("HTTP/%d.0", 1+i%2),
+				TraceID:    fmt.Sprint(i),
+				Conn:       httpclient.ConnInfo{Acquired: true, TLSVersion: versions[i%2], TLSCipherSuite: suites[i%2]},
+			}
+			if i%11 == 0 {
+				r.StatusCode = 0
+				r.Error = fmt.Errorf("dial tcp: error %d", i%13)
+			}
+			stats.AddResult(r)
+		}
+	}()
+
+	// Readers such as the control API encode the summary without holding the stats lock;
+	// run with -race to catch anything still shared with the live counters
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		if _, err := json.Marshal(stats.GetLiveSummary()); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestStatsEndpointCountsGraphQLErrorsAsFailures(t *testing.T) {
+	stats := NewStats()
+	stats.AddResult(Result{URL: "http://api/graphql", StatusCode: 200, Operation: "GetUser"})
+	stats.AddResult(Result{
+		URL:        "http://api/graphql",
+		StatusCode: 200,
+		Operation:  "GetUser",
+		Error:      &graphql.Error{Messages: []string{"user not found"}},
+	})
+
+	summary := stats.GetSummary()
+	if summary.FailedRequests != 1 {
+		t.Fatalf("FailedRequests = %d, want 1", summary.FailedRequests)
+	}
+	e := summary.Endpoints[0]
+	if e.TotalRequests != 2 || e.FailedRequests != 1 || e.SuccessRequests != 1 {
+		t.Errorf("endpoint counts %d requests, %d failed and %d successful, want 2, 1 and 1", e.TotalRequests, e.FailedRequests, e.SuccessRequests)
+	}
+	if op := summary.Operations[0]; op.FailedRequests != 1 || op.GraphQLErrors != 1 {
+		t.Errorf("operation counts %d failed with %d GraphQL errors, want 1 and 1", op.FailedRequests, op.GraphQLErrors)
+	}
+}
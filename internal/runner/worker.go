@@ -2,7 +2,10 @@ package runner
 
 import (
 	"context"
+	"time"
 
+	"github.com/calummacc/g0/internal/check"
+	"github.com/calummacc/g0/internal/graphql"
 	"github.com/calummacc/g0/internal/httpclient"
 )
 
@@ -12,41 +15,66 @@ type Worker struct {
 	request     httpclient.Request // Base request config (URL will be selected dynamically)
 	results     chan<- Result
 	rateLimiter *RateLimiter
-	urlRotator  *URLRotator // For selecting URL in round-robin fashion
+	urlRotator  *URLRotator       // For selecting URL in round-robin fashion
+	operations  *OperationRotator // For selecting the GraphQL operation (nil without GraphQL)
+	checks      []check.Check     // Evaluated against every response
+	stats       *Stats            // For in-flight and active worker gauges
+	control     *Control          // Holds the worker back while paused (nil without a control)
 }
 
 // NewWorker creates a new worker
-func NewWorker(client *httpclient.Client, request httpclient.Request, results chan<- Result, rateLimiter *RateLimiter, urlRotator *URLRotator) *Worker {
+func NewWorker(client *httpclient.Client, request httpclient.Request, results chan<- Result, rateLimiter *RateLimiter, urlRotator *URLRotator, operations *OperationRotator, checks []check.Check, stats *Stats, control *Control) *Worker {
 	return &Worker{
 		client:      client,
 		request:     request,
 		results:     results,
 		rateLimiter: rateLimiter,
 		urlRotator:  urlRotator,
+		operations:  operations,
+		checks:      checks,
+		stats:       stats,
+		control:     control,
 	}
 }
 
 // Start begins the worker loop, sending requests until ctx is cancelled
 func (w *Worker) Start(ctx context.Context) {
+	w.StartUntil(ctx, ctx)
+}
+
+// StartUntil is Start for a worker that may be retired before the end of the run: once retire
+// is done (it must be derived from ctx), the worker finishes any request in flight and stops
+func (w *Worker) StartUntil(ctx, retire context.Context) {
 	defer func() {
 		// Recover from any panic (e.g., sending on closed channel)
 		// This should not happen with proper synchronization, but provides safety
 		recover()
 	}()
 
+	w.stats.WorkerStarted()
+	defer w.stats.WorkerStopped()
+
+	var enqueueWait time.Duration // How long the previous result waited for the stats collector
 	for {
-		// Check if context is done before starting a new request
+		// Check if context is done (or the worker retired) before starting a new request
 		select {
-		case <-ctx.Done():
+		case <-retire.Done():
 			return
 		default:
 		}
 
+		// Wait while the run is paused
+		if !w.control.wait(retire) {
+			return
+		}
+
 		// Wait for rate limiter token if rate limiting is enabled
-		if !w.rateLimiter.Wait(ctx) {
+		slot, ok := w.rateLimiter.WaitSlot(retire)
+		if !ok {
 			// Context cancelled or rate limiter stopped
 			return
 		}
+		ready := time.Now()
 
 		// Select URL from rotator (round-robin)
 		selectedURL := w.urlRotator.Next()
@@ -60,21 +88,70 @@ func (w *Worker) Start(ctx context.Context) {
 		request.URL = selectedURL
 		request.Context = ctx // Pass context to enable request cancellation
 
+		// GraphQL: send the next operation and fail responses carrying errors
+		var operation string
+		if op := w.operations.Next(); op != nil {
+			request.Body = op.Body
+			request.CheckBody = graphql.CheckResponse
+			operation = op.Name
+		}
+
 		// Send request
+		w.stats.RequestStarted()
+		sent := time.Now()
+		loopLag := sent.Sub(ready) + enqueueWait
 		resp := w.client.Do(request)
+		w.stats.RequestFinished()
+
+		// Response time is measured from the intended send time so that time spent
+		// queued behind a slow server is not omitted (coordinated omission)
+		var responseTime time.Duration
+		if !slot.Due.IsZero() {
+			responseTime = resp.Latency
+			if slot.Due.Before(sent) {
+				responseTime += sent.Sub(slot.Due)
+			}
+		}
+
+		var checks []bool
+		if len(w.checks) > 0 {
+			checks = check.EvaluateAll(w.checks, check.Response{StatusCode: resp.StatusCode, Header: resp.Header, Body: resp.Body})
+		}
+
+		// Requests cut short by the end of the run (e.g. streams still being read) are not results
+		if ctx.Err() != nil {
+			return
+		}
 
 		// Check context again before sending result (request might have taken time)
+		enqueued := time.Now()
 		select {
 		case <-ctx.Done():
 			// Context cancelled, don't send result
 			return
 		case w.results <- Result{
+			URL:        selectedURL,
 			Latency:    resp.Latency,
 			StatusCode: resp.StatusCode,
 			Error:      resp.Error,
+			TraceID:    resp.TraceID,
+
+			ResponseTime: responseTime,
+			SentAt:       sent,
+			RetryAfter:   resp.RetryAfter,
+			Conn:         resp.Conn,
+			Protocol:     resp.Protocol,
+			Stream:       resp.Stream,
+			Operation:    operation,
+			Checks:       checks,
+			Missed:       slot.Missed,
+			MissedFrom:   slot.MissedFrom,
+			MissedGap:    slot.MissedGap,
+			TokenLag:     slot.Lag,
+			LoopLag:      loopLag,
 		}:
 			// Successfully sent result, continue loop
+			enqueueWait = time.Since(enqueued)
 		}
 	}
 }
-
@@ -0,0 +1,81 @@
+package target
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// Latency distributions of the target server
+const (
+	LatencyConstant    = "constant"    // Every response takes the set latency
+	LatencyUniform     = "uniform"     // Spread uniformly within ±Jitter × the mean
+	LatencyNormal      = "normal"      // Normally distributed with a standard deviation of Jitter × the mean
+	LatencyExponential = "exponential" // Exponentially distributed around the mean, with a long tail
+)
+
+// Latency is the added delay before a response is sent
+type Latency struct {
+	Mean   time.Duration
+	Kind   string
+	Jitter float64 // For uniform and normal latencies, as a fraction of Mean
+}
+
+// ParseLatency validates a latency distribution
+func ParseLatency(mean time.Duration, kind string, jitter float64) (Latency, error) {
+	if mean < 0 {
+		return Latency{}, fmt.Errorf("latency must not be negative")
+	}
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	switch kind {
+	case "", LatencyConstant:
+		return Latency{Mean: mean, Kind: LatencyConstant}, nil
+	case LatencyUniform:
+		if jitter <= 0 || jitter > 1 {
+			return Latency{}, fmt.Errorf("latency jitter must be between 0 (exclusive) and 1 for uniform latency")
+		}
+		return Latency{Mean: mean, Kind: kind, Jitter: jitter}, nil
+	case LatencyNormal:
+		if jitter <= 0 {
+			return Latency{}, fmt.Errorf("latency jitter must be greater than 0 for normal latency")
+		}
+		return Latency{Mean: mean, Kind: kind, Jitter: jitter}, nil
+	case LatencyExponential:
+		return Latency{Mean: mean, Kind: kind}, nil
+	default:
+		return Latency{}, fmt.Errorf("unknown latency distribution %q (supported: constant, uniform, normal, exponential)", kind)
+	}
+}
+
+// next draws the latency of one response (never negative)
+func (l Latency) next() time.Duration {
+	mean := float64(l.Mean)
+	var d float64
+	switch l.Kind {
+	case LatencyUniform:
+		d = mean * (1 + l.Jitter*(2*rand.Float64()-1))
+	case LatencyNormal:
+		d = mean * (1 + l.Jitter*rand.NormFloat64())
+	case LatencyExponential:
+		d = mean * rand.ExpFloat64()
+	default:
+		d = mean
+	}
+	if d < 0 {
+		return 0
+	}
+	return time.Duration(d)
+}
+
+// String describes the distribution, e.g. "20ms (normal ±25%)"
+func (l Latency) String() string {
+	switch l.Kind {
+	case LatencyUniform, LatencyNormal:
+		return fmt.Sprintf("%s (%s ±%.0f%%)", l.Mean, l.Kind, l.Jitter*100)
+	case LatencyExponential:
+		return fmt.Sprintf("%s (%s)", l.Mean, l.Kind)
+	default:
+		return l.Mean.String()
+	}
+}
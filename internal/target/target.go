@@ -0,0 +1,207 @@
+package target
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// filler is written repeatedly to make up response bodies of any size
+var filler = bytes.Repeat([]byte("x"), 32*1024)
+
+// Options shape the responses of the target server
+type Options struct {
+	Size        int           // Response body size in bytes
+	Status      int           // Status code of successful responses
+	Latency     Latency       // Delay before the response headers are sent
+	ErrorRate   float64       // Fraction of responses answered with ErrorStatus (0-1)
+	ErrorStatus int           // Status code of failed responses
+	Chunks      int           // Send the body in this many flushed chunks without Content-Length (0 = one write)
+	ChunkDelay  time.Duration // Pause before every chunk after the first (slow responses)
+}
+
+// Validate checks that the options describe a response that can be sent
+func (o Options) Validate() error {
+	switch {
+	case o.Size < 0:
+		return fmt.Errorf("size must not be negative")
+	case o.Status < 200 || o.Status > 599:
+		return fmt.Errorf("status must be between 200 and 599")
+	case o.ErrorStatus < 200 || o.ErrorStatus > 599:
+		return fmt.Errorf("error status must be between 200 and 599")
+	case o.ErrorRate < 0 || o.ErrorRate > 1:
+		return fmt.Errorf("error rate must be between 0 and 1")
+	case o.Chunks < 0:
+		return fmt.Errorf("chunks must not be negative")
+	case o.Chunks > o.Size:
+		return fmt.Errorf("size (%d bytes) must be at least the number of chunks (%d)", o.Size, o.Chunks)
+	case o.ChunkDelay < 0:
+		return fmt.Errorf("chunk delay must not be negative")
+	}
+	return nil
+}
+
+// override returns the options with the overrides of a request's query string applied
+// Keys are size, status, latency, error-rate, error-status, chunks and chunk-delay
+func (o Options) override(query url.Values) (Options, error) {
+	var err error
+	for key, values := range query {
+		value := values[len(values)-1]
+		switch key {
+		case "size":
+			o.Size, err = strconv.Atoi(value)
+		case "status":
+			o.Status, err = strconv.Atoi(value)
+		case "latency":
+			o.Latency.Mean, err = time.ParseDuration(value)
+			if err == nil && o.Latency.Mean < 0 {
+				err = fmt.Errorf("must not be negative")
+			}
+		case "error-rate":
+			o.ErrorRate, err = strconv.ParseFloat(value, 64)
+		case "error-status":
+			o.ErrorStatus, err = strconv.Atoi(value)
+		case "chunks":
+			o.Chunks, err = strconv.Atoi(value)
+		case "chunk-delay":
+			o.ChunkDelay, err = time.ParseDuration(value)
+		default:
+			continue
+		}
+		if err != nil {
+			return o, fmt.Errorf("invalid %s: %v", key, err)
+		}
+	}
+	return o, o.Validate()
+}
+
+// Server is a minimal HTTP server answering every request as set by its Options
+type Server struct {
+	options Options
+	server  *http.Server
+
+	requests int64 // Requests answered (atomic)
+	errors   int64 // Of which were answered with the error status (atomic)
+}
+
+// New creates a target server
+func New(options Options) (*Server, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+	return &Server{options: options}, nil
+}
+
+// Listen starts serving on addr and returns the address listened on
+func (s *Server) Listen(addr string) (string, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", err
+	}
+	s.server = &http.Server{Handler: s, ReadHeaderTimeout: 10 * time.Second}
+	go s.server.Serve(listener)
+	return listener.Addr().String(), nil
+}
+
+// Close stops serving
+func (s *Server) Close() error {
+	if s.server == nil {
+		return nil
+	}
+	return s.server.Close()
+}
+
+// Counts returns the number of requests answered so far and how many of them were errors
+func (s *Server) Counts() (requests, errors int64) {
+	return atomic.LoadInt64(&s.requests), atomic.LoadInt64(&s.errors)
+}
+
+// ServeHTTP answers a request after the configured latency
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	options := s.options
+	if r.URL.RawQuery != "" {
+		var err error
+		if options, err = options.override(r.URL.Query()); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Read the request body so that the connection can be reused
+	io.Copy(io.Discard, r.Body)
+
+	if !sleep(r.Context(), options.Latency.next()) {
+		return
+	}
+
+	status := options.Status
+	if options.ErrorRate > 0 && rand.Float64() < options.ErrorRate {
+		status = options.ErrorStatus
+		atomic.AddInt64(&s.errors, 1)
+	}
+	atomic.AddInt64(&s.requests, 1)
+
+	w.Header().Set("Content-Type", "text/plain")
+	if options.Chunks == 0 {
+		w.Header().Set("Content-Length", strconv.Itoa(options.Size))
+		w.WriteHeader(status)
+		writeBody(w, options.Size)
+		return
+	}
+
+	w.WriteHeader(status)
+	flusher, _ := w.(http.Flusher)
+	for i := 0; i < options.Chunks; i++ {
+		if i > 0 && !sleep(r.Context(), options.ChunkDelay) {
+			return
+		}
+		n := options.Size / options.Chunks
+		if i < options.Size%options.Chunks {
+			n++
+		}
+		if !writeBody(w, n) {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
+
+// writeBody writes n filler bytes and reports whether it succeeded
+func writeBody(w io.Writer, n int) bool {
+	for n > 0 {
+		chunk := filler
+		if n < len(chunk) {
+			chunk = chunk[:n]
+		}
+		if _, err := w.Write(chunk); err != nil {
+			return false
+		}
+		n -= len(chunk)
+	}
+	return true
+}
+
+// sleep waits for d unless ctx is done first, and reports whether it waited the whole time
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,242 @@
+package threshold
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/calummacc/g0/internal/runner"
+)
+
+// Metric kinds determine how threshold values are parsed and formatted
+const (
+	kindLatency = iota
+	kindPercent
+	kindRate
+	kindCount
+)
+
+// metricKinds lists the metrics a threshold can refer to
+var metricKinds = map[string]int{
+	"min":      kindLatency,
+	"avg":      kindLatency,
+	"max":      kindLatency,
+	"p90":      kindLatency,
+	"p95":      kindLatency,
+	"p99":      kindLatency,
+	"errors":   kindPercent,
+	"rps":      kindRate,
+	"requests": kindCount,
+}
+
+// operators in match order (two-character operators first)
+var operators = []string{"<=", ">=", "<", ">"}
+
+// Threshold is a pass/fail criterion such as "p99<300ms" or "errors<1%"
+type Threshold struct {
+	Expr     string // Original expression
+	Metric   string
+	Operator string
+	Value    float64 // Latency in ms, error rate in percent, rate in req/s or request count
+}
+
+// Values holds the metrics a threshold is evaluated against (latencies in ms, errors in percent)
+type Values map[string]float64
+
+// Result is the outcome of evaluating one threshold
+type Result struct {
+	Threshold Threshold
+	Observed  float64
+	Passed    bool
+}
+
+// Parse parses a single threshold expression such as "p99<300ms", "errors<1%" or "rps>=500"
+func Parse(expr string) (Threshold, error) {
+	expr = strings.TrimSpace(expr)
+	for _, op := range operators {
+		idx := strings.Index(expr, op)
+		if idx < 0 {
+			continue
+		}
+
+		metric := strings.ToLower(strings.TrimSpace(expr[:idx]))
+		kind, ok := metricKinds[metric]
+		if !ok {
+			return Threshold{}, fmt.Errorf("invalid threshold %q: unknown metric %q (supported: min, avg, max, p90, p95, p99, errors, rps, requests)", expr, metric)
+		}
+
+		value, err := parseValue(strings.TrimSpace(expr[idx+len(op):]), kind)
+		if err != nil {
+			return Threshold{}, fmt.Errorf("invalid threshold %q: %w", expr, err)
+		}
+
+		return Threshold{Expr: expr, Metric: metric, Operator: op, Value: value}, nil
+	}
+	return Threshold{}, fmt.Errorf("invalid threshold %q (expected e.g. p99<300ms, errors<1%%, rps>100)", expr)
+}
+
+// ParseList parses a comma-separated list of threshold expressions
+func ParseList(list string) ([]Threshold, error) {
+	var thresholds []Threshold
+	for _, expr := range strings.Split(list, ",") {
+		if strings.TrimSpace(expr) == "" {
+			continue
+		}
+		t, err := Parse(expr)
+		if err != nil {
+			return nil, err
+		}
+		thresholds = append(thresholds, t)
+	}
+	return thresholds, nil
+}
+
+// parseValue parses a threshold value according to the metric kind
+// Latencies accept durations (300ms, 1.5s) or bare numbers in ms; error rates accept "1%" or "1"
+func parseValue(raw string, kind int) (float64, error) {
+	switch kind {
+	case kindLatency:
+		if d, err := time.ParseDuration(raw); err == nil {
+			return float64(d.Nanoseconds()) / 1000000.0, nil
+		}
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid latency %q (expected e.g. 300ms)", raw)
+		}
+		return v, nil
+	case kindPercent:
+		v, err := strconv.ParseFloat(strings.TrimSuffix(raw, "%"), 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid percentage %q (expected e.g. 1%%)", raw)
+		}
+		return v, nil
+	default:
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number %q", raw)
+		}
+		return v, nil
+	}
+}
+
+// Evaluate checks the threshold against the given values
+func (t Threshold) Evaluate(values Values) Result {
+	observed := values[t.Metric]
+
+	var passed bool
+	switch t.Operator {
+	case "<":
+		passed = observed < t.Value
+	case "<=":
+		passed = observed <= t.Value
+	case ">":
+		passed = observed > t.Value
+	case ">=":
+		passed = observed >= t.Value
+	}
+
+	return Result{Threshold: t, Observed: observed, Passed: passed}
+}
+
+// String returns the expression in normalised form (e.g. "p99 < 300ms")
+func (t Threshold) String() string {
+	return fmt.Sprintf("%s %s %s", t.Metric, t.Operator, t.FormatValue(t.Value))
+}
+
+// FormatValue formats a value of this threshold's metric for display
+func (t Threshold) FormatValue(v float64) string {
+	v = round(v)
+	switch metricKinds[t.Metric] {
+	case kindLatency:
+		return strconv.FormatFloat(v, 'f', -1, 64) + "ms"
+	case kindPercent:
+		return strconv.FormatFloat(v, 'f', -1, 64) + "%"
+	case kindRate:
+		return strconv.FormatFloat(v, 'f', 1, 64) + "/s"
+	default:
+		return strconv.FormatFloat(v, 'f', 0, 64)
+	}
+}
+
+// Message describes the outcome, including observed and expected values
+func (r Result) Message() string {
+	return fmt.Sprintf("%s = %s, expected %s %s", r.Threshold.Metric,
+		r.Threshold.FormatValue(r.Observed), r.Threshold.Operator, r.Threshold.FormatValue(r.Threshold.Value))
+}
+
+// EvaluateAll evaluates every threshold against the given values
+func EvaluateAll(thresholds []Threshold, values Values) []Result {
+	results := make([]Result, 0, len(thresholds))
+	for _, t := range thresholds {
+		results = append(results, t.Evaluate(values))
+	}
+	return results
+}
+
+// AllPassed reports whether every result passed
+func AllPassed(results []Result) bool {
+	for _, r := range results {
+		if !r.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// SummaryValues extracts threshold values from a run summary
+func SummaryValues(s *runner.Summary) Values {
+	return values(s.TotalRequests, s.FailedRequests, s.RPS,
+		s.MinLatency, s.AvgLatency, s.MaxLatency, s.P90Latency, s.P95Latency, s.P99Latency)
+}
+
+// EndpointValues extracts threshold values from a per-URL summary
+func EndpointValues(e *runner.EndpointSummary) Values {
+	return values(e.TotalRequests, e.FailedRequests, e.RPS,
+		e.MinLatency, e.AvgLatency, e.MaxLatency, e.P90Latency, e.P95Latency, e.P99Latency)
+}
+
+// values builds a Values map from raw statistics
+func values(total, failed int64, rps float64, min, avg, max, p90, p95, p99 time.Duration) Values {
+	var errorRate float64
+	if total > 0 {
+		errorRate = float64(failed) / float64(total) * 100
+	}
+	return Values{
+		"min":      ms(min),
+		"avg":      ms(avg),
+		"max":      ms(max),
+		"p90":      ms(p90),
+		"p95":      ms(p95),
+		"p99":      ms(p99),
+		"errors":   errorRate,
+		"rps":      rps,
+		"requests": float64(total),
+	}
+}
+
+// ms converts a duration to fractional milliseconds
+func ms(d time.Duration) float64 {
+	return float64(d.Nanoseconds()) / 1000000.0
+}
+
+// round rounds to two decimals for display
+func round(v float64) float64 {
+	return float64(int64(v*100+0.5)) / 100
+}
+
+// ForEndpoints returns the thresholds that apply to a single endpoint (latency and error rate)
+// Run-wide metrics such as rps and requests are skipped; if nothing applies,
+// endpoints are required to have no failed requests
+func ForEndpoints(thresholds []Threshold) []Threshold {
+	var endpoint []Threshold
+	for _, t := range thresholds {
+		if kind := metricKinds[t.Metric]; kind == kindLatency || kind == kindPercent {
+			endpoint = append(endpoint, t)
+		}
+	}
+	if len(endpoint) == 0 {
+		endpoint = append(endpoint, Threshold{Expr: "errors<=0", Metric: "errors", Operator: "<=", Value: 0})
+	}
+	return endpoint
+}
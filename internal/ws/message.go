@@ -0,0 +1,83 @@
+package ws
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// renderMessage fills in the template placeholders of a message:
+// {{id}} (unique correlation ID), {{vu}} (virtual user number), {{seq}} (message number
+// on the connection) and {{timestamp}} (Unix milliseconds)
+// If the message is a JSON object without the correlation field, the ID is added to it
+// Returns the message and the correlation ID a reply must carry
+func renderMessage(template string, vu int, seq int64, field string) (message, id string) {
+	id = fmt.Sprintf("%d-%d", vu, seq)
+	message = strings.NewReplacer(
+		"{{id}}", id,
+		"{{vu}}", strconv.Itoa(vu),
+		"{{seq}}", strconv.FormatInt(seq, 10),
+		"{{timestamp}}", strconv.FormatInt(time.Now().UnixMilli(), 10),
+	).Replace(template)
+
+	if field == "" {
+		return message, message
+	}
+	var object map[string]interface{}
+	if err := json.Unmarshal([]byte(message), &object); err != nil {
+		// Not a JSON object: plain text replies are matched by their whole content
+		return message, message
+	}
+	if value, ok := fieldValue(object, field); ok {
+		return message, value
+	}
+	if !strings.Contains(field, ".") {
+		object[field] = id
+		if encoded, err := json.Marshal(object); err == nil {
+			return string(encoded), id
+		}
+	}
+	return message, message
+}
+
+// correlationID extracts the correlation ID from a reply
+// JSON objects are matched by the correlation field, anything else by its whole content
+func correlationID(reply []byte, field string) string {
+	if field != "" {
+		var object map[string]interface{}
+		if err := json.Unmarshal(reply, &object); err == nil {
+			if value, ok := fieldValue(object, field); ok {
+				return value
+			}
+		}
+	}
+	return string(reply)
+}
+
+// fieldValue looks up a dotted field path such as "meta.id" and formats it as a string
+func fieldValue(object map[string]interface{}, field string) (string, bool) {
+	parts := strings.Split(field, ".")
+	var value interface{} = object
+	for _, part := range parts {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		if value, ok = m[part]; !ok {
+			return "", false
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case nil, map[string]interface{}, []interface{}:
+		return "", false
+	default:
+		return fmt.Sprint(v), true
+	}
+}
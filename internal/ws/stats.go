@@ -0,0 +1,161 @@
+package ws
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/calummacc/g0/internal/runner"
+)
+
+// Stats collects WebSocket load test statistics
+// It is separate from runner.Stats, which counts request/response pairs by URL and status code
+// (with pacing, connection reuse and the thresholds and JSON report built on those): here a
+// connection is held for the whole run and messages are counted separately from replies, which
+// may never come or be pushed unasked. Distributions use the runner's helpers
+type Stats struct {
+	mu sync.Mutex
+
+	ConnectAttempts int64
+	ConnectTimes    []time.Duration  // Handshake durations of established connections
+	ConnectErrors   map[string]int64 // Failed connection attempts per reason
+	Disconnects     map[string]int64 // Connections lost during the run per reason
+
+	Sent     int64
+	Received int64
+	Matched  int64           // Replies matched to a sent message
+	Lost     int64           // Sent messages without a reply within the reply timeout
+	RTTs     []time.Duration // Round-trip latency of matched replies
+
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// NewStats creates a new Stats instance
+func NewStats() *Stats {
+	return &Stats{
+		ConnectErrors: make(map[string]int64),
+		Disconnects:   make(map[string]int64),
+		StartTime:     time.Now(),
+	}
+}
+
+// connected records a successful connection
+func (s *Stats) connected(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ConnectAttempts++
+	s.ConnectTimes = append(s.ConnectTimes, d)
+}
+
+// connectFailed records a failed connection attempt
+func (s *Stats) connectFailed(reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ConnectAttempts++
+	s.ConnectErrors[reason]++
+}
+
+// disconnected records a connection lost before the end of the run
+func (s *Stats) disconnected(reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Disconnects[reason]++
+}
+
+// sent records a sent message
+func (s *Stats) sent() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Sent++
+}
+
+// received records a received message and, if it matched a sent one, its round-trip time
+func (s *Stats) received(rtt time.Duration, matched bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Received++
+	if matched {
+		s.Matched++
+		s.RTTs = append(s.RTTs, rtt)
+	}
+}
+
+// lost records sent messages whose reply did not arrive in time
+func (s *Stats) lost(n int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Lost += n
+}
+
+// Finalize marks the end of the test
+func (s *Stats) Finalize() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.EndTime = time.Now()
+}
+
+// GetSummary returns a summary of the statistics
+func (s *Stats) GetSummary() Summary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	duration := s.EndTime.Sub(s.StartTime)
+	summary := Summary{
+		Duration:        duration,
+		ConnectAttempts: s.ConnectAttempts,
+		Connected:       int64(len(s.ConnectTimes)),
+		ConnectTime:     runner.Distribution(s.ConnectTimes),
+		ConnectErrors:   sortedReasons(s.ConnectErrors),
+		Disconnects:     sortedReasons(s.Disconnects),
+		Sent:            s.Sent,
+		Received:        s.Received,
+		Matched:         s.Matched,
+		Lost:            s.Lost,
+		RTT:             runner.Distribution(s.RTTs),
+	}
+	if seconds := duration.Seconds(); seconds > 0 {
+		summary.SentPerSec = float64(s.Sent) / seconds
+		summary.ReceivedPerSec = float64(s.Received) / seconds
+	}
+	return summary
+}
+
+// Summary contains aggregated WebSocket statistics
+type Summary struct {
+	Duration        time.Duration
+	ConnectAttempts int64
+	Connected       int64
+	ConnectTime     runner.LatencyDistribution
+	ConnectErrors   []Reason // Most frequent first
+	Disconnects     []Reason // Most frequent first
+
+	Sent           int64
+	Received       int64
+	Matched        int64
+	Lost           int64
+	SentPerSec     float64
+	ReceivedPerSec float64
+	RTT            runner.LatencyDistribution // Round-trip latency of matched replies
+}
+
+// Reason counts connection failures or disconnects with the same cause
+type Reason struct {
+	Reason string
+	Count  int64
+}
+
+// sortedReasons converts a reason count map into a slice, most frequent first
+func sortedReasons(counts map[string]int64) []Reason {
+	reasons := make([]Reason, 0, len(counts))
+	for reason, count := range counts {
+		reasons = append(reasons, Reason{Reason: reason, Count: count})
+	}
+	sort.Slice(reasons, func(i, j int) bool {
+		if reasons[i].Count != reasons[j].Count {
+			return reasons[i].Count > reasons[j].Count
+		}
+		return reasons[i].Reason < reasons[j].Reason
+	})
+	return reasons
+}
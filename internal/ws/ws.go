@@ -0,0 +1,353 @@
+package ws
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/calummacc/g0/internal/runner"
+	"github.com/gorilla/websocket"
+)
+
+// Default settings, used for zero-valued Config fields
+const (
+	DefaultReplyTimeout   = 5 * time.Second
+	DefaultConnectTimeout = 10 * time.Second
+)
+
+// reconnectDelay is how long a virtual user waits before reconnecting after a failure
+const reconnectDelay = 250 * time.Millisecond
+
+// Config holds the configuration for a WebSocket load test
+type Config struct {
+	URL              string // ws:// or wss:// URL
+	Connections      int    // Virtual users, each holding one connection
+	Duration         time.Duration
+	Headers          map[string]string
+	Messages         []string      // Message templates, sent in turn (none = connect and listen only)
+	Rate             float64       // Messages per second per connection (0 = send the next message once the previous one is answered)
+	CorrelationField string        // JSON field (dotted path allowed) matching replies to sent messages
+	ReplyTimeout     time.Duration // Messages without a reply after this long count as lost
+	ConnectTimeout   time.Duration // WebSocket handshake, including TCP connect and TLS
+	Insecure         bool          // Skip TLS certificate verification
+}
+
+// Run executes a WebSocket load test with the given configuration
+func Run(config Config) (*Summary, error) {
+	u, err := url.Parse(config.URL)
+	if err != nil || (u.Scheme != "ws" && u.Scheme != "wss") || u.Host == "" {
+		return nil, fmt.Errorf("invalid WebSocket URL %q (expected ws:// or wss://)", config.URL)
+	}
+	if config.Connections <= 0 {
+		return nil, fmt.Errorf("at least one connection is required")
+	}
+	if config.ReplyTimeout <= 0 {
+		config.ReplyTimeout = DefaultReplyTimeout
+	}
+	if config.ConnectTimeout <= 0 {
+		config.ConnectTimeout = DefaultConnectTimeout
+	}
+
+	dialer := &websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: config.ConnectTimeout,
+	}
+	if config.Insecure {
+		dialer.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	header := make(http.Header)
+	for key, value := range config.Headers {
+		header.Set(key, value)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.Duration)
+	defer cancel()
+
+	stats := NewStats()
+
+	var wg sync.WaitGroup
+	for i := 0; i < config.Connections; i++ {
+		vu := &virtualUser{
+			id:     i + 1,
+			config: config,
+			dialer: dialer,
+			header: header,
+			stats:  stats,
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			vu.run(ctx)
+		}()
+	}
+
+	<-ctx.Done()
+	wg.Wait()
+
+	stats.Finalize()
+	summary := stats.GetSummary()
+	return &summary, nil
+}
+
+// virtualUser holds one WebSocket connection, reconnecting whenever it is lost
+type virtualUser struct {
+	id     int
+	config Config
+	dialer *websocket.Dialer
+	header http.Header
+	stats  *Stats
+	seq    int64 // Messages sent so far, across reconnects
+}
+
+// run connects and exchanges messages until ctx is done
+func (vu *virtualUser) run(ctx context.Context) {
+	for ctx.Err() == nil {
+		start := time.Now()
+		conn, resp, err := vu.dialer.DialContext(ctx, vu.config.URL, vu.header)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			vu.stats.connectFailed(connectFailureReason(err, resp))
+			sleep(ctx, reconnectDelay)
+			continue
+		}
+		vu.stats.connected(time.Since(start))
+
+		if reason := vu.session(ctx, conn); reason != "" {
+			vu.stats.disconnected(reason)
+			sleep(ctx, reconnectDelay)
+		}
+	}
+}
+
+// session exchanges messages on conn until ctx is done or the connection is lost
+// Returns the reason the connection was lost, or "" if it was closed at the end of the run
+func (vu *virtualUser) session(ctx context.Context, conn *websocket.Conn) string {
+	defer conn.Close()
+
+	pending := newPendingMessages()
+	matched := make(chan struct{}, 1)
+	readErr := make(chan error, 1)
+
+	go func() {
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				readErr <- err
+				return
+			}
+			if sentAt, ok := pending.take(correlationID(data, vu.config.CorrelationField)); ok {
+				vu.stats.received(time.Since(sentAt), true)
+				select {
+				case matched <- struct{}{}:
+				default:
+				}
+			} else {
+				vu.stats.received(0, false)
+			}
+		}
+	}()
+
+	// closeNormally ends the session at the end of the run
+	closeNormally := func() string {
+		deadline := time.Now().Add(time.Second)
+		conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), deadline)
+		return ""
+	}
+
+	send := func() error {
+		template := vu.config.Messages[vu.seq%int64(len(vu.config.Messages))]
+		message, id := renderMessage(template, vu.id, vu.seq, vu.config.CorrelationField)
+		vu.seq++
+		// Register before writing, as the reply may arrive before WriteMessage returns
+		pending.add(id, time.Now())
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(message)); err != nil {
+			pending.take(id)
+			return err
+		}
+		vu.stats.sent()
+		return nil
+	}
+
+	timeout := vu.config.ReplyTimeout
+	expire := func() {
+		vu.stats.lost(pending.expire(time.Now().Add(-timeout)))
+	}
+
+	// disconnected ends the session when the connection is lost; unanswered messages are lost with it
+	disconnected := func(err error) string {
+		vu.stats.lost(pending.expire(time.Now()))
+		return disconnectReason(err)
+	}
+
+	// Without messages, just hold the connection open and count what the server pushes
+	if len(vu.config.Messages) == 0 {
+		select {
+		case <-ctx.Done():
+			return closeNormally()
+		case err := <-readErr:
+			return disconnected(err)
+		}
+	}
+
+	// Without a rate, send each message once the previous one is answered or timed out
+	if vu.config.Rate <= 0 {
+		for {
+			if err := send(); err != nil {
+				return disconnected(err)
+			}
+			timer := time.NewTimer(timeout)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return closeNormally()
+			case err := <-readErr:
+				timer.Stop()
+				return disconnected(err)
+			case <-matched:
+				timer.Stop()
+			case <-timer.C:
+				expire()
+			}
+		}
+	}
+
+	interval := time.Duration(float64(time.Second) / vu.config.Rate)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	sweep := time.NewTicker(timeout / 2)
+	defer sweep.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return closeNormally()
+		case err := <-readErr:
+			return disconnected(err)
+		case <-ticker.C:
+			if err := send(); err != nil {
+				return disconnected(err)
+			}
+		case <-sweep.C:
+			expire()
+		}
+	}
+}
+
+// pendingMessages tracks sent messages awaiting a reply by correlation ID
+// Messages sharing an ID (e.g. identical plain text messages) are matched in order
+type pendingMessages struct {
+	mu   sync.Mutex
+	sent map[string][]time.Time
+}
+
+// newPendingMessages creates an empty set of pending messages
+func newPendingMessages() *pendingMessages {
+	return &pendingMessages{sent: make(map[string][]time.Time)}
+}
+
+// add registers a message sent at the given time
+func (p *pendingMessages) add(id string, at time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sent[id] = append(p.sent[id], at)
+}
+
+// take removes the oldest message with the given ID and returns when it was sent
+func (p *pendingMessages) take(id string) (time.Time, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	times := p.sent[id]
+	if len(times) == 0 {
+		return time.Time{}, false
+	}
+	if len(times) == 1 {
+		delete(p.sent, id)
+	} else {
+		p.sent[id] = times[1:]
+	}
+	return times[0], true
+}
+
+// expire removes messages sent before cutoff and returns how many were removed
+func (p *pendingMessages) expire(cutoff time.Time) int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var expired int64
+	for id, times := range p.sent {
+		kept := times[:0]
+		for _, at := range times {
+			if at.Before(cutoff) {
+				expired++
+			} else {
+				kept = append(kept, at)
+			}
+		}
+		if len(kept) == 0 {
+			delete(p.sent, id)
+		} else {
+			p.sent[id] = kept
+		}
+	}
+	return expired
+}
+
+// connectFailureReason describes a failed WebSocket handshake
+func connectFailureReason(err error, resp *http.Response) string {
+	if errors.Is(err, websocket.ErrBadHandshake) && resp != nil {
+		return fmt.Sprintf("HTTP %d", resp.StatusCode)
+	}
+	return runner.ClassifyError(err)
+}
+
+// closeCodeNames are the registered WebSocket close codes (RFC 6455)
+var closeCodeNames = map[int]string{
+	websocket.CloseNormalClosure:           "normal closure",
+	websocket.CloseGoingAway:               "going away",
+	websocket.CloseProtocolError:           "protocol error",
+	websocket.CloseUnsupportedData:         "unsupported data",
+	websocket.CloseNoStatusReceived:        "no status",
+	websocket.CloseAbnormalClosure:         "abnormal closure",
+	websocket.CloseInvalidFramePayloadData: "invalid payload",
+	websocket.ClosePolicyViolation:         "policy violation",
+	websocket.CloseMessageTooBig:           "message too big",
+	websocket.CloseMandatoryExtension:      "mandatory extension",
+	websocket.CloseInternalServerErr:       "internal server error",
+	websocket.CloseServiceRestart:          "service restart",
+	websocket.CloseTryAgainLater:           "try again later",
+	websocket.CloseTLSHandshake:            "TLS handshake",
+}
+
+// disconnectReason describes why a connection was lost: the close code sent by the
+// server, or the network error class
+func disconnectReason(err error) string {
+	var closeErr *websocket.CloseError
+	if !errors.As(err, &closeErr) {
+		return runner.ClassifyError(err)
+	}
+	reason := fmt.Sprintf("close %d", closeErr.Code)
+	if name, ok := closeCodeNames[closeErr.Code]; ok {
+		reason += " (" + name + ")"
+	}
+	// Abnormal closures carry a local error message rather than a server-sent reason
+	if closeErr.Text != "" && closeErr.Code != websocket.CloseAbnormalClosure {
+		reason += ": " + closeErr.Text
+	}
+	return reason
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
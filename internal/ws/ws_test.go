@@ -0,0 +1,245 @@
+package ws
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// startServer serves WebSocket connections on a loopback port, handing each to handle,
+// and returns its ws:// URL
+func startServer(t *testing.T, handle func(conn *websocket.Conn)) string {
+	t.Helper()
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		handle(conn)
+	}))
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+// echo replies to every message with the same message
+func echo(conn *websocket.Conn) {
+	for {
+		kind, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if err := conn.WriteMessage(kind, data); err != nil {
+			return
+		}
+	}
+}
+
+func TestRunMatchesEchoedReplies(t *testing.T) {
+	for name, config := range map[string]Config{
+		"JSON by correlation field": {Messages: []string{`{"type":"ping"}`}, CorrelationField: "id"},
+		"plain text by content":     {Messages: []string{"ping {{vu}}-{{seq}}"}},
+		"at a fixed rate":           {Messages: []string{`{"id":"{{id}}"}`}, CorrelationField: "id", Rate: 50},
+	} {
+		config.URL = startServer(t, echo)
+		config.Connections = 2
+		config.Duration = 300 * time.Millisecond
+		summary, err := Run(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if summary.Connected != 2 || len(summary.ConnectErrors) != 0 {
+			t.Errorf("%s: %d connected with errors %v, want 2 without errors", name, summary.Connected, summary.ConnectErrors)
+		}
+		if summary.Sent == 0 || summary.Matched != summary.Received || summary.Lost != 0 {
+			t.Errorf("%s: sent %d, received %d, matched %d, lost %d, want every reply matched", name, summary.Sent, summary.Received, summary.Matched, summary.Lost)
+		}
+		// Messages sent just before the end may still be pending
+		if summary.Sent-summary.Matched > 2 {
+			t.Errorf("%s: %d of %d messages unanswered", name, summary.Sent-summary.Matched, summary.Sent)
+		}
+		if summary.RTT.Max <= 0 {
+			t.Errorf("%s: no round-trip time recorded", name)
+		}
+		if len(summary.Disconnects) != 0 {
+			t.Errorf("%s: disconnects %v, want none", name, summary.Disconnects)
+		}
+	}
+}
+
+func TestRunCountsLostMessages(t *testing.T) {
+	url := startServer(t, func(conn *websocket.Conn) {
+		// Replies carry another ID, so none matches
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+			conn.WriteMessage(websocket.TextMessage, []byte(`{"id":"unknown"}`))
+		}
+	})
+
+	summary, err := Run(Config{
+		URL:              url,
+		Connections:      1,
+		Duration:         300 * time.Millisecond,
+		Messages:         []string{`{"type":"ping"}`},
+		CorrelationField: "id",
+		ReplyTimeout:     50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Matched != 0 || summary.Received != summary.Sent {
+		t.Errorf("received %d replies to %d messages with %d matched, want all received and none matched", summary.Received, summary.Sent, summary.Matched)
+	}
+	// One message is sent per reply timeout; the last may still be pending at the end
+	if summary.Lost < 3 || summary.Lost < summary.Sent-1 {
+		t.Errorf("lost %d of %d messages, want all but the last", summary.Lost, summary.Sent)
+	}
+}
+
+func TestRunReportsDisconnectReasons(t *testing.T) {
+	url := startServer(t, func(conn *websocket.Conn) {
+		// The first message is left unanswered and the connection closed
+		conn.ReadMessage()
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "restarting"))
+		conn.ReadMessage()
+	})
+
+	summary, err := Run(Config{URL: url, Connections: 1, Duration: 200 * time.Millisecond, Messages: []string{"ping"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(summary.Disconnects) != 1 || summary.Disconnects[0].Reason != "close 1001 (going away): restarting" {
+		t.Fatalf("disconnects = %v, want close 1001 with its reason", summary.Disconnects)
+	}
+	// The connection is re-established after each disconnect; unanswered messages are lost with it
+	if summary.Connected != summary.Disconnects[0].Count && summary.Connected != summary.Disconnects[0].Count+1 {
+		t.Errorf("%d connections for %d disconnects", summary.Connected, summary.Disconnects[0].Count)
+	}
+	if summary.Lost != summary.Disconnects[0].Count {
+		t.Errorf("lost %d messages over %d disconnects, want one each", summary.Lost, summary.Disconnects[0].Count)
+	}
+}
+
+func TestRunReportsConnectErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	summary, err := Run(Config{URL: "ws" + strings.TrimPrefix(server.URL, "http"), Connections: 1, Duration: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Connected != 0 || len(summary.ConnectErrors) != 1 || summary.ConnectErrors[0].Reason != "HTTP 403" {
+		t.Errorf("connected %d with errors %v, want only HTTP 403 failures", summary.Connected, summary.ConnectErrors)
+	}
+	if summary.ConnectAttempts != summary.ConnectErrors[0].Count {
+		t.Errorf("%d attempts for %d failures", summary.ConnectAttempts, summary.ConnectErrors[0].Count)
+	}
+}
+
+func TestRunRejectsInvalidConfig(t *testing.T) {
+	for name, config := range map[string]Config{
+		"HTTP URL":       {URL: "http://localhost/", Connections: 1},
+		"no host":        {URL: "ws://", Connections: 1},
+		"no connections": {URL: "ws://localhost/"},
+	} {
+		if _, err := Run(config); err == nil {
+			t.Errorf("%s: Run succeeded, want an error", name)
+		}
+	}
+}
+
+func TestRenderMessage(t *testing.T) {
+	tests := []struct {
+		name, template, field string
+		wantMessage, wantID   string
+	}{
+		{"placeholders", "{{vu}}/{{seq}}/{{id}}", "", "3/7/3-7", "3/7/3-7"},
+		{"ID added to a JSON object", `{"type":"ping"}`, "id", `{"id":"3-7","type":"ping"}`, "3-7"},
+		{"ID from the template", `{"id":"{{id}}"}`, "id", `{"id":"3-7"}`, "3-7"},
+		{"own ID kept", `{"id":42}`, "id", `{"id":42}`, "42"},
+		{"dotted field", `{"meta":{"id":"x"}}`, "meta.id", `{"meta":{"id":"x"}}`, "x"},
+		{"missing dotted field", `{"meta":{}}`, "meta.id", `{"meta":{}}`, `{"meta":{}}`},
+		{"plain text", "ping {{seq}}", "id", "ping 7", "ping 7"},
+		{"JSON array", `[1,2]`, "id", `[1,2]`, `[1,2]`},
+	}
+	for _, tt := range tests {
+		message, id := renderMessage(tt.template, 3, 7, tt.field)
+		if message != tt.wantMessage || id != tt.wantID {
+			t.Errorf("%s: got %q with ID %q, want %q with ID %q", tt.name, message, id, tt.wantMessage, tt.wantID)
+		}
+	}
+
+	message, _ := renderMessage("{{timestamp}}", 1, 0, "")
+	if ms, err := strconv.ParseInt(message, 10, 64); err != nil || time.Since(time.UnixMilli(ms)) > time.Second {
+		t.Errorf("timestamp rendered as %q, want the current Unix time in milliseconds", message)
+	}
+}
+
+func TestCorrelationID(t *testing.T) {
+	for _, tt := range []struct {
+		reply, field, want string
+	}{
+		{`{"id":"1-2","text":"pong"}`, "id", "1-2"},
+		{`{"id":12}`, "id", "12"},
+		{`{"meta":{"id":true}}`, "meta.id", "true"},
+		{`{"id":null}`, "id", `{"id":null}`},
+		{`{"other":"1-2"}`, "id", `{"other":"1-2"}`},
+		{"pong", "id", "pong"},
+		{`{"id":"1-2"}`, "", `{"id":"1-2"}`},
+	} {
+		if got := correlationID([]byte(tt.reply), tt.field); got != tt.want {
+			t.Errorf("correlationID(%s, %q) = %q, want %q", tt.reply, tt.field, got, tt.want)
+		}
+	}
+}
+
+func TestPendingMessages(t *testing.T) {
+	p := newPendingMessages()
+	start := time.Now()
+	p.add("a", start)
+	p.add("a", start.Add(time.Second)) // Same content, matched in order
+	p.add("b", start.Add(2*time.Second))
+
+	if at, ok := p.take("a"); !ok || !at.Equal(start) {
+		t.Errorf("take(a) = %v, %v, want the first message", at, ok)
+	}
+	if _, ok := p.take("c"); ok {
+		t.Error("take(c) matched a message that was never sent")
+	}
+	if n := p.expire(start.Add(1500 * time.Millisecond)); n != 1 {
+		t.Errorf("expired %d messages, want the second a", n)
+	}
+	if _, ok := p.take("a"); ok {
+		t.Error("an expired message was still pending")
+	}
+	if _, ok := p.take("b"); !ok {
+		t.Error("b expired before the cutoff")
+	}
+}
+
+func TestDisconnectReason(t *testing.T) {
+	for _, tt := range []struct {
+		err  error
+		want string
+	}{
+		{&websocket.CloseError{Code: websocket.CloseNormalClosure}, "close 1000 (normal closure)"},
+		{&websocket.CloseError{Code: websocket.CloseTryAgainLater, Text: "busy"}, "close 1013 (try again later): busy"},
+		{&websocket.CloseError{Code: websocket.CloseAbnormalClosure, Text: "unexpected EOF"}, "close 1006 (abnormal closure)"},
+		{&websocket.CloseError{Code: 4000, Text: "custom"}, "close 4000: custom"},
+	} {
+		if got := disconnectReason(tt.err); got != tt.want {
+			t.Errorf("disconnectReason(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}